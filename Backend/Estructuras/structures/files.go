@@ -22,6 +22,13 @@ type SuperBloque struct { //68 bytes
 	S_block_start       int32 //guarda inicio de la tala de bloques
 }
 
+// Versiones del formato, guardadas en el segundo byte de S_filesistem_type (el primero es
+// el tipo, 2 = EXT2). Las particiones anteriores a las versiones tienen 0 y son ASCII.
+const (
+	FormatoBitmapASCII int32 = 1 // un byte '0'/'1' por inodo o bloque
+	FormatoBitmapBits  int32 = 2 // bitmaps empaquetados, un bit por inodo o bloque
+)
+
 type TablaInodo struct { //92 bytes
 	I_uid   int32     //UID del usuario propietario del archivo o carpeta
 	I_gid   int32     //GID del grupo al que pertenece el archivo o carpeta
@@ -2,30 +2,28 @@ package Reportes
 
 import (
 	"Proyecto/Estructuras/structures"
-	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
 	"Proyecto/comandos/utils"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 )
 
-func generarReporteBlock(id string, path string) (string, bool) {
-	repDir := "VDIC-MIA/Rep"
-	if _, err := os.Stat(repDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(repDir, 0755); err != nil {
-			return fmt.Sprintf("[REP BLOCK]: Error al crear %s: %v", repDir, err), true
-		}
+func generarReporteBlock(m *motor.Motor, id string, path string, formato string) (string, error) {
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP BLOCK]: %w", err)
 	}
 
-	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	particionMontada, err := m.ParticionMontadaPorID(id)
 	if err != nil {
-		return fmt.Sprintf("[REP BLOCK]: Partición con ID '%s' no montada", id), true
+		return "", errores.Nuevof(errores.NoMontada, "[REP BLOCK]: Partición con ID '%s' no montada", id)
 	}
 
-	file, err := os.Open(particionMontada.DiskPath)
+	file, err := m.AbrirDiscoConCache(particionMontada.DiskPath, false)
 	if err != nil {
-		return "[REP BLOCK]: Error al abrir disco", true
+		return "", errores.Nuevo(errores.Interno, "[REP BLOCK]: Error al abrir disco")
 	}
 	defer file.Close()
 
@@ -34,24 +32,24 @@ func generarReporteBlock(id string, path string) (string, bool) {
 
 	sb, err := utils.LeerSuperBloque(file, inicioParticion)
 	if err != nil {
-		return "[REP BLOCK]: Error al leer superbloque", true
+		return "", errores.Nuevo(errores.Interno, "[REP BLOCK]: Error al leer superbloque")
 	}
 
-	htmlContent := generarHtmlBlock(file, sb)
-
-	// Guardar archivo
-	baseName := filepath.Base(path)
-	htmlFileName := strings.TrimSuffix(baseName, filepath.Ext(baseName)) + ".html"
-	htmlFilePath := filepath.Join(repDir, htmlFileName)
+	var contenido string
+	if formato == "dot" {
+		contenido = generarDotBlock(file, sb)
+	} else {
+		contenido = generarHtmlBlock(file, sb)
+	}
 
-	if err := os.WriteFile(htmlFilePath, []byte(htmlContent), 0644); err != nil {
-		return fmt.Sprintf("[REP BLOCK]: Error al escribir: %v", err), true
+	if err := escribirReporte(m, rutaReporte, []byte(contenido), "block", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP BLOCK]: Error al escribir: %w", err)
 	}
 
-	return fmt.Sprintf("[REP BLOCK]: Reporte generado en %s", htmlFilePath), false
+	return fmt.Sprintf("[REP BLOCK]: Reporte generado en %s", rutaReporte), nil
 }
 
-func generarHtmlBlock(file *os.File, sb structures.SuperBloque) string {
+func generarHtmlBlock(file almacenamiento.Disco, sb structures.SuperBloque) string {
 	var sbBuilder strings.Builder
 
 	sbBuilder.WriteString(`<!DOCTYPE html>
@@ -79,14 +77,8 @@ func generarHtmlBlock(file *os.File, sb structures.SuperBloque) string {
     <div class="container">`)
 
 	// Leer bitmap de bloques
-	bitmapSize := int(sb.S_blocks_count)
-	bitmapBloques := make([]byte, bitmapSize)
-	if _, err := file.Seek(int64(sb.S_bm_block_start), 0); err != nil {
-		sbBuilder.WriteString(`<p>Error al leer bitmap de bloques</p>`)
-		sbBuilder.WriteString(`</div></body></html>`)
-		return sbBuilder.String()
-	}
-	if _, err := file.Read(bitmapBloques); err != nil {
+	bitmapBloques, err := utils.LeerBitmapBloques(file, &sb)
+	if err != nil {
 		sbBuilder.WriteString(`<p>Error al leer bitmap de bloques</p>`)
 		sbBuilder.WriteString(`</div></body></html>`)
 		return sbBuilder.String()
@@ -101,10 +93,7 @@ func generarHtmlBlock(file *os.File, sb structures.SuperBloque) string {
 		posBloque := sb.S_block_start + int32(i)*64 // tamaño fijo de 64 bytes
 		var bloque [64]byte
 
-		if _, err := file.Seek(int64(posBloque), 0); err != nil {
-			continue
-		}
-		if _, err := file.Read(bloque[:]); err != nil {
+		if err := utils.LeerBytes(file, int64(posBloque), bloque[:]); err != nil {
 			continue
 		}
 
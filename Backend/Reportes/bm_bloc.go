@@ -1,30 +1,27 @@
 package Reportes
 
 import (
-	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
 	"Proyecto/comandos/utils"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 )
 
-func generarReporteBMBloc(id string, path string) (string, bool) {
-	repDir := "VDIC-MIA/Rep"
-	if _, err := os.Stat(repDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(repDir, 0755); err != nil {
-			return fmt.Sprintf("[REP BM_BLOC]: Error al crear %s: %v", repDir, err), true
-		}
+func generarReporteBMBloc(m *motor.Motor, id string, path string, formato string) (string, error) {
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP BM_BLOC]: %w", err)
 	}
 
-	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	particionMontada, err := m.ParticionMontadaPorID(id)
 	if err != nil {
-		return fmt.Sprintf("[REP BM_BLOC]: Partición con ID '%s' no montada", id), true
+		return "", errores.Nuevof(errores.NoMontada, "[REP BM_BLOC]: Partición con ID '%s' no montada", id)
 	}
 
-	file, err := os.Open(particionMontada.DiskPath)
+	file, err := m.AbrirDisco(particionMontada.DiskPath, false)
 	if err != nil {
-		return "[REP BM_BLOC]: Error al abrir disco", true
+		return "", errores.Nuevo(errores.Interno, "[REP BM_BLOC]: Error al abrir disco")
 	}
 	defer file.Close()
 
@@ -33,39 +30,32 @@ func generarReporteBMBloc(id string, path string) (string, bool) {
 
 	sb, err := utils.LeerSuperBloque(file, inicioParticion)
 	if err != nil {
-		return "[REP BM_BLOC]: Error al leer superbloque", true
+		return "", errores.Nuevo(errores.Interno, "[REP BM_BLOC]: Error al leer superbloque")
 	}
 
-	// ✅ Leer bitmap como bytes (1 byte por bloque)
-	bitmapBloques := make([]byte, sb.S_blocks_count)
-	if _, err := file.Seek(int64(sb.S_bm_block_start), 0); err != nil {
-		return "[REP BM_BLOC]: Error al posicionar puntero", true
-	}
-	if _, err := file.Read(bitmapBloques); err != nil {
-		return fmt.Sprintf("[REP BM_BLOC]: Error al leer bitmap: %v", err), true
+	// ✅ Leer bitmap como '0'/'1' por bloque, esté o no empaquetado en disco
+	bitmapBloques, err := utils.LeerBitmapBloques(file, &sb)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP BM_BLOC]: Error al leer bitmap: %w", err)
 	}
 
 	txtContent := generarTxtBMBloc(bitmapBloques)
 
-	baseName := filepath.Base(path)
-	txtFileName := strings.TrimSuffix(baseName, filepath.Ext(baseName)) + ".txt"
-	txtFilePath := filepath.Join(repDir, txtFileName)
-
-	if err := os.WriteFile(txtFilePath, []byte(txtContent), 0644); err != nil {
-		return fmt.Sprintf("[REP BM_BLOC]: Error al escribir: %v", err), true
+	if err := escribirReporte(m, rutaReporte, []byte(txtContent), "bm_bloc", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP BM_BLOC]: Error al escribir: %w", err)
 	}
 
-	return fmt.Sprintf("[REP BM_BLOC]: Reporte generado en %s", txtFilePath), false
+	return fmt.Sprintf("[REP BM_BLOC]: Reporte generado en %s", rutaReporte), nil
 }
 
-// generarTxtBMBloc convierte el bitmap (1 byte por bloque) a texto
+// generarTxtBMBloc convierte el bitmap ('0'/'1' por bloque) a texto
 func generarTxtBMBloc(bitmapBloques []byte) string {
 	var sb strings.Builder
 
 	// Convertir cada byte a '0' o '1'
 	var line string
 	for i, b := range bitmapBloques {
-		if b == '1' || b == 1 {
+		if b == '1' {
 			line += "1"
 		} else {
 			line += "0"
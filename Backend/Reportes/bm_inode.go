@@ -1,81 +1,69 @@
 package Reportes
 
 import (
-	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
 	"Proyecto/comandos/utils"
-	"encoding/binary"
 	"fmt"
-	"os"
 	"strings"
 )
 
 // GenerarReporteBMInode genera el reporte del bitmap de inodos en formato .txt
-func generarReporteBMInode(id string, path string) (string, bool) {
+func generarReporteBMInode(m *motor.Motor, id string, path string, formato string) (string, error) {
 	// 1. Obtener la partición montada por ID
-	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	particionMontada, err := m.ParticionMontadaPorID(id)
 	if err != nil {
-		return fmt.Sprintf("[REP BM_INODE]: Partición con ID '%s' no encontrada o no montada", id), true
+		return "", errores.Nuevof(errores.NoMontada, "[REP BM_INODE]: Partición con ID '%s' no encontrada o no montada", id)
 	}
 
 	// 2. Abrir el archivo del disco
-	file, errOpen := os.OpenFile(particionMontada.DiskPath, os.O_RDONLY, 0666)
+	file, errOpen := m.AbrirDisco(particionMontada.DiskPath, false)
 	if errOpen != nil {
-		return "[REP BM_INODE]: Error al abrir el disco", true
+		return "", errores.Nuevo(errores.Interno, "[REP BM_INODE]: Error al abrir el disco")
 	}
 	defer file.Close()
 
 	// 3. Leer el MBR y el SuperBloque
-	mbr, er, strError := utils.ObtenerEstructuraMBR(particionMontada.DiskPath)
-	if er {
-		return strError, er
+	mbr, err := m.LeerMBR(particionMontada.DiskPath)
+	if err != nil {
+		return "", err
 	}
 
 	sb, errSB := utils.LeerSuperBloque(file, mbr.Mbr_partitions[0].Part_start)
 	if errSB != nil {
-		return "[REP BM_INODE]: Error al leer SuperBloque", true
+		return "", errores.Nuevo(errores.Interno, "[REP BM_INODE]: Error al leer SuperBloque")
 	}
 
-	// 4. Leer el bitmap de inodos
-	bitmapInodos := make([]byte, sb.S_inodes_count)
-	if _, err := file.Seek(int64(sb.S_bm_inode_start), 0); err != nil {
-		return "[REP BM_INODE]: Error al posicionar puntero para leer bitmap de inodos", true
-	}
-	if err := binary.Read(file, binary.LittleEndian, &bitmapInodos); err != nil {
-		return "[REP BM_INODE]: Error al leer bitmap de inodos", true
+	// 4. Leer el bitmap de inodos ('0'/'1' por inodo, esté o no empaquetado en disco)
+	bitmapInodos, err := utils.LeerBitmapInodos(file, &sb)
+	if err != nil {
+		return "", errores.Nuevo(errores.Interno, "[REP BM_INODE]: Error al leer bitmap de inodos")
 	}
 
 	// 5. Generar el contenido del archivo .txt
 	txtContent := generarTxtBMInode(bitmapInodos, sb.S_inodes_count)
 
-	// 6. Escribir el archivo .txt en la carpeta Rep
-	repDir := "VDIC-MIA/Rep"
-	if _, err := os.Stat(repDir); os.IsNotExist(err) {
-		os.MkdirAll(repDir, 0777)
-	}
-
-	// Construir la ruta completa para el archivo .txt
-	finalFileName := strings.TrimSuffix(path, ".png") + ".txt"
-	if !strings.HasSuffix(finalFileName, ".txt") {
-		finalFileName += ".txt"
+	// 6. Escribir el archivo .txt en la carpeta de reportes
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP BM_INODE]: %w", err)
 	}
-	txtFilePath := repDir + "/" + finalFileName
 
-	if err := os.WriteFile(txtFilePath, []byte(txtContent), 0644); err != nil {
-		return fmt.Sprintf("[REP BM_INODE]: Error al escribir archivo TXT: %v", err), true
+	if err := escribirReporte(m, rutaReporte, []byte(txtContent), "bm_inode", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP BM_INODE]: Error al escribir archivo TXT: %w", err)
 	}
 
-	return fmt.Sprintf("[REP BM_INODE]: Reporte BM_INODE generado exitosamente en %s", txtFilePath), false
+	return fmt.Sprintf("[REP BM_INODE]: Reporte BM_INODE generado exitosamente en %s", rutaReporte), nil
 }
 
 // generarTxtBMInode crea el contenido del archivo .txt para el reporte del bitmap de inodos
 func generarTxtBMInode(bitmapInodos []byte, totalInodos int32) string {
 	var sb strings.Builder
 
-	// Convertir el bitmap de bytes a una cadena de '0's y '1's
+	// Convertir el bitmap a una cadena de '0's y '1's
 	var bits string
 	for i := int32(0); i < totalInodos; i++ {
-		bit := (bitmapInodos[i/8] >> (i % 8)) & 1
-		if bit == 1 {
+		if bitmapInodos[i] == '1' {
 			bits += "1"
 		} else {
 			bits += "0"
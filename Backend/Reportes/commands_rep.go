@@ -1,46 +1,207 @@
 package Reportes
 
 import (
-	"fmt"
+	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/motor"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
 // RepExecute maneja el comando rep
-func RepExecute(comando string, parametros map[string]string) (string, bool) {
+func RepExecute(comando string, parametros map[string]string) (string, interface{}, error) {
+	// -path es sinónimo de -namereport; ambos pueden incluir subcarpetas
+	namereport := strings.TrimSpace(parametros["namereport"])
+	if namereport == "" {
+		namereport = strings.TrimSpace(parametros["path"])
+	}
+
+	res, err := Generar(admonDisk.MotorComandos(global.SesionActiva), motor.OpcionesReporte{
+		Nombre:      strings.TrimSpace(parametros["name"]),
+		ID:          strings.TrimSpace(parametros["id"]),
+		Ruta:        namereport,
+		Formato:     strings.TrimSpace(parametros["format"]),
+		RutaArchivo: strings.TrimSpace(parametros["path_file_ls"]),
+		Disco:       strings.TrimSpace(parametros["diskname"]),
+		Offset:      strings.TrimSpace(parametros["offset"]),
+		Largo:       strings.TrimSpace(parametros["len"]),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	datos := map[string]interface{}{
+		"reporte": res.Nombre,
+		"formato": res.Formato,
+		"ruta":    filepath.ToSlash(res.Ruta),
+	}
+	return res.Salida, datos, nil
+}
+
+// Generar valida las opciones y genera el reporte con las carpetas del motor. Es el
+// generador que se pasa en motor.Opciones.GeneradorReportes.
+func Generar(m *motor.Motor, op motor.OpcionesReporte) (*motor.ResultadoReporte, error) {
+	salida, ruta, err := despacharReporte(m, op)
+	if err != nil {
+		return nil, err
+	}
+	nombre := strings.ToLower(strings.TrimSpace(op.Nombre))
+	return &motor.ResultadoReporte{Nombre: nombre, Formato: formatoReporte(op), Ruta: ruta, Salida: salida}, nil
+}
+
+// formatoReporte devuelve el formato pedido o el predeterminado del reporte
+func formatoReporte(op motor.OpcionesReporte) string {
+	formato := strings.ToLower(strings.TrimSpace(op.Formato))
+	if formato == "" {
+		formato = formatoPorDefecto(strings.ToLower(strings.TrimSpace(op.Nombre)))
+	}
+	return formato
+}
+
+func despacharReporte(m *motor.Motor, op motor.OpcionesReporte) (string, string, error) {
 	// Validar parámetros obligatorios
-	name := strings.TrimSpace(parametros["name"])
+	name := strings.TrimSpace(op.Nombre)
 	if name == "" {
-		return "[REP]: Parámetro -name es obligatorio", true
+		return "", "", errores.Nuevo(errores.ParametroInvalido, "[REP]: Parámetro -name es obligatorio")
 	}
 
-	id := strings.TrimSpace(parametros["id"])
-	if id == "" {
-		return "[REP]: Parámetro -id es obligatorio", true
+	// El reporte raw lee el disco directamente, así que se ubica por -diskname y no por -id
+	id := strings.TrimSpace(op.ID)
+	if id == "" && strings.ToLower(name) != "raw" {
+		return "", "", errores.Nuevo(errores.ParametroInvalido, "[REP]: Parámetro -id es obligatorio")
 	}
 
-	namereport := strings.TrimSpace(parametros["namereport"])
+	namereport := strings.TrimSpace(op.Ruta)
 	if namereport == "" {
-		return "[REP]: Parámetro -namereport es obligatorio", true
+		return "", "", errores.Nuevo(errores.ParametroInvalido, "[REP]: Parámetro -namereport (o -path) es obligatorio")
+	}
+
+	if _, ok := formatosReporte[strings.ToLower(name)]; !ok {
+		return "", "", errores.Nuevof(errores.ParametroInvalido, "[REP]: Tipo de reporte '%s' no soportado", name)
+	}
+
+	formato := formatoReporte(op)
+	if !formatoSoportado(strings.ToLower(name), formato) {
+		return "", "", errores.Nuevof(errores.ParametroInvalido, "[REP]: El reporte '%s' no soporta el formato '%s'", name, formato)
 	}
 
-	switch strings.ToLower(name) {
+	var salida string
+	var err error
+	// Los reportes de estructuras arman su JSON a partir de la inspección del disco
+	if _, ok := seccionesJSON[strings.ToLower(name)]; ok && formato == "json" {
+		salida, err = generarReporteJSON(m, strings.ToLower(name), id, namereport)
+	} else {
+		salida, err = generarReportePorNombre(m, strings.ToLower(name), id, namereport, formato, op)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	ruta, _ := resolverRutaReporte(m, namereport, formato)
+	return salida, ruta, nil
+}
+
+func generarReportePorNombre(m *motor.Motor, name string, id string, namereport string, formato string, op motor.OpcionesReporte) (string, error) {
+	switch name {
 	case "mbr":
-		return generarReporteMBR(id, namereport)
+		return generarReporteMBR(m, id, namereport, formato)
 	case "disk": // Añadir cuando lo implementes
-		return generarReporteDisk(id, namereport)
+		return generarReporteDisk(m, id, namereport, formato)
 	case "inode": // <-- Añadir este caso
-		return generarReporteInode(id, namereport)
+		return generarReporteInode(m, id, namereport, formato)
 	case "block": // Añadir cuando lo implementes
-		return generarReporteBlock(id, namereport)
+		return generarReporteBlock(m, id, namereport, formato)
 	case "tree": // Añadir cuando lo implementes
-		return generarReporteTree(id, namereport)
+		return generarReporteTree(m, id, namereport, formato)
 	case "sb": // Añadir cuando lo implementes
-		return generarReporteSB(id, namereport)
+		return generarReporteSB(m, id, namereport, formato)
 	case "bm_inode": // Añadir cuando lo implementes
-		return generarReporteBMInode(id, namereport)
+		return generarReporteBMInode(m, id, namereport, formato)
 	case "bm_bloc": // Añadir cuando lo implementes
-		return generarReporteBMBloc(id, namereport)
+		return generarReporteBMBloc(m, id, namereport, formato)
+	case "file":
+		return generarReporteFile(m, id, namereport, strings.TrimSpace(op.RutaArchivo), formato)
+	case "ls":
+		return generarReporteLs(m, id, namereport, strings.TrimSpace(op.RutaArchivo), formato)
+	case "frag":
+		return generarReporteFrag(m, id, namereport, formato)
+	case "raw":
+		return generarReporteRaw(m, namereport, strings.TrimSpace(op.Disco),
+			strings.TrimSpace(op.Offset), strings.TrimSpace(op.Largo), formato)
 	default:
-		return fmt.Sprintf("[REP]: Tipo de reporte '%s' no soportado", name), true
+		return "", errores.Nuevof(errores.ParametroInvalido, "[REP]: Tipo de reporte '%s' no soportado", name)
+	}
+}
+
+// formatosReporte indica qué formatos admite cada reporte; el primero es el predeterminado
+var formatosReporte = map[string][]string{
+	"mbr":      {"html", "dot", "json"},
+	"disk":     {"html", "dot", "svg", "json"},
+	"tree":     {"html", "dot", "svg", "json"},
+	"inode":    {"html", "dot", "json"},
+	"block":    {"html", "dot", "json"},
+	"sb":       {"html", "json"},
+	"bm_inode": {"txt", "json"},
+	"bm_bloc":  {"txt", "json"},
+	"file":     {"txt"},
+	"ls":       {"html", "json"},
+	"raw":      {"html", "txt"},
+	"frag":     {"html", "json"},
+}
+
+// formatoPorDefecto devuelve el formato que usa el reporte cuando no se indica -format
+func formatoPorDefecto(nombre string) string {
+	return formatosReporte[nombre][0]
+}
+
+// formatoSoportado revisa si el reporte puede generarse en el formato pedido
+func formatoSoportado(nombre string, formato string) bool {
+	for _, f := range formatosReporte[nombre] {
+		if f == formato {
+			return true
+		}
+	}
+	return false
+}
+
+// resolverRutaReporte ubica el reporte dentro de la carpeta de reportes respetando las
+// subcarpetas de la ruta pedida y cambia la extensión por la del formato. No crea nada:
+// las carpetas se crean en escribirReporte, cuando el reporte ya se generó. Las rutas
+// absolutas se toman relativas a la raíz de reportes; las que salen de ella se rechazan.
+func resolverRutaReporte(m *motor.Motor, ruta string, formato string) (string, error) {
+	relativa := path.Clean("/" + filepath.ToSlash(strings.TrimSpace(ruta)))
+	if escapaDeRaiz(ruta) {
+		return "", errores.Nuevof(errores.ParametroInvalido, "la ruta '%s' sale de la carpeta de reportes", ruta)
+	}
+	if relativa == "/" {
+		return "", errores.Nuevof(errores.ParametroInvalido, "la ruta '%s' no indica un nombre de reporte", ruta)
+	}
+
+	nombre := path.Base(relativa)
+	if strings.HasPrefix(nombre, ".") {
+		return "", errores.Nuevof(errores.ParametroInvalido, "el nombre de reporte '%s' no es válido", nombre)
+	}
+	nombre = strings.TrimSuffix(nombre, path.Ext(nombre)) + "." + formato
+
+	carpeta := filepath.Join(m.DirectorioReportes(), filepath.FromSlash(path.Dir(relativa)))
+	return filepath.Join(carpeta, nombre), nil
+}
+
+// escapaDeRaiz indica si al resolver los ".." la ruta relativa termina fuera de la raíz
+func escapaDeRaiz(ruta string) bool {
+	profundidad := 0
+	for _, parte := range strings.Split(filepath.ToSlash(ruta), "/") {
+		switch parte {
+		case "", ".":
+		case "..":
+			profundidad--
+			if profundidad < 0 {
+				return true
+			}
+		default:
+			profundidad++
+		}
 	}
+	return false
 }
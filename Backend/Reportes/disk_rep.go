@@ -3,11 +3,10 @@ package Reportes
 import (
 	"Proyecto/Estructuras/size"
 	"Proyecto/Estructuras/structures"
-	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
 	"Proyecto/comandos/utils"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 )
@@ -20,45 +19,46 @@ type Segmento struct {
 	Porcentaje float64
 }
 
-// generarReporteDisk genera el reporte DISK en HTML según el enunciado
-func generarReporteDisk(id string, path string) (string, bool) {
-	repDir := "VDIC-MIA/Rep"
-
-	// 0. Crear directorio si no existe
-	if _, err := os.Stat(repDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(repDir, 0755); err != nil {
-			return fmt.Sprintf("[REP DISK]: Error al crear carpeta %s: %v", repDir, err), true
-		}
+// generarReporteDisk genera el reporte DISK en HTML (o DOT/SVG) según el enunciado
+func generarReporteDisk(m *motor.Motor, id string, path string, formato string) (string, error) {
+	// 0. Validar la ruta del reporte dentro de la carpeta de reportes
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP DISK]: %w", err)
 	}
 
 	// 1. Obtener partición montada por ID
-	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	particionMontada, err := m.ParticionMontadaPorID(id)
 	if err != nil {
-		return fmt.Sprintf("[REP DISK]: Partición con ID '%s' no encontrada o no montada", id), true
+		return "", errores.Nuevof(errores.NoMontada, "[REP DISK]: Partición con ID '%s' no encontrada o no montada", id)
 	}
 
 	// 2. Leer MBR
-	mbr, er, strError := utils.ObtenerEstructuraMBR(particionMontada.DiskPath)
-	if er {
-		return strError, er
+	mbr, err := m.LeerMBR(particionMontada.DiskPath)
+	if err != nil {
+		return "", err
 	}
 
 	// 3. Generar segmentos del disco
 	segmentos := generarSegmentosDisco(mbr, mbr.Mbr_tamano)
 
-	// 4. Generar HTML
-	htmlContent := generarHtmlDisk(segmentos, particionMontada.DiskName)
+	// 4. Generar contenido
+	var contenido string
+	switch formato {
+	case "dot":
+		contenido = generarDotDisk(segmentos, particionMontada.DiskName)
+	case "svg":
+		contenido = generarSvgDisk(m, segmentos, particionMontada.DiskPath, particionMontada.DiskName, mbr.Mbr_tamano)
+	default:
+		contenido = generarHtmlDisk(segmentos, particionMontada.DiskName)
+	}
 
 	// 5. Guardar archivo
-	baseName := filepath.Base(path)
-	htmlFileName := strings.TrimSuffix(baseName, filepath.Ext(baseName)) + ".html"
-	htmlFilePath := filepath.Join(repDir, htmlFileName)
-
-	if err := os.WriteFile(htmlFilePath, []byte(htmlContent), 0644); err != nil {
-		return fmt.Sprintf("[REP DISK]: Error al escribir archivo HTML: %v", err), true
+	if err := escribirReporte(m, rutaReporte, []byte(contenido), "disk", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP DISK]: Error al escribir archivo %s: %w", strings.ToUpper(formato), err)
 	}
 
-	return fmt.Sprintf("[REP DISK]: Reporte DISK HTML generado exitosamente en %s", htmlFilePath), false
+	return fmt.Sprintf("[REP DISK]: Reporte DISK %s generado exitosamente en %s", strings.ToUpper(formato), rutaReporte), nil
 }
 
 // generarSegmentosDisco construye la lista de segmentos físicos del disco
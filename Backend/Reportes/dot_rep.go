@@ -0,0 +1,326 @@
+package Reportes
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/motor"
+	"Proyecto/comandos/utils"
+	"fmt"
+	"strings"
+)
+
+// escaparDot escapa el texto para usarlo dentro de una etiqueta record de Graphviz
+func escaparDot(texto string) string {
+	reemplazo := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"{", `\{`,
+		"}", `\}`,
+		"|", `\|`,
+		"<", `\<`,
+		">", `\>`,
+		"\n", `\n`,
+		"\r", "",
+		"\x00", "",
+	)
+	return reemplazo.Replace(texto)
+}
+
+// indiceInodo convierte la posición absoluta de un inodo en su número dentro de la tabla
+func indiceInodo(sb *structures.SuperBloque, pos int32) int32 {
+	return (pos - sb.S_inode_start) / sb.S_inode_s
+}
+
+// indiceBloque convierte la posición absoluta de un bloque en su número dentro de la tabla
+func indiceBloque(sb *structures.SuperBloque, pos int32) int32 {
+	return (pos - sb.S_block_start) / sb.S_block_s
+}
+
+func leerBloqueCarpeta(file almacenamiento.Disco, pos int32) (structures.BloqueCarpeta, error) {
+	return utils.LeerBloqueCarpeta(file, pos)
+}
+
+func leerBloqueArchivo(file almacenamiento.Disco, pos int32) (structures.BloqueArchivo, error) {
+	return utils.LeerBloqueArchivo(file, pos)
+}
+
+func iniciarDot(sb *strings.Builder, nombre string, titulo string) {
+	sb.WriteString(fmt.Sprintf("digraph %s {\n", nombre))
+	sb.WriteString("    rankdir=LR;\n")
+	sb.WriteString(fmt.Sprintf("    label=\"%s\";\n", escaparDot(titulo)))
+	sb.WriteString("    labelloc=t;\n")
+	sb.WriteString("    node [shape=record, fontname=\"Arial\", fontsize=10, style=filled];\n")
+	sb.WriteString("    edge [fontname=\"Arial\", fontsize=9];\n")
+}
+
+// etiquetaInodo arma el record de un inodo con un puerto por cada apuntador usado
+func etiquetaInodo(sb *structures.SuperBloque, inodo *structures.TablaInodo, numero int32) string {
+	tipo := "Archivo"
+	if inodo.I_type[0] == '0' {
+		tipo = "Carpeta"
+	}
+
+	campos := []string{
+		fmt.Sprintf("Inodo %d (%s)", numero, tipo),
+		fmt.Sprintf("i_uid: %d", inodo.I_uid),
+		fmt.Sprintf("i_gid: %d", inodo.I_gid),
+		fmt.Sprintf("i_s: %d", inodo.I_s),
+		fmt.Sprintf("i_perm: %s", escaparDot(string(inodo.I_perm[:]))),
+		fmt.Sprintf("i_mtime: %s", escaparDot(utils.IntFechaToStr(inodo.I_mtime))),
+	}
+	for i, apuntador := range inodo.I_block {
+		if apuntador == -1 {
+			continue
+		}
+		campos = append(campos, fmt.Sprintf("<b%d> i_block[%d]: %d", i, i, indiceBloque(sb, apuntador)))
+	}
+	return "{" + strings.Join(campos, "|") + "}"
+}
+
+// etiquetaBloqueCarpeta arma el record de un bloque carpeta con un puerto por entrada
+func etiquetaBloqueCarpeta(sb *structures.SuperBloque, bloque *structures.BloqueCarpeta, numero int32) string {
+	campos := []string{fmt.Sprintf("Bloque carpeta %d", numero)}
+	for j, entrada := range bloque.B_content {
+		nombre := utils.ConvertirByteAString(entrada.B_name[:])
+		apunta := "-1"
+		if entrada.B_inodo != -1 {
+			apunta = fmt.Sprintf("%d", indiceInodo(sb, entrada.B_inodo))
+		}
+		campos = append(campos, fmt.Sprintf("{%s|<e%d> %s}", escaparDot(nombre), j, apunta))
+	}
+	return "{" + strings.Join(campos, "|") + "}"
+}
+
+func etiquetaBloqueArchivo(bloque *structures.BloqueArchivo, numero int32) string {
+	contenido := strings.TrimRight(string(bloque.B_content[:]), "\x00")
+	return fmt.Sprintf("{Bloque archivo %d|%s}", numero, escaparDot(contenido))
+}
+
+// generarDotMBR genera el grafo del MBR, sus particiones y la cadena de EBRs
+func generarDotMBR(m *motor.Motor, mbr structures.MBR, diskPath, diskName string) string {
+	var sb strings.Builder
+	iniciarDot(&sb, "MBR", "MBR + EBRs - Disco: "+diskName)
+
+	sb.WriteString(fmt.Sprintf("    mbr [fillcolor=\"#e9d8fd\", label=\"{MBR|mbr_tamano: %d|mbr_fecha_creacion: %s|mbr_disk_signature: %d|dsk_fit: %s}\"];\n",
+		mbr.Mbr_tamano, escaparDot(utils.IntFechaToStr(mbr.Mbr_fecha_creacion)), mbr.Mbr_disk_signature, escaparDot(string(mbr.Dsk_fit))))
+
+	for i, part := range mbr.Mbr_partitions {
+		if part.Part_s <= 0 {
+			continue
+		}
+
+		nombre := utils.ConvertirByteAString(part.Part_name[:])
+		if nombre == "" {
+			nombre = "N/A"
+		}
+		color := "#e9d8fd"
+		if part.Part_type == 'E' {
+			color = "#c8e6c9"
+		}
+
+		sb.WriteString(fmt.Sprintf("    particion%d [fillcolor=\"%s\", label=\"{Partición %d|part_status: %d|part_type: %s|part_fit: %s|part_start: %d|part_size: %d|part_name: %s|part_correlative: %d}\"];\n",
+			i+1, color, i+1, part.Part_status, escaparDot(string(part.Part_type)), escaparDot(string(part.Part_fit)),
+			part.Part_start, part.Part_s, escaparDot(nombre), part.Part_correlative))
+		sb.WriteString(fmt.Sprintf("    mbr -> particion%d [label=\"mbr_partitions[%d]\"];\n", i+1, i))
+
+		if part.Part_type != 'E' {
+			continue
+		}
+
+		anterior := fmt.Sprintf("particion%d", i+1)
+		for j, ebr := range leerEBRs(m, diskPath, part.Part_start) {
+			nombreEBR := utils.ConvertirByteAString(ebr.Name[:])
+			if nombreEBR == "" {
+				nombreEBR = "N/A"
+			}
+			nodo := fmt.Sprintf("ebr%d_%d", i+1, j+1)
+			sb.WriteString(fmt.Sprintf("    %s [fillcolor=\"#ffecb3\", label=\"{EBR %d|part_mount: %d|part_fit: %s|part_start: %d|part_size: %d|part_next: %d|part_name: %s}\"];\n",
+				nodo, j+1, ebr.Part_mount, escaparDot(string(ebr.Part_fit)), ebr.Part_start, ebr.Part_s, ebr.Part_next, escaparDot(nombreEBR)))
+
+			etiqueta := "part_next"
+			if j == 0 {
+				etiqueta = "part_start"
+			}
+			sb.WriteString(fmt.Sprintf("    %s -> %s [label=\"%s\"];\n", anterior, nodo, etiqueta))
+			anterior = nodo
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// generarDotDisk genera el grafo con la distribución física del disco en un solo record
+func generarDotDisk(segmentos []Segmento, diskName string) string {
+	var sb strings.Builder
+	iniciarDot(&sb, "Disco", "Disco: "+diskName)
+
+	campos := make([]string, 0, len(segmentos))
+	for _, seg := range segmentos {
+		campos = append(campos, fmt.Sprintf("{%s|%s|inicio: %d|%d bytes|%.2f%%}",
+			escaparDot(seg.Nombre), seg.Tipo, seg.Inicio, seg.Tamaño, seg.Porcentaje))
+	}
+	sb.WriteString(fmt.Sprintf("    disco [fillcolor=\"#f2f2f2\", label=\"%s\"];\n", strings.Join(campos, "|")))
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// generarDotTree recorre el sistema de archivos desde la raíz usando posiciones absolutas
+func generarDotTree(file almacenamiento.Disco, sb structures.SuperBloque) string {
+	var dot strings.Builder
+	iniciarDot(&dot, "Arbol", "Árbol del sistema de archivos")
+
+	visitados := make(map[int32]bool)
+	var procesarInodo func(posInodo int32)
+	procesarInodo = func(posInodo int32) {
+		if visitados[posInodo] {
+			return // evita ciclos en carpetas dañadas
+		}
+		visitados[posInodo] = true
+
+		inodo, err := utils.LeerInodoPorPosicion(file, posInodo)
+		if err != nil {
+			return
+		}
+
+		numInodo := indiceInodo(&sb, posInodo)
+		dot.WriteString(fmt.Sprintf("    inodo%d [fillcolor=\"#cce5ff\", label=\"%s\"];\n", numInodo, etiquetaInodo(&sb, &inodo, numInodo)))
+
+		for i := 0; i < 12; i++ {
+			if inodo.I_block[i] == -1 {
+				continue
+			}
+			numBloque := indiceBloque(&sb, inodo.I_block[i])
+			dot.WriteString(fmt.Sprintf("    inodo%d:b%d -> bloque%d;\n", numInodo, i, numBloque))
+
+			if inodo.I_type[0] != '0' {
+				bloque, err := leerBloqueArchivo(file, inodo.I_block[i])
+				if err != nil {
+					continue
+				}
+				dot.WriteString(fmt.Sprintf("    bloque%d [fillcolor=\"#ffffcc\", label=\"%s\"];\n", numBloque, etiquetaBloqueArchivo(&bloque, numBloque)))
+				continue
+			}
+
+			bloque, err := leerBloqueCarpeta(file, inodo.I_block[i])
+			if err != nil {
+				continue
+			}
+			dot.WriteString(fmt.Sprintf("    bloque%d [fillcolor=\"#ffcccc\", label=\"%s\"];\n", numBloque, etiquetaBloqueCarpeta(&sb, &bloque, numBloque)))
+
+			for j, entrada := range bloque.B_content {
+				nombre := utils.ConvertirByteAString(entrada.B_name[:])
+				if entrada.B_inodo == -1 || nombre == "" || nombre == "." || nombre == ".." {
+					continue
+				}
+				dot.WriteString(fmt.Sprintf("    bloque%d:e%d -> inodo%d;\n", numBloque, j, indiceInodo(&sb, entrada.B_inodo)))
+				procesarInodo(entrada.B_inodo)
+			}
+		}
+	}
+
+	procesarInodo(sb.S_inode_start)
+
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+// generarDotInode genera un nodo por cada inodo usado y una arista hacia cada bloque que apunta
+func generarDotInode(file almacenamiento.Disco, sb structures.SuperBloque) string {
+	var dot strings.Builder
+	iniciarDot(&dot, "Inodos", "Inodos usados")
+
+	bitmap, err := utils.LeerBitmapInodos(file, &sb)
+	if err != nil {
+		dot.WriteString("}\n")
+		return dot.String()
+	}
+
+	for i := int32(0); i < sb.S_inodes_count; i++ {
+		if bitmap[i] != '1' {
+			continue
+		}
+		inodo, err := utils.LeerInodoPorPosicion(file, sb.S_inode_start+i*sb.S_inode_s)
+		if err != nil {
+			continue
+		}
+
+		dot.WriteString(fmt.Sprintf("    inodo%d [fillcolor=\"#cce5ff\", label=\"%s\"];\n", i, etiquetaInodo(&sb, &inodo, i)))
+		for j, apuntador := range inodo.I_block {
+			if apuntador == -1 {
+				continue
+			}
+			numBloque := indiceBloque(&sb, apuntador)
+			dot.WriteString(fmt.Sprintf("    bloque%d [shape=box, fillcolor=\"#f2f2f2\", label=\"Bloque %d\"];\n", numBloque, numBloque))
+			dot.WriteString(fmt.Sprintf("    inodo%d:b%d -> bloque%d;\n", i, j, numBloque))
+		}
+	}
+
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+// generarDotBlock genera un nodo por cada bloque usado; el tipo se deduce del inodo que lo apunta
+func generarDotBlock(file almacenamiento.Disco, sb structures.SuperBloque) string {
+	var dot strings.Builder
+	iniciarDot(&dot, "Bloques", "Bloques usados")
+
+	bitmapInodos, errInodos := utils.LeerBitmapInodos(file, &sb)
+	bitmapBloques, errBloques := utils.LeerBitmapBloques(file, &sb)
+	if errInodos != nil || errBloques != nil {
+		dot.WriteString("}\n")
+		return dot.String()
+	}
+
+	// Los bloques no guardan su tipo, así que se toma del inodo dueño
+	carpetas := make(map[int32]bool)
+	for i := int32(0); i < sb.S_inodes_count; i++ {
+		if bitmapInodos[i] != '1' {
+			continue
+		}
+		inodo, err := utils.LeerInodoPorPosicion(file, sb.S_inode_start+i*sb.S_inode_s)
+		if err != nil || inodo.I_type[0] != '0' {
+			continue
+		}
+		for _, apuntador := range inodo.I_block {
+			if apuntador != -1 {
+				carpetas[indiceBloque(&sb, apuntador)] = true
+			}
+		}
+	}
+
+	for i := int32(0); i < sb.S_blocks_count; i++ {
+		if bitmapBloques[i] != '1' {
+			continue
+		}
+		posBloque := sb.S_block_start + i*sb.S_block_s
+
+		if !carpetas[i] {
+			bloque, err := leerBloqueArchivo(file, posBloque)
+			if err != nil {
+				continue
+			}
+			dot.WriteString(fmt.Sprintf("    bloque%d [fillcolor=\"#ffffcc\", label=\"%s\"];\n", i, etiquetaBloqueArchivo(&bloque, i)))
+			continue
+		}
+
+		bloque, err := leerBloqueCarpeta(file, posBloque)
+		if err != nil {
+			continue
+		}
+		dot.WriteString(fmt.Sprintf("    bloque%d [fillcolor=\"#ffcccc\", label=\"%s\"];\n", i, etiquetaBloqueCarpeta(&sb, &bloque, i)))
+		for j, entrada := range bloque.B_content {
+			nombre := utils.ConvertirByteAString(entrada.B_name[:])
+			if entrada.B_inodo == -1 || nombre == "." || nombre == ".." {
+				continue
+			}
+			numInodo := indiceInodo(&sb, entrada.B_inodo)
+			dot.WriteString(fmt.Sprintf("    inodo%d [shape=ellipse, fillcolor=\"#cce5ff\", label=\"Inodo %d\"];\n", numInodo, numInodo))
+			dot.WriteString(fmt.Sprintf("    bloque%d:e%d -> inodo%d;\n", i, j, numInodo))
+		}
+	}
+
+	dot.WriteString("}\n")
+	return dot.String()
+}
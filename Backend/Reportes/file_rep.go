@@ -0,0 +1,108 @@
+package Reportes
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
+	"Proyecto/comandos/utils"
+	"fmt"
+	"strings"
+)
+
+// generarReporteFile escribe el contenido de un archivo de la partición con un encabezado
+// que muestra su inodo, tamaño, dueño y bloques
+func generarReporteFile(m *motor.Motor, id string, path string, rutaArchivo string, formato string) (string, error) {
+	if rutaArchivo == "" {
+		return "", errores.Nuevo(errores.ParametroInvalido, "[REP FILE]: Parámetro -path_file_ls es obligatorio")
+	}
+
+	// Los permisos de lectura se evalúan con el usuario de la sesión, igual que cat
+	sesion := m.Sesion()
+	if sesion == nil {
+		return "", errores.Nuevo(errores.SinSesion, "[REP FILE]: No hay sesión activa. Use el comando LOGIN")
+	}
+	if sesion.ID != id {
+		return "", errores.Nuevof(errores.SinSesion, "[REP FILE]: La sesión activa pertenece a la partición '%s', no a '%s'", sesion.ID, id)
+	}
+
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP FILE]: %w", err)
+	}
+
+	particionMontada, err := m.ParticionMontadaPorID(id)
+	if err != nil {
+		return "", errores.Nuevof(errores.NoMontada, "[REP FILE]: Partición con ID '%s' no montada", id)
+	}
+
+	file, err := m.AbrirDiscoConCache(particionMontada.DiskPath, false)
+	if err != nil {
+		return "", errores.Nuevo(errores.Interno, "[REP FILE]: Error al abrir disco")
+	}
+	defer file.Close()
+
+	sb, err := utils.LeerSuperBloque(file, particionMontada.Partition.Part_start)
+	if err != nil {
+		return "", errores.Nuevo(errores.Interno, "[REP FILE]: Error al leer superbloque")
+	}
+
+	inodo, posInodo, err := utils.LocalizarArchivo(file, &sb, rutaArchivo, sesion.Credenciales())
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP FILE]: %w", err)
+	}
+
+	contenido, err := utils.LeerContenidoArchivo(file, &sb, &inodo)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP FILE]: Error al leer '%s': %w", rutaArchivo, err)
+	}
+
+	bloquesInodo, err := utils.BloquesDeInodo(file, &inodo)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP FILE]: Error al leer los bloques de '%s': %w", rutaArchivo, err)
+	}
+
+	usuarios, grupos := utils.LeerUsuariosGrupos(file, &sb)
+	txtContent := generarTxtFile(&sb, rutaArchivo, &inodo, posInodo, bloquesInodo, contenido, usuarios, grupos)
+
+	if err := escribirReporte(m, rutaReporte, []byte(txtContent), "file", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP FILE]: Error al escribir: %w", err)
+	}
+
+	return fmt.Sprintf("[REP FILE]: Reporte generado en %s", rutaReporte), nil
+}
+
+func generarTxtFile(sb *structures.SuperBloque, ruta string, inodo *structures.TablaInodo, posInodo int32, bloquesInodo []utils.BloqueInodo, contenido string, usuarios map[int32]string, grupos map[int32]string) string {
+	var txt strings.Builder
+
+	// Solo bloques de datos; los de apuntadores no guardan contenido
+	var bloques []string
+	for _, bloque := range bloquesInodo {
+		if bloque.Apuntador {
+			continue
+		}
+		bloques = append(bloques, fmt.Sprintf("%d", indiceBloque(sb, bloque.Pos)))
+	}
+	listaBloques := "(ninguno)"
+	if len(bloques) > 0 {
+		listaBloques = strings.Join(bloques, ", ")
+	}
+
+	txt.WriteString("===========================================================\n")
+	txt.WriteString("                    REPORTE DE ARCHIVO\n")
+	txt.WriteString("===========================================================\n")
+	txt.WriteString(fmt.Sprintf("  Archivo:        %s\n", ruta))
+	txt.WriteString(fmt.Sprintf("  Inodo:          %d\n", indiceInodo(sb, posInodo)))
+	txt.WriteString(fmt.Sprintf("  Tamaño:         %d bytes\n", inodo.I_s))
+	txt.WriteString(fmt.Sprintf("  Propietario:    %s (UID %d)\n", usuarios[inodo.I_uid], inodo.I_uid))
+	txt.WriteString(fmt.Sprintf("  Grupo:          %s (GID %d)\n", grupos[inodo.I_gid], inodo.I_gid))
+	txt.WriteString(fmt.Sprintf("  Permisos:       %s\n", string(inodo.I_perm[:])))
+	txt.WriteString(fmt.Sprintf("  Bloques:        %s\n", listaBloques))
+	txt.WriteString(fmt.Sprintf("  Modificado:     %s\n", utils.IntFechaToStr(inodo.I_mtime)))
+	txt.WriteString("-----------------------------------------------------------\n")
+	txt.WriteString(contenido)
+	if contenido != "" && !strings.HasSuffix(contenido, "\n") {
+		txt.WriteString("\n")
+	}
+
+	return txt.String()
+}
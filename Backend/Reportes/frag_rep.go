@@ -0,0 +1,208 @@
+package Reportes
+
+import (
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
+	"Proyecto/comandos/utils"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// fragmentacionInodo son las extensiones de bloques de un inodo en uso
+type fragmentacionInodo struct {
+	Inodo       int32                    `json:"inodo"`
+	Ruta        string                   `json:"ruta"`
+	Tipo        string                   `json:"tipo"`
+	Bloques     int                      `json:"bloques"`
+	Extensiones []utils.ExtensionBloques `json:"extensiones"`
+}
+
+// reporteFrag es el contenido del reporte frag en JSON
+type reporteFrag struct {
+	ID                  string               `json:"id"`
+	Puntaje             float64              `json:"puntaje"`
+	BloquesUsados       int32                `json:"bloques_usados"`
+	BloquesLibres       int32                `json:"bloques_libres"`
+	ExtensionesLibres   int                  `json:"extensiones_libres"`
+	MayorExtensionLibre int32                `json:"mayor_extension_libre"`
+	Inodos              []fragmentacionInodo `json:"inodos"`
+}
+
+func generarReporteFrag(m *motor.Motor, id string, path string, formato string) (string, error) {
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP FRAG]: %w", err)
+	}
+
+	particionMontada, err := m.ParticionMontadaPorID(id)
+	if err != nil {
+		return "", errores.Nuevof(errores.NoMontada, "[REP FRAG]: Partición con ID '%s' no montada", id)
+	}
+
+	file, err := m.AbrirDiscoConCache(particionMontada.DiskPath, false)
+	if err != nil {
+		return "", errores.Nuevo(errores.Interno, "[REP FRAG]: Error al abrir disco")
+	}
+	defer file.Close()
+
+	sb, err := utils.LeerSuperBloque(file, particionMontada.Partition.Part_start)
+	if err != nil {
+		return "", errores.Nuevo(errores.Interno, "[REP FRAG]: Error al leer superbloque")
+	}
+
+	inodos, err := utils.InodosEnUso(file, &sb)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP FRAG]: %w", err)
+	}
+	bitmap, err := utils.LeerBitmapBloques(file, &sb)
+	if err != nil {
+		return "", errores.Nuevo(errores.Interno, "[REP FRAG]: Error al leer bitmap de bloques")
+	}
+
+	reporte := reporteFrag{
+		ID:      id,
+		Puntaje: utils.PuntajeFragmentacion(&sb, inodos),
+		Inodos:  []fragmentacionInodo{},
+	}
+	for _, in := range inodos {
+		tipo := "Archivo"
+		if in.Inodo.I_type[0] == '0' {
+			tipo = "Carpeta"
+		}
+		ruta := in.Ruta
+		if ruta == "" {
+			ruta = "(sin enlazar)"
+		}
+		reporte.Inodos = append(reporte.Inodos, fragmentacionInodo{
+			Inodo:       in.Numero,
+			Ruta:        ruta,
+			Tipo:        tipo,
+			Bloques:     len(in.Bloques),
+			Extensiones: utils.ExtensionesDeBloques(utils.IndicesBloques(&sb, in.Bloques)),
+		})
+	}
+
+	// Espacio libre: cada corrida de '0' en el bitmap es una extensión libre
+	var libres []int32
+	for i, b := range bitmap {
+		if b == '1' {
+			reporte.BloquesUsados++
+			continue
+		}
+		libres = append(libres, int32(i))
+	}
+	reporte.BloquesLibres = int32(len(libres))
+	for _, ext := range utils.ExtensionesDeBloques(libres) {
+		reporte.ExtensionesLibres++
+		if ext.Cantidad > reporte.MayorExtensionLibre {
+			reporte.MayorExtensionLibre = ext.Cantidad
+		}
+	}
+
+	var contenido []byte
+	if formato == "json" {
+		contenido, err = json.MarshalIndent(reporte, "", "  ")
+		if err != nil {
+			return "", errores.Nuevof(errores.Interno, "[REP FRAG]: Error al serializar: %w", err)
+		}
+	} else {
+		contenido = []byte(generarHtmlFrag(&reporte, particionMontada.DiskName))
+	}
+
+	if err := escribirReporte(m, rutaReporte, contenido, "frag", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP FRAG]: Error al escribir: %w", err)
+	}
+
+	return fmt.Sprintf("[REP FRAG]: Reporte generado en %s", rutaReporte), nil
+}
+
+// textoExtensiones muestra las extensiones como rangos de índices ("0-3, 7, 9-10")
+func textoExtensiones(extensiones []utils.ExtensionBloques) string {
+	var partes []string
+	for _, ext := range extensiones {
+		if ext.Cantidad == 1 {
+			partes = append(partes, fmt.Sprintf("%d", ext.Inicio))
+		} else {
+			partes = append(partes, fmt.Sprintf("%d-%d", ext.Inicio, ext.Inicio+ext.Cantidad-1))
+		}
+	}
+	if len(partes) == 0 {
+		return "(sin bloques)"
+	}
+	return strings.Join(partes, ", ")
+}
+
+func generarHtmlFrag(reporte *reporteFrag, diskName string) string {
+	var sb strings.Builder
+
+	sb.WriteString(`<!DOCTYPE html>
+<html lang="es">
+<head>
+    <meta charset="UTF-8">
+    <title>Reporte de Fragmentación</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 20px; background: #f9f9f9; }
+        h2 { color: #1d3557; text-align: center; }
+        table { border-collapse: collapse; width: 100%; margin-bottom: 20px; }
+        th, td { border: 1px solid #ccc; padding: 8px; text-align: left; }
+        th { background-color: #1d3557; color: white; }
+        tr:nth-child(even) { background-color: #f2f2f2; }
+        .extensiones { font-family: monospace; }
+        .fragmentado { background-color: #ffe5b4 !important; }
+        .resumen td:first-child { font-weight: bold; width: 30%; }
+    </style>
+</head>
+<body>
+    <h2>REPORTE DE FRAGMENTACIÓN — ` + html.EscapeString(reporte.ID) + ` (` + html.EscapeString(diskName) + `)</h2>
+`)
+
+	sb.WriteString(fmt.Sprintf(`
+    <table class="resumen">
+        <tr><th colspan="2">Resumen</th></tr>
+        <tr><td>Fragmentación</td><td>%.1f%%</td></tr>
+        <tr><td>Bloques usados</td><td>%d</td></tr>
+        <tr><td>Bloques libres</td><td>%d</td></tr>
+        <tr><td>Extensiones libres</td><td>%d</td></tr>
+        <tr><td>Mayor extensión libre</td><td>%d bloques</td></tr>
+    </table>
+
+    <table>
+        <tr>
+            <th>Inodo</th>
+            <th>Ruta</th>
+            <th>Tipo</th>
+            <th>Bloques</th>
+            <th>Extensiones</th>
+            <th>Fragmentos</th>
+        </tr>`,
+		reporte.Puntaje, reporte.BloquesUsados, reporte.BloquesLibres, reporte.ExtensionesLibres, reporte.MayorExtensionLibre))
+
+	for _, in := range reporte.Inodos {
+		clase := ""
+		if len(in.Extensiones) > 1 {
+			clase = ` class="fragmentado"`
+		}
+		sb.WriteString(fmt.Sprintf(`
+        <tr%s>
+            <td>%d</td>
+            <td>%s</td>
+            <td>%s</td>
+            <td>%d</td>
+            <td class="extensiones">%s</td>
+            <td>%d</td>
+        </tr>`,
+			clase, in.Inodo, html.EscapeString(in.Ruta), in.Tipo, in.Bloques, textoExtensiones(in.Extensiones), len(in.Extensiones)))
+	}
+
+	sb.WriteString(`
+    </table>
+    <p style="text-align: center; margin-top: 30px; color: #666;">
+        Reporte de fragmentación generado el ` + utils.IntFechaToStr(utils.ObFechaInt()) + `
+    </p>
+</body>
+</html>`)
+
+	return sb.String()
+}
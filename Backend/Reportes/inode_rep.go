@@ -2,31 +2,28 @@ package Reportes
 
 import (
 	"Proyecto/Estructuras/structures"
-	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
 	"Proyecto/comandos/utils"
-	"encoding/binary"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 )
 
-func generarReporteInode(id string, path string) (string, bool) {
-	repDir := "VDIC-MIA/Rep"
-	if _, err := os.Stat(repDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(repDir, 0755); err != nil {
-			return fmt.Sprintf("[REP INODE]: Error al crear %s: %v", repDir, err), true
-		}
+func generarReporteInode(m *motor.Motor, id string, path string, formato string) (string, error) {
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP INODE]: %w", err)
 	}
 
-	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	particionMontada, err := m.ParticionMontadaPorID(id)
 	if err != nil {
-		return fmt.Sprintf("[REP INODE]: Partición con ID '%s' no montada", id), true
+		return "", errores.Nuevof(errores.NoMontada, "[REP INODE]: Partición con ID '%s' no montada", id)
 	}
 
-	file, err := os.Open(particionMontada.DiskPath)
+	file, err := m.AbrirDiscoConCache(particionMontada.DiskPath, false)
 	if err != nil {
-		return "[REP INODE]: Error al abrir disco", true
+		return "", errores.Nuevo(errores.Interno, "[REP INODE]: Error al abrir disco")
 	}
 	defer file.Close()
 
@@ -34,23 +31,24 @@ func generarReporteInode(id string, path string) (string, bool) {
 
 	sb, err := utils.LeerSuperBloque(file, inicioParticion)
 	if err != nil {
-		return "[REP INODE]: Error al leer superbloque", true
+		return "", errores.Nuevo(errores.Interno, "[REP INODE]: Error al leer superbloque")
 	}
 
-	htmlContent := generarHtmlInode(file, sb)
-
-	baseName := filepath.Base(path)
-	htmlFileName := strings.TrimSuffix(baseName, filepath.Ext(baseName)) + ".html"
-	htmlFilePath := filepath.Join(repDir, htmlFileName)
+	var contenido string
+	if formato == "dot" {
+		contenido = generarDotInode(file, sb)
+	} else {
+		contenido = generarHtmlInode(file, sb)
+	}
 
-	if err := os.WriteFile(htmlFilePath, []byte(htmlContent), 0644); err != nil {
-		return fmt.Sprintf("[REP INODE]: Error al escribir: %v", err), true
+	if err := escribirReporte(m, rutaReporte, []byte(contenido), "inode", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP INODE]: Error al escribir: %w", err)
 	}
 
-	return fmt.Sprintf("[REP INODE]: Reporte generado en %s", htmlFilePath), false
+	return fmt.Sprintf("[REP INODE]: Reporte generado en %s", rutaReporte), nil
 }
 
-func generarHtmlInode(file *os.File, sb structures.SuperBloque) string {
+func generarHtmlInode(file almacenamiento.Disco, sb structures.SuperBloque) string {
 	var sbBuilder strings.Builder
 
 	sbBuilder.WriteString(`<!DOCTYPE html>
@@ -79,13 +77,8 @@ func generarHtmlInode(file *os.File, sb structures.SuperBloque) string {
     <h2>REPORTE DE INODOS (solo usados)</h2>
     <div class="container">`)
 
-	bitmapInodos := make([]byte, sb.S_inodes_count)
-	if _, err := file.Seek(int64(sb.S_bm_inode_start), 0); err != nil {
-		sbBuilder.WriteString(`<p>Error al leer bitmap</p>`)
-		sbBuilder.WriteString(`</div></body></html>`)
-		return sbBuilder.String()
-	}
-	if _, err := file.Read(bitmapInodos); err != nil {
+	bitmapInodos, err := utils.LeerBitmapInodos(file, &sb)
+	if err != nil {
 		sbBuilder.WriteString(`<p>Error al leer bitmap</p>`)
 		sbBuilder.WriteString(`</div></body></html>`)
 		return sbBuilder.String()
@@ -99,10 +92,7 @@ func generarHtmlInode(file *os.File, sb structures.SuperBloque) string {
 
 		posInodo := sb.S_inode_start + i*sb.S_inode_s
 		var inodo structures.TablaInodo
-		if _, err := file.Seek(int64(posInodo), 0); err != nil {
-			continue
-		}
-		if err := binary.Read(file, binary.LittleEndian, &inodo); err != nil {
+		if err := utils.LeerEstructura(file, posInodo, &inodo); err != nil {
 			continue
 		}
 
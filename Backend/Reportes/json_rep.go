@@ -0,0 +1,418 @@
+package Reportes
+
+import (
+	"Proyecto/Estructuras/size"
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
+	"Proyecto/comandos/utils"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// InspeccionDisco reúne todas las estructuras de una partición montada con sus offsets
+type InspeccionDisco struct {
+	ID            string                 `json:"id"`
+	Disco         string                 `json:"disco"`
+	Particion     string                 `json:"particion"`
+	MBR           *MBRInspeccion         `json:"mbr,omitempty"`
+	EBRs          []EBRInspeccion        `json:"ebrs,omitempty"`
+	SuperBloque   *SuperBloqueInspeccion `json:"superbloque,omitempty"`
+	BitmapInodos  *BitmapInspeccion      `json:"bitmap_inodos,omitempty"`
+	BitmapBloques *BitmapInspeccion      `json:"bitmap_bloques,omitempty"`
+	Inodos        []InodoInspeccion      `json:"inodos,omitempty"`
+	Bloques       []BloqueInspeccion     `json:"bloques,omitempty"`
+}
+
+type MBRInspeccion struct {
+	Offset             int32                 `json:"offset"`
+	Mbr_tamano         int32                 `json:"mbr_tamano"`
+	Mbr_fecha_creacion int32                 `json:"mbr_fecha_creacion"`
+	Mbr_disk_signature int32                 `json:"mbr_disk_signature"`
+	Dsk_fit            string                `json:"dsk_fit"`
+	Mbr_partitions     []ParticionInspeccion `json:"mbr_partitions"`
+}
+
+type ParticionInspeccion struct {
+	Offset           int32  `json:"offset"`
+	Part_status      int8   `json:"part_status"`
+	Part_type        string `json:"part_type"`
+	Part_fit         string `json:"part_fit"`
+	Part_start       int32  `json:"part_start"`
+	Part_s           int32  `json:"part_s"`
+	Part_name        string `json:"part_name"`
+	Part_correlative int32  `json:"part_correlative"`
+	Part_id          string `json:"part_id"`
+}
+
+type EBRInspeccion struct {
+	Offset     int32  `json:"offset"`
+	Part_mount int8   `json:"part_mount"`
+	Part_fit   string `json:"part_fit"`
+	Part_start int32  `json:"part_start"`
+	Part_s     int32  `json:"part_s"`
+	Part_next  int32  `json:"part_next"`
+	Name       string `json:"name"`
+}
+
+type SuperBloqueInspeccion struct {
+	Offset              int32 `json:"offset"`
+	S_filesistem_type   int32 `json:"s_filesistem_type"`
+	S_inodes_count      int32 `json:"s_inodes_count"`
+	S_blocks_count      int32 `json:"s_blocks_count"`
+	S_free_blocks_count int32 `json:"s_free_blocks_count"`
+	S_free_inodes_count int32 `json:"s_free_inodes_count"`
+	S_mtime             int32 `json:"s_mtime"`
+	S_umtime            int32 `json:"s_umtime"`
+	S_mnt_count         int32 `json:"s_mnt_count"`
+	S_magic             int32 `json:"s_magic"`
+	S_inode_s           int32 `json:"s_inode_s"`
+	S_block_s           int32 `json:"s_block_s"`
+	S_first_ino         int32 `json:"s_first_ino"`
+	S_first_blo         int32 `json:"s_first_blo"`
+	S_bm_inode_start    int32 `json:"s_bm_inode_start"`
+	S_bm_block_start    int32 `json:"s_bm_block_start"`
+	S_inode_start       int32 `json:"s_inode_start"`
+	S_block_start       int32 `json:"s_block_start"`
+}
+
+type BitmapInspeccion struct {
+	Offset    int32  `json:"offset"`
+	Total     int32  `json:"total"`
+	Usados    int32  `json:"usados"`
+	Contenido string `json:"contenido"`
+}
+
+type InodoInspeccion struct {
+	Numero  int32     `json:"numero"`
+	Offset  int32     `json:"offset"`
+	I_uid   int32     `json:"i_uid"`
+	I_gid   int32     `json:"i_gid"`
+	I_s     int32     `json:"i_s"`
+	I_atime int32     `json:"i_atime"`
+	I_ctime int32     `json:"i_ctime"`
+	I_mtime int32     `json:"i_mtime"`
+	I_block [15]int32 `json:"i_block"`
+	I_type  string    `json:"i_type"`
+	I_perm  string    `json:"i_perm"`
+}
+
+// BloqueInspeccion llena solo los campos que corresponden a su tipo
+type BloqueInspeccion struct {
+	Numero     int32               `json:"numero"`
+	Offset     int32               `json:"offset"`
+	Tipo       string              `json:"tipo"` // "carpeta", "archivo", "apuntador" o "desconocido"
+	B_content  []EntradaInspeccion `json:"b_content,omitempty"`
+	Contenido  *string             `json:"contenido,omitempty"`
+	B_pointers []int32             `json:"b_pointers,omitempty"`
+}
+
+type EntradaInspeccion struct {
+	B_name  string `json:"b_name"`
+	B_inodo int32  `json:"b_inodo"`
+}
+
+// seccionesJSON indica qué partes de la inspección escribe cada reporte con -format=json
+var seccionesJSON = map[string][]string{
+	"mbr":      {"mbr", "ebrs"},
+	"disk":     {"mbr", "ebrs"},
+	"sb":       {"superbloque"},
+	"bm_inode": {"bitmap_inodos"},
+	"bm_bloc":  {"bitmap_bloques"},
+	"inode":    {"inodos"},
+	"block":    {"bloques"},
+	"tree":     {"inodos", "bloques"},
+}
+
+// InspeccionarParticion lee el disco de la partición montada y arma la inspección completa
+func InspeccionarParticion(m *motor.Motor, id string) (*InspeccionDisco, error) {
+	particionMontada, err := m.ParticionMontadaPorID(id)
+	if err != nil {
+		return nil, errores.Nuevof(errores.NoMontada, "partición con ID '%s' no encontrada o no montada", id)
+	}
+
+	mbr, err := m.LeerMBR(particionMontada.DiskPath)
+	if err != nil {
+		return nil, errores.Nuevof(errores.Interno, "error al leer el MBR: %w", err)
+	}
+
+	file, err := m.AbrirDiscoConCache(particionMontada.DiskPath, false)
+	if err != nil {
+		return nil, errores.Nuevof(errores.Interno, "error al abrir el disco: %w", err)
+	}
+	defer file.Close()
+
+	inspeccion := &InspeccionDisco{
+		ID:        id,
+		Disco:     particionMontada.DiskName,
+		Particion: particionMontada.PartName,
+		MBR:       inspeccionarMBR(mbr),
+	}
+
+	for _, part := range mbr.Mbr_partitions {
+		if part.Part_type != 'E' || part.Part_s <= 0 {
+			continue
+		}
+		for _, ebr := range leerEBRs(m, particionMontada.DiskPath, part.Part_start) {
+			inspeccion.EBRs = append(inspeccion.EBRs, EBRInspeccion{
+				Offset:     ebr.Part_start - size.SizeEBR(),
+				Part_mount: ebr.Part_mount,
+				Part_fit:   string(ebr.Part_fit),
+				Part_start: ebr.Part_start,
+				Part_s:     ebr.Part_s,
+				Part_next:  ebr.Part_next,
+				Name:       utils.ConvertirByteAString(ebr.Name[:]),
+			})
+		}
+	}
+
+	// Sin formatear solo se devuelven las estructuras del disco
+	inicio := particionMontada.Partition.Part_start
+	sb, err := utils.LeerSuperBloque(file, inicio)
+	if err != nil || sb.S_magic != 0xEF53 {
+		return inspeccion, nil
+	}
+	inspeccion.SuperBloque = &SuperBloqueInspeccion{
+		Offset:              inicio,
+		S_filesistem_type:   sb.S_filesistem_type,
+		S_inodes_count:      sb.S_inodes_count,
+		S_blocks_count:      sb.S_blocks_count,
+		S_free_blocks_count: sb.S_free_blocks_count,
+		S_free_inodes_count: sb.S_free_inodes_count,
+		S_mtime:             sb.S_mtime,
+		S_umtime:            sb.S_umtime,
+		S_mnt_count:         sb.S_mnt_count,
+		S_magic:             sb.S_magic,
+		S_inode_s:           sb.S_inode_s,
+		S_block_s:           sb.S_block_s,
+		S_first_ino:         sb.S_first_ino,
+		S_first_blo:         sb.S_first_blo,
+		S_bm_inode_start:    sb.S_bm_inode_start,
+		S_bm_block_start:    sb.S_bm_block_start,
+		S_inode_start:       sb.S_inode_start,
+		S_block_start:       sb.S_block_start,
+	}
+
+	bitmapInodos, err := utils.LeerBitmapInodos(file, &sb)
+	if err != nil {
+		return nil, errores.Nuevof(errores.Interno, "error al leer bitmap de inodos: %w", err)
+	}
+	bitmapBloques, err := utils.LeerBitmapBloques(file, &sb)
+	if err != nil {
+		return nil, errores.Nuevof(errores.Interno, "error al leer bitmap de bloques: %w", err)
+	}
+	inspeccion.BitmapInodos = inspeccionarBitmap(sb.S_bm_inode_start, bitmapInodos)
+	inspeccion.BitmapBloques = inspeccionarBitmap(sb.S_bm_block_start, bitmapBloques)
+
+	tipos := make(map[int32]string)
+	for i := int32(0); i < sb.S_inodes_count; i++ {
+		if bitmapInodos[i] != '1' {
+			continue
+		}
+		pos := sb.S_inode_start + i*sb.S_inode_s
+		inodo, err := utils.LeerInodoPorPosicion(file, pos)
+		if err != nil {
+			continue
+		}
+		inspeccion.Inodos = append(inspeccion.Inodos, InodoInspeccion{
+			Numero:  i,
+			Offset:  pos,
+			I_uid:   inodo.I_uid,
+			I_gid:   inodo.I_gid,
+			I_s:     inodo.I_s,
+			I_atime: inodo.I_atime,
+			I_ctime: inodo.I_ctime,
+			I_mtime: inodo.I_mtime,
+			I_block: inodo.I_block,
+			I_type:  string(inodo.I_type[:]),
+			I_perm:  string(inodo.I_perm[:]),
+		})
+		clasificarBloquesInodo(file, &inodo, tipos)
+	}
+
+	for i := int32(0); i < sb.S_blocks_count; i++ {
+		if bitmapBloques[i] != '1' {
+			continue
+		}
+		pos := sb.S_block_start + i*sb.S_block_s
+		tipo, ok := tipos[pos]
+		if !ok {
+			tipo = "desconocido"
+		}
+		bloque, err := inspeccionarBloque(file, &sb, pos, tipo)
+		if err != nil {
+			continue
+		}
+		inspeccion.Bloques = append(inspeccion.Bloques, bloque)
+	}
+
+	return inspeccion, nil
+}
+
+func inspeccionarMBR(mbr structures.MBR) *MBRInspeccion {
+	resultado := &MBRInspeccion{
+		Offset:             0,
+		Mbr_tamano:         mbr.Mbr_tamano,
+		Mbr_fecha_creacion: mbr.Mbr_fecha_creacion,
+		Mbr_disk_signature: mbr.Mbr_disk_signature,
+		Dsk_fit:            string(mbr.Dsk_fit),
+	}
+	for i, part := range mbr.Mbr_partitions {
+		resultado.Mbr_partitions = append(resultado.Mbr_partitions, ParticionInspeccion{
+			Offset:           size.SizeMBR_NotPartitions() + int32(i)*size.SizePartition(),
+			Part_status:      part.Part_status,
+			Part_type:        string(part.Part_type),
+			Part_fit:         string(part.Part_fit),
+			Part_start:       part.Part_start,
+			Part_s:           part.Part_s,
+			Part_name:        utils.ConvertirByteAString(part.Part_name[:]),
+			Part_correlative: part.Part_correlative,
+			Part_id:          utils.ConvertirByteAString(part.Part_id[:]),
+		})
+	}
+	return resultado
+}
+
+func inspeccionarBitmap(offset int32, bitmap []byte) *BitmapInspeccion {
+	return &BitmapInspeccion{
+		Offset:    offset,
+		Total:     int32(len(bitmap)),
+		Usados:    int32(strings.Count(string(bitmap), "1")),
+		Contenido: string(bitmap),
+	}
+}
+
+// clasificarBloquesInodo anota el tipo de cada bloque que apunta el inodo.
+// I_block[12], [13] y [14] son apuntadores simples, dobles y triples.
+func clasificarBloquesInodo(file almacenamiento.Disco, inodo *structures.TablaInodo, tipos map[int32]string) {
+	tipoDatos := "archivo"
+	if inodo.I_type[0] == '0' {
+		tipoDatos = "carpeta"
+	}
+
+	var marcar func(pos int32, nivel int)
+	marcar = func(pos int32, nivel int) {
+		if pos == -1 {
+			return
+		}
+		if _, ok := tipos[pos]; ok {
+			return
+		}
+		if nivel == 0 {
+			tipos[pos] = tipoDatos
+			return
+		}
+		tipos[pos] = "apuntador"
+
+		var apuntador structures.BloqueApuntador
+		if err := utils.LeerEstructura(file, pos, &apuntador); err != nil {
+			return
+		}
+		for _, p := range apuntador.B_pointers {
+			marcar(p, nivel-1)
+		}
+	}
+
+	for i, pos := range inodo.I_block {
+		nivel := 0
+		if i >= 12 {
+			nivel = i - 11
+		}
+		marcar(pos, nivel)
+	}
+}
+
+func inspeccionarBloque(file almacenamiento.Disco, sb *structures.SuperBloque, pos int32, tipo string) (BloqueInspeccion, error) {
+	bloque := BloqueInspeccion{
+		Numero: indiceBloque(sb, pos),
+		Offset: pos,
+		Tipo:   tipo,
+	}
+
+	switch tipo {
+	case "carpeta":
+		carpeta, err := leerBloqueCarpeta(file, pos)
+		if err != nil {
+			return bloque, err
+		}
+		for _, entrada := range carpeta.B_content {
+			bloque.B_content = append(bloque.B_content, EntradaInspeccion{
+				B_name:  utils.ConvertirByteAString(entrada.B_name[:]),
+				B_inodo: entrada.B_inodo,
+			})
+		}
+	case "apuntador":
+		var apuntador structures.BloqueApuntador
+		if err := utils.LeerEstructura(file, pos, &apuntador); err != nil {
+			return bloque, err
+		}
+		bloque.B_pointers = apuntador.B_pointers[:]
+	default:
+		// Archivos y bloques sin dueño se muestran como texto
+		archivo, err := leerBloqueArchivo(file, pos)
+		if err != nil {
+			return bloque, err
+		}
+		contenido := strings.TrimRight(string(archivo.B_content[:]), "\x00")
+		bloque.Contenido = &contenido
+	}
+	return bloque, nil
+}
+
+// filtrarSecciones deja en la inspección solo las secciones indicadas
+func filtrarSecciones(inspeccion *InspeccionDisco, secciones []string) {
+	incluir := make(map[string]bool)
+	for _, s := range secciones {
+		incluir[s] = true
+	}
+	if !incluir["mbr"] {
+		inspeccion.MBR = nil
+	}
+	if !incluir["ebrs"] {
+		inspeccion.EBRs = nil
+	}
+	if !incluir["superbloque"] {
+		inspeccion.SuperBloque = nil
+	}
+	if !incluir["bitmap_inodos"] {
+		inspeccion.BitmapInodos = nil
+	}
+	if !incluir["bitmap_bloques"] {
+		inspeccion.BitmapBloques = nil
+	}
+	if !incluir["inodos"] {
+		inspeccion.Inodos = nil
+	}
+	if !incluir["bloques"] {
+		inspeccion.Bloques = nil
+	}
+}
+
+// generarReporteJSON escribe en JSON las estructuras que cubre el reporte pedido
+func generarReporteJSON(m *motor.Motor, nombre string, id string, path string) (string, error) {
+	etiqueta := fmt.Sprintf("[REP %s]", strings.ToUpper(nombre))
+
+	rutaReporte, err := resolverRutaReporte(m, path, "json")
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "%s: %w", etiqueta, err)
+	}
+
+	inspeccion, err := InspeccionarParticion(m, id)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "%s: %w", etiqueta, err)
+	}
+	filtrarSecciones(inspeccion, seccionesJSON[nombre])
+
+	contenido, err := json.MarshalIndent(inspeccion, "", "  ")
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "%s: Error al serializar: %w", etiqueta, err)
+	}
+
+	if err := escribirReporte(m, rutaReporte, contenido, nombre, id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "%s: Error al escribir: %w", etiqueta, err)
+	}
+
+	return fmt.Sprintf("%s: Reporte JSON generado en %s", etiqueta, rutaReporte), nil
+}
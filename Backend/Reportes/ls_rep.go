@@ -0,0 +1,234 @@
+package Reportes
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
+	"Proyecto/comandos/utils"
+	"encoding/json"
+	"fmt"
+	"html"
+	"path"
+	"strings"
+)
+
+// entradaLs es una fila del reporte ls
+type entradaLs struct {
+	Nombre      string `json:"nombre"`
+	Tipo        string `json:"tipo"`
+	Permisos    string `json:"permisos"`
+	Propietario string `json:"propietario"`
+	Grupo       string `json:"grupo"`
+	Tamanio     int32  `json:"tamanio"`
+	Creado      string `json:"creado"`
+	Modificado  string `json:"modificado"`
+	Inodo       int32  `json:"inodo"`
+}
+
+// listadoLs es el contenido del reporte ls en JSON
+type listadoLs struct {
+	ID       string      `json:"id"`
+	Ruta     string      `json:"ruta"`
+	Entradas []entradaLs `json:"entradas"`
+}
+
+func generarReporteLs(m *motor.Motor, id string, namereport string, rutaCarpeta string, formato string) (string, error) {
+	if rutaCarpeta == "" {
+		return "", errores.Nuevo(errores.ParametroInvalido, "[REP LS]: Parámetro -path_file_ls es obligatorio")
+	}
+	if !strings.HasPrefix(rutaCarpeta, "/") {
+		rutaCarpeta = "/" + rutaCarpeta
+	}
+	rutaCarpeta = path.Clean(rutaCarpeta)
+
+	// Igual que el reporte file: se lista solo lo que el usuario de la sesión puede leer
+	sesion := m.Sesion()
+	if sesion == nil {
+		return "", errores.Nuevo(errores.SinSesion, "[REP LS]: No hay sesión activa. Use el comando LOGIN")
+	}
+	if sesion.ID != id {
+		return "", errores.Nuevof(errores.SinSesion, "[REP LS]: La sesión activa pertenece a la partición '%s', no a '%s'", sesion.ID, id)
+	}
+
+	rutaReporte, err := resolverRutaReporte(m, namereport, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP LS]: %w", err)
+	}
+
+	particionMontada, err := m.ParticionMontadaPorID(id)
+	if err != nil {
+		return "", errores.Nuevof(errores.NoMontada, "[REP LS]: Partición con ID '%s' no montada", id)
+	}
+
+	file, err := m.AbrirDiscoConCache(particionMontada.DiskPath, false)
+	if err != nil {
+		return "", errores.Nuevo(errores.Interno, "[REP LS]: Error al abrir disco")
+	}
+	defer file.Close()
+
+	sb, err := utils.LeerSuperBloque(file, particionMontada.Partition.Part_start)
+	if err != nil {
+		return "", errores.Nuevo(errores.Interno, "[REP LS]: Error al leer superbloque")
+	}
+
+	carpeta, _, err := utils.LeerInodoDesdeRuta(file, &sb, rutaCarpeta)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP LS]: %w", err)
+	}
+	if carpeta.I_type[0] != '0' {
+		return "", errores.Nuevof(errores.ParametroInvalido, "[REP LS]: '%s' no es una carpeta", rutaCarpeta)
+	}
+	if !utils.TienePermisoLectura(&carpeta, sesion.Credenciales(), "") {
+		return "", errores.Nuevof(errores.SinPermiso, "[REP LS]: No tiene permisos de lectura sobre '%s'", rutaCarpeta)
+	}
+
+	entradas, err := listarEntradasLs(file, &sb, &carpeta)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP LS]: Error al leer '%s': %w", rutaCarpeta, err)
+	}
+
+	var contenido []byte
+	if formato == "json" {
+		contenido, err = json.MarshalIndent(listadoLs{ID: id, Ruta: rutaCarpeta, Entradas: entradas}, "", "  ")
+		if err != nil {
+			return "", errores.Nuevof(errores.Interno, "[REP LS]: Error al serializar: %w", err)
+		}
+	} else {
+		contenido = []byte(generarHtmlLs(rutaCarpeta, particionMontada.DiskName, entradas))
+	}
+
+	if err := escribirReporte(m, rutaReporte, contenido, "ls", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP LS]: Error al escribir: %w", err)
+	}
+
+	return fmt.Sprintf("[REP LS]: Reporte generado en %s", rutaReporte), nil
+}
+
+func listarEntradasLs(file almacenamiento.Disco, sb *structures.SuperBloque, carpeta *structures.TablaInodo) ([]entradaLs, error) {
+	lista, err := utils.ListarCarpeta(file, carpeta)
+	if err != nil {
+		return nil, err
+	}
+
+	usuarios, grupos := utils.LeerUsuariosGrupos(file, sb)
+	entradas := []entradaLs{}
+	for _, e := range lista {
+		inodo, err := utils.LeerInodoPorPosicion(file, e.PosInodo)
+		if err != nil {
+			continue
+		}
+
+		tipo := "Archivo"
+		if inodo.I_type[0] == '0' {
+			tipo = "Carpeta"
+		}
+		entradas = append(entradas, entradaLs{
+			Nombre:      e.Nombre,
+			Tipo:        tipo,
+			Permisos:    permisoSimbolico(&inodo),
+			Propietario: usuarios[inodo.I_uid],
+			Grupo:       grupos[inodo.I_gid],
+			Tamanio:     inodo.I_s,
+			Creado:      utils.IntFechaToStr(inodo.I_ctime),
+			Modificado:  utils.IntFechaToStr(inodo.I_mtime),
+			Inodo:       indiceInodo(sb, e.PosInodo),
+		})
+	}
+	return entradas, nil
+}
+
+// permisoSimbolico convierte I_perm ("664") al formato de ls ("-rw-rw-r--")
+func permisoSimbolico(inodo *structures.TablaInodo) string {
+	var sb strings.Builder
+	if inodo.I_type[0] == '0' {
+		sb.WriteByte('d')
+	} else {
+		sb.WriteByte('-')
+	}
+
+	for _, digito := range inodo.I_perm {
+		valor := byte(0)
+		if digito >= '0' && digito <= '7' {
+			valor = digito - '0'
+		}
+		for i, letra := range "rwx" {
+			if valor&(4>>i) != 0 {
+				sb.WriteRune(letra)
+			} else {
+				sb.WriteByte('-')
+			}
+		}
+	}
+	return sb.String()
+}
+
+func generarHtmlLs(ruta string, diskName string, entradas []entradaLs) string {
+	var sb strings.Builder
+
+	sb.WriteString(`<!DOCTYPE html>
+<html lang="es">
+<head>
+    <meta charset="UTF-8">
+    <title>Reporte LS</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 20px; background: #f9f9f9; }
+        h2 { color: #6a2c70; text-align: center; }
+        table { border-collapse: collapse; width: 100%; margin-bottom: 20px; }
+        th, td { border: 1px solid #ccc; padding: 8px; text-align: left; }
+        th { background-color: #6a2c70; color: white; }
+        tr:nth-child(even) { background-color: #f2f2f2; }
+        .permisos { font-family: monospace; }
+        .carpeta { background-color: #ffcccc; }
+    </style>
+</head>
+<body>
+    <h2>REPORTE LS — ` + html.EscapeString(ruta) + ` (` + html.EscapeString(diskName) + `)</h2>
+
+    <table>
+        <tr>
+            <th>Permisos</th>
+            <th>Propietario</th>
+            <th>Grupo</th>
+            <th>Tamaño</th>
+            <th>Fecha creación</th>
+            <th>Fecha modificación</th>
+            <th>Tipo</th>
+            <th>Nombre</th>
+        </tr>`)
+
+	for _, e := range entradas {
+		clase := ""
+		if e.Tipo == "Carpeta" {
+			clase = ` class="carpeta"`
+		}
+		sb.WriteString(fmt.Sprintf(`
+        <tr%s>
+            <td class="permisos">%s</td>
+            <td>%s</td>
+            <td>%s</td>
+            <td>%d</td>
+            <td>%s</td>
+            <td>%s</td>
+            <td>%s</td>
+            <td>%s</td>
+        </tr>`,
+			clase, e.Permisos, html.EscapeString(e.Propietario), html.EscapeString(e.Grupo),
+			e.Tamanio, e.Creado, e.Modificado, e.Tipo, html.EscapeString(e.Nombre)))
+	}
+
+	if len(entradas) == 0 {
+		sb.WriteString(`
+        <tr><td colspan="8" style="text-align: center;">(carpeta vacía)</td></tr>`)
+	}
+
+	sb.WriteString(`
+    </table>
+    <p style="text-align: center; margin-top: 30px; color: #666;">
+        Reporte LS generado el ` + utils.IntFechaToStr(utils.ObFechaInt()) + `
+    </p>
+</body>
+</html>`)
+
+	return sb.String()
+}
@@ -2,58 +2,52 @@ package Reportes
 
 import (
 	"Proyecto/Estructuras/structures"
-	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
 	"Proyecto/comandos/utils"
 	"encoding/binary"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 )
 
-// generarReporteMBR genera un reporte HTML del MBR y todos los EBRs asociados
-func generarReporteMBR(id string, path string) (string, bool) {
-	repDir := "VDIC-MIA/Rep"
-
-	// 0. Asegurar que el directorio de reportes exista
-	if _, err := os.Stat(repDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(repDir, 0755); err != nil {
-			return fmt.Sprintf("[REP MBR]: Error al crear carpeta %s: %v", repDir, err), true
-		}
-	} else if err != nil {
-		return fmt.Sprintf("[REP MBR]: Error al verificar carpeta %s: %v", repDir, err), true
+// generarReporteMBR genera un reporte HTML (o DOT) del MBR y todos los EBRs asociados
+func generarReporteMBR(m *motor.Motor, id string, path string, formato string) (string, error) {
+	// 0. Validar la ruta del reporte dentro de la carpeta de reportes
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP MBR]: %w", err)
 	}
 
 	// 1. Obtener la partición montada por ID
-	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	particionMontada, err := m.ParticionMontadaPorID(id)
 	if err != nil {
-		return fmt.Sprintf("[REP MBR]: Partición con ID '%s' no encontrada o no montada", id), true
+		return "", errores.Nuevof(errores.NoMontada, "[REP MBR]: Partición con ID '%s' no encontrada o no montada", id)
 	}
 
 	// 2. Leer el MBR del disco
-	mbr, er, strError := utils.ObtenerEstructuraMBR(particionMontada.DiskPath)
-	if er {
-		return strError, er
+	mbr, err := m.LeerMBR(particionMontada.DiskPath)
+	if err != nil {
+		return "", err
 	}
 
-	// 3. Generar el contenido HTML incluyendo EBRs
-	htmlContent := generarHtmlMBRConEBRs(mbr, particionMontada.DiskPath, particionMontada.DiskName)
-
-	// 4. Construir la ruta final del reporte
-	baseName := filepath.Base(path)
-	htmlFileName := strings.TrimSuffix(baseName, filepath.Ext(baseName)) + ".html"
-	htmlFilePath := filepath.Join(repDir, htmlFileName)
+	// 3. Generar el contenido incluyendo EBRs
+	var contenido string
+	if formato == "dot" {
+		contenido = generarDotMBR(m, mbr, particionMontada.DiskPath, particionMontada.DiskName)
+	} else {
+		contenido = generarHtmlMBRConEBRs(m, mbr, particionMontada.DiskPath, particionMontada.DiskName)
+	}
 
-	// 5. Escribir el archivo HTML
-	if err := os.WriteFile(htmlFilePath, []byte(htmlContent), 0644); err != nil {
-		return fmt.Sprintf("[REP MBR]: Error al escribir archivo HTML en %s: %v", htmlFilePath, err), true
+	// 4. Escribir el archivo
+	if err := escribirReporte(m, rutaReporte, []byte(contenido), "mbr", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP MBR]: Error al escribir archivo %s en %s: %w", strings.ToUpper(formato), rutaReporte, err)
 	}
 
-	return fmt.Sprintf("[REP MBR]: Reporte MBR+EBR HTML generado exitosamente en %s", htmlFilePath), false
+	return fmt.Sprintf("[REP MBR]: Reporte MBR+EBR %s generado exitosamente en %s", strings.ToUpper(formato), rutaReporte), nil
 }
 
 // generarHtmlMBRConEBRs genera HTML con MBR y todos los EBRs
-func generarHtmlMBRConEBRs(mbr structures.MBR, diskPath, diskName string) string {
+func generarHtmlMBRConEBRs(m *motor.Motor, mbr structures.MBR, diskPath, diskName string) string {
 	var sb strings.Builder
 
 	sb.WriteString(`<!DOCTYPE html>
@@ -130,7 +124,7 @@ func generarHtmlMBRConEBRs(mbr structures.MBR, diskPath, diskName string) string
 
 		// Si es partición extendida, leer y mostrar EBRs
 		if typeStr == "E" {
-			ebrs := leerEBRs(diskPath, part.Part_start)
+			ebrs := leerEBRs(m, diskPath, part.Part_start)
 			if len(ebrs) == 0 {
 				sb.WriteString(`<p style="color: #d32f2f;">⚠️ No se encontraron EBRs en esta partición extendida.</p>`)
 			} else {
@@ -174,11 +168,11 @@ func generarHtmlMBRConEBRs(mbr structures.MBR, diskPath, diskName string) string
 }
 
 // leerEBRs lee la cadena enlazada de EBRs desde una partición extendida
-func leerEBRs(diskPath string, extendidaStart int32) []structures.EBR {
+func leerEBRs(m *motor.Motor, diskPath string, extendidaStart int32) []structures.EBR {
 	var ebrs []structures.EBR
 	current := extendidaStart
 
-	file, err := os.Open(diskPath)
+	file, err := m.AbrirDisco(diskPath, false)
 	if err != nil {
 		return ebrs
 	}
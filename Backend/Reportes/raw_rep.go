@@ -0,0 +1,542 @@
+package Reportes
+
+import (
+	"Proyecto/Estructuras/size"
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
+	"Proyecto/comandos/utils"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	rawLenPorDefecto = 512
+	rawLenMaximo     = 64 * 1024
+	rawBytesPorFila  = 16
+)
+
+// campoRaw es un campo de una estructura conocida ubicado en el disco
+type campoRaw struct {
+	Estructura string
+	Clase      string
+	Campo      string
+	Offset     int64
+	Tam        int64
+	Valor      string
+}
+
+// coloresRaw asigna un color por tipo de estructura en el volcado
+var coloresRaw = []struct {
+	clase  string
+	nombre string
+	color  string
+}{
+	{"mbr", "MBR", "#ffd6a5"},
+	{"ebr", "EBR", "#fdffb6"},
+	{"sb", "SuperBloque", "#caffbf"},
+	{"bitmap", "Bitmap", "#9bf6ff"},
+	{"inodo", "Inodo", "#a0c4ff"},
+	{"carpeta", "Bloque carpeta", "#ffc6ff"},
+	{"archivo", "Bloque archivo", "#bdb2ff"},
+	{"apuntador", "Bloque apuntador", "#ffadad"},
+	{"desconocido", "Bloque sin dueño", "#dddddd"},
+}
+
+// generarReporteRaw vuelca en hexadecimal una región del disco y etiqueta cada byte con el
+// campo de la estructura que lo contiene. -offset acepta decimal o hexadecimal (0x...).
+func generarReporteRaw(m *motor.Motor, path string, diskName string, offsetStr string, lenStr string, formato string) (string, error) {
+	if diskName == "" {
+		return "", errores.Nuevo(errores.ParametroInvalido, "[REP RAW]: Parámetro -diskname es obligatorio")
+	}
+	if strings.ContainsAny(diskName, `/\`) || strings.HasPrefix(diskName, ".") {
+		return "", errores.Nuevof(errores.ParametroInvalido, "[REP RAW]: Nombre de disco inválido: '%s'", diskName)
+	}
+	if !strings.Contains(diskName, ".") {
+		diskName += ".mia"
+	}
+
+	offset := int64(0)
+	if offsetStr != "" {
+		valor, err := strconv.ParseInt(offsetStr, 0, 64)
+		if err != nil || valor < 0 {
+			return "", errores.Nuevof(errores.ParametroInvalido, "[REP RAW]: -offset inválido: '%s'", offsetStr)
+		}
+		offset = valor
+	}
+
+	largo := int64(rawLenPorDefecto)
+	if lenStr != "" {
+		valor, err := strconv.ParseInt(lenStr, 0, 64)
+		if err != nil || valor <= 0 {
+			return "", errores.Nuevof(errores.ParametroInvalido, "[REP RAW]: -len inválido: '%s'", lenStr)
+		}
+		if valor > rawLenMaximo {
+			return "", errores.Nuevof(errores.ParametroInvalido, "[REP RAW]: -len no puede ser mayor a %d bytes", rawLenMaximo)
+		}
+		largo = valor
+	}
+
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP RAW]: %w", err)
+	}
+
+	diskPath := filepath.Join(m.DirectorioDiscos(), diskName)
+	file, err := m.AbrirDisco(diskPath, false)
+	if err != nil {
+		return "", errores.Nuevof(errores.NoEncontrado, "[REP RAW]: Disco no encontrado: %s", diskName)
+	}
+	defer file.Close()
+
+	tamanioDisco, err := file.Size()
+	if err != nil {
+		return "", errores.Nuevo(errores.Interno, "[REP RAW]: Error al leer el tamaño del disco")
+	}
+	if offset >= tamanioDisco {
+		return "", errores.Nuevof(errores.ParametroInvalido, "[REP RAW]: El offset %d está fuera del disco (%d bytes)", offset, tamanioDisco)
+	}
+	if offset+largo > tamanioDisco {
+		largo = tamanioDisco - offset
+	}
+
+	datos := make([]byte, largo)
+	if _, err := file.ReadAt(datos, offset); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP RAW]: Error al leer el disco: %w", err)
+	}
+
+	mbr, err := m.LeerMBR(diskPath)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP RAW]: %w", err)
+	}
+	campos := ubicarCamposRaw(m, file, diskPath, mbr, offset, offset+largo)
+
+	var contenido string
+	if formato == "txt" {
+		contenido = generarTxtRaw(diskName, offset, datos, campos)
+	} else {
+		contenido = generarHtmlRaw(diskName, offset, datos, campos)
+	}
+
+	if err := escribirReporte(m, rutaReporte, []byte(contenido), "raw", diskName); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP RAW]: Error al escribir: %w", err)
+	}
+
+	return fmt.Sprintf("[REP RAW]: Reporte generado en %s", rutaReporte), nil
+}
+
+// ubicarCamposRaw recorre las estructuras del disco y devuelve, ordenados por offset,
+// los campos que tocan el rango [inicio, fin)
+func ubicarCamposRaw(m *motor.Motor, file almacenamiento.Disco, diskPath string, mbr structures.MBR, inicio, fin int64) []campoRaw {
+	var campos []campoRaw
+	campos = append(campos, camposEstructura(file, "MBR", "mbr", 0, reflect.TypeOf(mbr), "", inicio, fin)...)
+
+	for _, part := range mbr.Mbr_partitions {
+		if part.Part_s <= 0 {
+			continue
+		}
+		if part.Part_type != 'E' {
+			campos = append(campos, camposSistemaArchivos(file, part.Part_start, utils.ConvertirByteAString(part.Part_name[:]), inicio, fin)...)
+			continue
+		}
+		for _, ebr := range leerEBRs(m, diskPath, part.Part_start) {
+			nombre := utils.ConvertirByteAString(ebr.Name[:])
+			inicioEBR := int64(ebr.Part_start - size.SizeEBR())
+			campos = append(campos, camposEstructura(file, "EBR "+nombre, "ebr", inicioEBR, reflect.TypeOf(ebr), "", inicio, fin)...)
+			campos = append(campos, camposSistemaArchivos(file, ebr.Part_start, nombre, inicio, fin)...)
+		}
+	}
+
+	sort.SliceStable(campos, func(i, j int) bool { return campos[i].Offset < campos[j].Offset })
+	return campos
+}
+
+// camposSistemaArchivos ubica el superbloque, los bitmaps, los inodos y los bloques usados
+// de una partición formateada
+func camposSistemaArchivos(file almacenamiento.Disco, inicioParticion int32, particion string, inicio, fin int64) []campoRaw {
+	sb, err := utils.LeerSuperBloque(file, inicioParticion)
+	if err != nil {
+		return nil
+	}
+	finParticion := int64(sb.S_block_start) + int64(sb.S_blocks_count)*int64(sb.S_block_s)
+	if int64(inicioParticion) >= fin || finParticion <= inicio {
+		return nil
+	}
+
+	var campos []campoRaw
+	campos = append(campos, camposEstructura(file, "SuperBloque "+particion, "sb", int64(inicioParticion), reflect.TypeOf(sb), "", inicio, fin)...)
+
+	campos = append(campos, camposBitmapRaw(file, &sb, "Bitmap inodos "+particion, "inodo", sb.S_bm_inode_start, sb.S_inodes_count, inicio, fin)...)
+	campos = append(campos, camposBitmapRaw(file, &sb, "Bitmap bloques "+particion, "bloque", sb.S_bm_block_start, sb.S_blocks_count, inicio, fin)...)
+
+	bitmapInodos, err := utils.LeerBitmapInodos(file, &sb)
+	if err != nil {
+		return campos
+	}
+	bitmapBloques, err := utils.LeerBitmapBloques(file, &sb)
+	if err != nil {
+		return campos
+	}
+
+	// El tipo de cada bloque lo decide el inodo que lo usa, así que se recorren todos
+	tipos := make(map[int32]string)
+	desde, hasta := rangoIndices(sb.S_inode_start, sb.S_inode_s, sb.S_inodes_count, inicio, fin)
+	for i := int32(0); i < sb.S_inodes_count; i++ {
+		if bitmapInodos[i] != '1' {
+			continue
+		}
+		pos := sb.S_inode_start + i*sb.S_inode_s
+		inodo, err := utils.LeerInodoPorPosicion(file, pos)
+		if err != nil {
+			continue
+		}
+		clasificarBloquesInodo(file, &inodo, tipos)
+		if i >= desde && i <= hasta {
+			campos = append(campos, camposEstructura(file, fmt.Sprintf("Inodo %d", i), "inodo", int64(pos), reflect.TypeOf(inodo), "", inicio, fin)...)
+		}
+	}
+
+	desde, hasta = rangoIndices(sb.S_block_start, sb.S_block_s, sb.S_blocks_count, inicio, fin)
+	for i := desde; i <= hasta; i++ {
+		if bitmapBloques[i] != '1' {
+			continue
+		}
+		pos := sb.S_block_start + i*sb.S_block_s
+		tipo, ok := tipos[pos]
+		if !ok {
+			tipo = "desconocido"
+		}
+		var estructura reflect.Type
+		switch tipo {
+		case "carpeta":
+			estructura = reflect.TypeOf(structures.BloqueCarpeta{})
+		case "apuntador":
+			estructura = reflect.TypeOf(structures.BloqueApuntador{})
+		default:
+			estructura = reflect.TypeOf(structures.BloqueArchivo{})
+		}
+		campos = append(campos, camposEstructura(file, fmt.Sprintf("Bloque %d (%s)", i, tipo), tipo, int64(pos), estructura, "", inicio, fin)...)
+	}
+
+	return campos
+}
+
+// rangoIndices devuelve el primer y último elemento de una tabla que tocan [inicio, fin).
+// Si ninguno la toca, hasta queda menor que desde.
+func rangoIndices(base int32, tam int32, cantidad int32, inicio, fin int64) (int32, int32) {
+	primero := (inicio - int64(base)) / int64(tam)
+	if inicio < int64(base) {
+		primero = 0
+	}
+	ultimo := (fin - 1 - int64(base)) / int64(tam)
+	if fin-1 < int64(base) {
+		return 0, -1
+	}
+	if ultimo >= int64(cantidad) {
+		ultimo = int64(cantidad) - 1
+	}
+	return int32(primero), int32(ultimo)
+}
+
+// camposBitmapRaw etiqueta cada byte de un bitmap con el elemento (ASCII) o los ocho
+// elementos (empaquetado) que representa
+func camposBitmapRaw(file almacenamiento.Disco, sb *structures.SuperBloque, estructura, elemento string, offset, cantidad int32, inicio, fin int64) []campoRaw {
+	tipoByte := reflect.TypeOf(byte(0))
+	empaquetado := utils.BitmapsEmpaquetados(sb)
+
+	var campos []campoRaw
+	desde, hasta := rangoIndices(offset, 1, utils.TamanioBitmap(sb, cantidad), inicio, fin)
+	for i := desde; i <= hasta; i++ {
+		nombre := fmt.Sprintf("%s %d", elemento, i)
+		if empaquetado {
+			nombre = fmt.Sprintf("%ss %d-%d", elemento, i*8, min(i*8+7, cantidad-1))
+		}
+		campos = append(campos, campoHoja(file, estructura, "bitmap", nombre, int64(offset+i), 1, tipoByte, inicio, fin)...)
+	}
+	return campos
+}
+
+// camposEstructura calcula el offset de cada campo de t tal como lo escribe binary.Write
+// (sin relleno). Los arreglos de estructuras o de enteros se separan por elemento; los de
+// bytes quedan como un solo campo.
+func camposEstructura(file almacenamiento.Disco, estructura, clase string, offset int64, t reflect.Type, prefijo string, inicio, fin int64) []campoRaw {
+	var campos []campoRaw
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tam := int64(binary.Size(reflect.Zero(f.Type).Interface()))
+		nombre := prefijo + f.Name
+
+		if offset < fin && offset+tam > inicio {
+			switch {
+			case f.Type.Kind() == reflect.Struct:
+				campos = append(campos, camposEstructura(file, estructura, clase, offset, f.Type, nombre+".", inicio, fin)...)
+			case f.Type.Kind() == reflect.Array && f.Type.Elem().Kind() != reflect.Uint8:
+				elem := f.Type.Elem()
+				tamElem := tam / int64(f.Type.Len())
+				for j := 0; j < f.Type.Len(); j++ {
+					nombreElem := fmt.Sprintf("%s[%d]", nombre, j)
+					offsetElem := offset + int64(j)*tamElem
+					if elem.Kind() == reflect.Struct {
+						campos = append(campos, camposEstructura(file, estructura, clase, offsetElem, elem, nombreElem+".", inicio, fin)...)
+					} else {
+						campos = append(campos, campoHoja(file, estructura, clase, nombreElem, offsetElem, tamElem, elem, inicio, fin)...)
+					}
+				}
+			default:
+				campos = append(campos, campoHoja(file, estructura, clase, nombre, offset, tam, f.Type, inicio, fin)...)
+			}
+		}
+		offset += tam
+	}
+	return campos
+}
+
+// campoHoja arma el campo con su valor decodificado si toca el rango pedido
+func campoHoja(file almacenamiento.Disco, estructura, clase, nombre string, offset, tam int64, t reflect.Type, inicio, fin int64) []campoRaw {
+	if offset >= fin || offset+tam <= inicio {
+		return nil
+	}
+	buf := make([]byte, tam)
+	valor := "(ilegible)"
+	if _, err := file.ReadAt(buf, offset); err == nil {
+		valor = valorCampoRaw(buf, t)
+	}
+	return []campoRaw{{
+		Estructura: estructura,
+		Clase:      clase,
+		Campo:      nombre,
+		Offset:     offset,
+		Tam:        tam,
+		Valor:      valor,
+	}}
+}
+
+func valorCampoRaw(buf []byte, t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int32:
+		return fmt.Sprintf("%d", int32(binary.LittleEndian.Uint32(buf)))
+	case reflect.Int8:
+		return fmt.Sprintf("%d", int8(buf[0]))
+	case reflect.Uint8:
+		if buf[0] >= 32 && buf[0] < 127 {
+			return fmt.Sprintf("'%c'", buf[0])
+		}
+		return fmt.Sprintf("%d", buf[0])
+	}
+
+	texto := strings.TrimRight(string(buf), "\x00")
+	for _, c := range []byte(texto) {
+		if (c < 32 || c >= 127) && c != '\n' {
+			return "0x" + hex.EncodeToString(buf)
+		}
+	}
+	return strconv.Quote(texto)
+}
+
+// mapaCamposRaw indica para cada byte del volcado el índice del campo que lo contiene (-1 si ninguno)
+func mapaCamposRaw(offset int64, largo int, campos []campoRaw) []int {
+	mapa := make([]int, largo)
+	for i := range mapa {
+		mapa[i] = -1
+	}
+	for idx, c := range campos {
+		for b := c.Offset; b < c.Offset+c.Tam; b++ {
+			if b >= offset && b < offset+int64(largo) {
+				mapa[b-offset] = idx
+			}
+		}
+	}
+	return mapa
+}
+
+// camposEnFila devuelve los nombres de los campos que empiezan en la fila
+// (o que vienen de antes si la fila es la primera del volcado)
+func camposEnFila(mapa []int, desde, hasta int, campos []campoRaw) []string {
+	var nombres []string
+	anterior := -1
+	if desde > 0 {
+		anterior = mapa[desde-1]
+	}
+	for i := desde; i < hasta; i++ {
+		if mapa[i] != -1 && mapa[i] != anterior {
+			nombres = append(nombres, campos[mapa[i]].Campo)
+		}
+		anterior = mapa[i]
+	}
+	return nombres
+}
+
+func caracterRaw(b byte) string {
+	if b >= 32 && b < 127 {
+		return string(b)
+	}
+	return "."
+}
+
+func generarHtmlRaw(diskName string, offset int64, datos []byte, campos []campoRaw) string {
+	var sb strings.Builder
+	mapa := mapaCamposRaw(offset, len(datos), campos)
+
+	sb.WriteString(`<!DOCTYPE html>
+<html lang="es">
+<head>
+    <meta charset="UTF-8">
+    <title>Reporte RAW</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 20px; background: #f9f9f9; }
+        h2 { color: #264653; text-align: center; }
+        table { border-collapse: collapse; margin-bottom: 20px; }
+        th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+        th { background-color: #264653; color: white; }
+        .volcado td { font-family: monospace; white-space: pre; border: none; padding: 1px 8px; }
+        .volcado .off { color: #888; }
+        .volcado .etiquetas { font-family: Arial, sans-serif; font-size: 12px; color: #555; }
+        .volcado span { cursor: help; }
+        .alt { filter: brightness(0.88); }
+        .leyenda span { display: inline-block; padding: 2px 8px; margin: 2px; border: 1px solid #ccc; }
+        .campos td.num { font-family: monospace; text-align: right; }
+`)
+	for _, c := range coloresRaw {
+		sb.WriteString(fmt.Sprintf("        .c-%s { background-color: %s; }\n", c.clase, c.color))
+	}
+	sb.WriteString(fmt.Sprintf(`    </style>
+</head>
+<body>
+    <h2>REPORTE RAW — %s [%d, %d)</h2>
+    <p class="leyenda">`, html.EscapeString(diskName), offset, offset+int64(len(datos))))
+	for _, c := range coloresRaw {
+		sb.WriteString(fmt.Sprintf(`<span class="c-%s">%s</span>`, c.clase, c.nombre))
+	}
+	sb.WriteString(`</p>
+
+    <table class="volcado">`)
+
+	for fila := 0; fila < len(datos); fila += rawBytesPorFila {
+		hasta := fila + rawBytesPorFila
+		if hasta > len(datos) {
+			hasta = len(datos)
+		}
+
+		var hexa, ascii strings.Builder
+		for i := fila; i < hasta; i++ {
+			if i > fila {
+				hexa.WriteByte(' ')
+			}
+			apertura := ""
+			cierre := ""
+			if idx := mapa[i]; idx != -1 {
+				c := campos[idx]
+				clase := "c-" + c.Clase
+				if idx%2 == 1 {
+					clase += " alt"
+				}
+				apertura = fmt.Sprintf(`<span class="%s" title="%s">`, clase,
+					html.EscapeString(fmt.Sprintf("%s · %s = %s (offset %d, %d bytes)", c.Estructura, c.Campo, c.Valor, c.Offset, c.Tam)))
+				cierre = "</span>"
+			}
+			hexa.WriteString(fmt.Sprintf("%s%02x%s", apertura, datos[i], cierre))
+			ascii.WriteString(apertura + html.EscapeString(caracterRaw(datos[i])) + cierre)
+		}
+
+		sb.WriteString(fmt.Sprintf(`
+        <tr><td class="off">0x%08x</td><td>%s</td><td>%s</td><td class="etiquetas">%s</td></tr>`,
+			offset+int64(fila), hexa.String(), ascii.String(),
+			html.EscapeString(strings.Join(camposEnFila(mapa, fila, hasta, campos), ", "))))
+	}
+
+	sb.WriteString(`
+    </table>
+
+    <table class="campos">
+        <tr>
+            <th>Offset</th>
+            <th>Hex</th>
+            <th>Tamaño</th>
+            <th>Estructura</th>
+            <th>Campo</th>
+            <th>Valor</th>
+        </tr>`)
+	for idx, c := range campos {
+		clase := "c-" + c.Clase
+		if idx%2 == 1 {
+			clase += " alt"
+		}
+		sb.WriteString(fmt.Sprintf(`
+        <tr class="%s">
+            <td class="num">%d</td>
+            <td class="num">0x%x</td>
+            <td class="num">%d</td>
+            <td>%s</td>
+            <td>%s</td>
+            <td>%s</td>
+        </tr>`, clase, c.Offset, c.Offset, c.Tam, html.EscapeString(c.Estructura), html.EscapeString(c.Campo), html.EscapeString(c.Valor)))
+	}
+	if len(campos) == 0 {
+		sb.WriteString(`
+        <tr><td colspan="6" style="text-align: center;">(ninguna estructura conocida en el rango)</td></tr>`)
+	}
+
+	sb.WriteString(`
+    </table>
+    <p style="text-align: center; margin-top: 30px; color: #666;">
+        Reporte RAW generado el ` + utils.IntFechaToStr(utils.ObFechaInt()) + `
+    </p>
+</body>
+</html>`)
+
+	return sb.String()
+}
+
+func generarTxtRaw(diskName string, offset int64, datos []byte, campos []campoRaw) string {
+	var txt strings.Builder
+	mapa := mapaCamposRaw(offset, len(datos), campos)
+
+	txt.WriteString("===========================================================\n")
+	txt.WriteString("                      REPORTE RAW\n")
+	txt.WriteString("===========================================================\n")
+	txt.WriteString(fmt.Sprintf("  Disco:          %s\n", diskName))
+	txt.WriteString(fmt.Sprintf("  Rango:          [%d, %d)\n", offset, offset+int64(len(datos))))
+	txt.WriteString("-----------------------------------------------------------\n")
+
+	for fila := 0; fila < len(datos); fila += rawBytesPorFila {
+		hasta := fila + rawBytesPorFila
+		if hasta > len(datos) {
+			hasta = len(datos)
+		}
+
+		var hexa, ascii strings.Builder
+		for i := fila; i < fila+rawBytesPorFila; i++ {
+			if i < hasta {
+				hexa.WriteString(fmt.Sprintf("%02x ", datos[i]))
+				ascii.WriteString(caracterRaw(datos[i]))
+			} else {
+				hexa.WriteString("   ")
+			}
+		}
+
+		linea := fmt.Sprintf("0x%08x  %s |%-16s|", offset+int64(fila), hexa.String(), ascii.String())
+		if nombres := camposEnFila(mapa, fila, hasta, campos); len(nombres) > 0 {
+			linea += "  " + strings.Join(nombres, ", ")
+		}
+		txt.WriteString(linea + "\n")
+	}
+
+	txt.WriteString("-----------------------------------------------------------\n")
+	txt.WriteString(fmt.Sprintf("%-10s %-6s %-28s %-34s %s\n", "OFFSET", "TAM", "ESTRUCTURA", "CAMPO", "VALOR"))
+	for _, c := range campos {
+		txt.WriteString(fmt.Sprintf("%-10d %-6d %-28s %-34s %s\n", c.Offset, c.Tam, c.Estructura, c.Campo, c.Valor))
+	}
+	if len(campos) == 0 {
+		txt.WriteString("(ninguna estructura conocida en el rango)\n")
+	}
+
+	return txt.String()
+}
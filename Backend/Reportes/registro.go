@@ -0,0 +1,74 @@
+package Reportes
+
+import (
+	"Proyecto/comandos/motor"
+	"Proyecto/comandos/utils"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// archivoIndiceReportes guarda de qué partición y tipo salió cada reporte
+const archivoIndiceReportes = ".indice.json"
+
+// InfoReporte son los metadatos de un reporte generado
+type InfoReporte struct {
+	Tipo     string `json:"tipo"`
+	ID       string `json:"id"`
+	Generado string `json:"generado"`
+}
+
+var bloqueoIndice sync.Mutex
+
+// escribirReporte crea la carpeta del reporte, guarda el archivo y lo registra en el índice
+func escribirReporte(m *motor.Motor, ruta string, contenido []byte, tipo string, id string) error {
+	if err := os.MkdirAll(filepath.Dir(ruta), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(ruta, contenido, 0644); err != nil {
+		return err
+	}
+	registrarReporte(m.DirectorioReportes(), ruta, tipo, id)
+	return nil
+}
+
+func registrarReporte(directorio string, ruta string, tipo string, id string) {
+	nombre, err := filepath.Rel(directorio, ruta)
+	if err != nil {
+		nombre = filepath.Base(ruta)
+	}
+
+	bloqueoIndice.Lock()
+	defer bloqueoIndice.Unlock()
+
+	indice := leerIndice(directorio)
+	indice[filepath.ToSlash(nombre)] = InfoReporte{
+		Tipo:     tipo,
+		ID:       id,
+		Generado: time.Now().Format(time.RFC3339),
+	}
+
+	if datos, err := json.MarshalIndent(indice, "", "  "); err == nil {
+		os.WriteFile(filepath.Join(directorio, archivoIndiceReportes), datos, 0644)
+	}
+}
+
+// LeerIndiceReportes devuelve los metadatos registrados por nombre de archivo en la
+// carpeta de reportes del servidor
+func LeerIndiceReportes() map[string]InfoReporte {
+	bloqueoIndice.Lock()
+	defer bloqueoIndice.Unlock()
+	return leerIndice(utils.DirectorioReportes)
+}
+
+func leerIndice(directorio string) map[string]InfoReporte {
+	indice := make(map[string]InfoReporte)
+	datos, err := os.ReadFile(filepath.Join(directorio, archivoIndiceReportes))
+	if err != nil {
+		return indice
+	}
+	json.Unmarshal(datos, &indice)
+	return indice
+}
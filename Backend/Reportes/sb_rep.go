@@ -2,60 +2,53 @@ package Reportes
 
 import (
 	"Proyecto/Estructuras/structures"
-	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
 	"Proyecto/comandos/utils"
 	"fmt"
-	"os"
 	"strings"
 )
 
 // GenerarReporteSB genera el reporte del SuperBloque en formato .html
-func generarReporteSB(id string, path string) (string, bool) {
+func generarReporteSB(m *motor.Motor, id string, path string, formato string) (string, error) {
 	// 1. Obtener la partición montada por ID
-	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	particionMontada, err := m.ParticionMontadaPorID(id)
 	if err != nil {
-		return fmt.Sprintf("[REP SB]: Partición con ID '%s' no encontrada o no montada", id), true
+		return "", errores.Nuevof(errores.NoMontada, "[REP SB]: Partición con ID '%s' no encontrada o no montada", id)
 	}
 
 	// 2. Abrir el archivo del disco
-	file, errOpen := os.OpenFile(particionMontada.DiskPath, os.O_RDONLY, 0666)
+	file, errOpen := m.AbrirDisco(particionMontada.DiskPath, false)
 	if errOpen != nil {
-		return "[REP SB]: Error al abrir el disco", true
+		return "", errores.Nuevo(errores.Interno, "[REP SB]: Error al abrir el disco")
 	}
 	defer file.Close()
 
 	// 3. Leer el MBR y el SuperBloque
-	mbr, er, strError := utils.ObtenerEstructuraMBR(particionMontada.DiskPath)
-	if er {
-		return strError, er
+	mbr, err := m.LeerMBR(particionMontada.DiskPath)
+	if err != nil {
+		return "", err
 	}
 
 	sb, errSB := utils.LeerSuperBloque(file, mbr.Mbr_partitions[0].Part_start)
 	if errSB != nil {
-		return "[REP SB]: Error al leer SuperBloque", true
+		return "", errores.Nuevo(errores.Interno, "[REP SB]: Error al leer SuperBloque")
 	}
 
 	// 4. Generar el contenido HTML
 	htmlContent := generarHtmlSB(sb)
 
-	// 5. Escribir el archivo .html en la carpeta Rep
-	repDir := "VDIC-MIA/Rep"
-	if _, err := os.Stat(repDir); os.IsNotExist(err) {
-		os.MkdirAll(repDir, 0777)
-	}
-
-	// Construir la ruta completa para el archivo .html
-	finalFileName := strings.TrimSuffix(path, ".png") + ".html"
-	if !strings.HasSuffix(finalFileName, ".html") {
-		finalFileName += ".html"
+	// 5. Escribir el archivo .html en la carpeta de reportes
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP SB]: %w", err)
 	}
-	htmlFilePath := repDir + "/" + finalFileName
 
-	if err := os.WriteFile(htmlFilePath, []byte(htmlContent), 0644); err != nil {
-		return fmt.Sprintf("[REP SB]: Error al escribir archivo HTML: %v", err), true
+	if err := escribirReporte(m, rutaReporte, []byte(htmlContent), "sb", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP SB]: Error al escribir archivo HTML: %w", err)
 	}
 
-	return fmt.Sprintf("[REP SB]: Reporte SB HTML generado exitosamente en %s", htmlFilePath), false
+	return fmt.Sprintf("[REP SB]: Reporte SB HTML generado exitosamente en %s", rutaReporte), nil
 }
 
 // generarHtmlSB crea el contenido HTML para el reporte del SuperBloque
@@ -87,8 +80,8 @@ func generarHtmlSB(sb structures.SuperBloque) string {
 
 	// Agregar cada campo del SuperBloque REAL
 	sbBuilder.WriteString(fmt.Sprintf(`
-        <tr><td>S_filesistem_type</td><td>%d</td></tr>
-`, sb.S_filesistem_type))
+        <tr><td>S_filesistem_type</td><td>%d (tipo %d, formato v%d)</td></tr>
+`, sb.S_filesistem_type, utils.TipoSistemaArchivos(&sb), utils.VersionFormato(&sb)))
 	sbBuilder.WriteString(fmt.Sprintf(`
         <tr><td>S_inodes_count</td><td>%d</td></tr>
 `, sb.S_inodes_count))
@@ -0,0 +1,374 @@
+package Reportes
+
+import (
+	"Proyecto/Estructuras/size"
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/motor"
+	"Proyecto/comandos/utils"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Medidas del lienzo SVG (en píxeles)
+const (
+	svgMargen       = 20.0
+	svgAnchoDisco   = 1000.0
+	svgAltoBarra    = 80.0
+	svgAnchoNodo    = 190.0
+	svgSeparacionX  = 70.0
+	svgSeparacionY  = 16.0
+	svgAltoLinea    = 14.0
+	svgCaracteres   = 26
+	svgLineasBloque = 4
+)
+
+// coloresSegmento usa los mismos colores que el reporte HTML del disco
+var coloresSegmento = map[string]string{
+	"mbr":       "#e9d8fd",
+	"primaria":  "#d9f2d9",
+	"extendida": "#fff2cc",
+	"logica":    "#ffecb3",
+	"ebr":       "#f8bbd0",
+	"libre":     "#cce5ff",
+}
+
+// segmentosExtendida divide la extendida en EBRs, lógicas y espacio libre
+func segmentosExtendida(m *motor.Motor, diskPath string, extendida Segmento, tamanoTotal int32) []Segmento {
+	var resultado []Segmento
+	posActual := extendida.Inicio
+	fin := extendida.Inicio + extendida.Tamaño
+
+	agregar := func(seg Segmento) {
+		seg.Porcentaje = (float64(seg.Tamaño) * 100.0) / float64(tamanoTotal)
+		resultado = append(resultado, seg)
+	}
+
+	for _, ebr := range leerEBRs(m, diskPath, extendida.Inicio) {
+		inicioEBR := ebr.Part_start - size.SizeEBR()
+		if inicioEBR > posActual {
+			agregar(Segmento{Nombre: "Libre", Tipo: "libre", Inicio: posActual, Tamaño: inicioEBR - posActual})
+		}
+		nombre := utils.ConvertirByteAString(ebr.Name[:])
+		if nombre == "" {
+			nombre = "SinNombre"
+		}
+		agregar(Segmento{Nombre: "EBR", Tipo: "ebr", Inicio: inicioEBR, Tamaño: size.SizeEBR()})
+		agregar(Segmento{Nombre: nombre, Tipo: "logica", Inicio: ebr.Part_start, Tamaño: ebr.Part_s})
+		posActual = ebr.Part_start + ebr.Part_s
+	}
+
+	if posActual < fin {
+		agregar(Segmento{Nombre: "Libre", Tipo: "libre", Inicio: posActual, Tamaño: fin - posActual})
+	}
+	return resultado
+}
+
+// generarSvgDisk dibuja el disco como una barra proporcional; la extendida se subdivide debajo
+func generarSvgDisk(m *motor.Motor, segmentos []Segmento, diskPath, diskName string, tamanoTotal int32) string {
+	escala := svgAnchoDisco / float64(tamanoTotal)
+	yBarra := svgMargen + 30
+	yExtendida := yBarra + svgAltoBarra + 10
+
+	// Detalle de las extendidas para la segunda fila y para la tabla
+	internos := make(map[int][]Segmento)
+	for i, seg := range segmentos {
+		if seg.Tipo == "extendida" {
+			internos[i] = segmentosExtendida(m, diskPath, seg, tamanoTotal)
+		}
+	}
+
+	filas := 0
+	for i := range segmentos {
+		filas += 1 + len(internos[i])
+	}
+	yTabla := yExtendida + svgAltoBarra/2 + 40
+	alto := yTabla + float64(filas+1)*18 + 60 + svgMargen
+	ancho := svgAnchoDisco + 2*svgMargen
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Arial, sans-serif">
+<rect width="100%%" height="100%%" fill="#f9f9f9"/>
+<text x="%.0f" y="%.0f" font-size="18" font-weight="bold" fill="#6a2c70" text-anchor="middle">REPORTE DE DISCO — %s</text>
+`, ancho, alto, ancho, alto, ancho/2, svgMargen+10, html.EscapeString(diskName)))
+
+	dibujar := func(seg Segmento, y float64, altoRect float64) {
+		x := svgMargen + float64(seg.Inicio)*escala
+		w := float64(seg.Tamaño) * escala
+		if w < 1 {
+			w = 1 // los EBR miden 30 bytes, se dejan visibles con un píxel
+		}
+		sb.WriteString(fmt.Sprintf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s" stroke="#6a2c70" stroke-width="0.5"><title>%s (%d bytes, %.2f%%)</title></rect>
+`, x, y, w, altoRect, coloresSegmento[seg.Tipo], html.EscapeString(seg.Nombre), seg.Tamaño, seg.Porcentaje))
+
+		// Solo se rotula si el texto cabe en el segmento
+		etiqueta := seg.Nombre
+		if w >= float64(len(etiqueta))*7+6 {
+			sb.WriteString(fmt.Sprintf(`<text x="%.2f" y="%.2f" font-size="12" text-anchor="middle">%s</text>
+`, x+w/2, y+altoRect/2-2, html.EscapeString(etiqueta)))
+			if w >= 60 {
+				sb.WriteString(fmt.Sprintf(`<text x="%.2f" y="%.2f" font-size="10" fill="#555" text-anchor="middle">%.2f%%</text>
+`, x+w/2, y+altoRect/2+12, seg.Porcentaje))
+			}
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf(`<rect x="%.0f" y="%.0f" width="%.0f" height="%.0f" fill="none" stroke="#6a2c70" stroke-width="2"/>
+`, svgMargen, yBarra, svgAnchoDisco, svgAltoBarra))
+	for i, seg := range segmentos {
+		if seg.Tamaño <= 0 {
+			continue
+		}
+		dibujar(seg, yBarra, svgAltoBarra)
+		// Los EBR van al final para que la lógica siguiente no los tape
+		for _, interno := range internos[i] {
+			if interno.Tipo != "ebr" {
+				dibujar(interno, yExtendida, svgAltoBarra/2)
+			}
+		}
+		for _, interno := range internos[i] {
+			if interno.Tipo == "ebr" {
+				dibujar(interno, yExtendida, svgAltoBarra/2)
+			}
+		}
+	}
+
+	// Tabla resumen con el mismo contenido que el HTML
+	columnas := []float64{svgMargen, svgMargen + 260, svgMargen + 400, svgMargen + 560, svgMargen + 720}
+	encabezados := []string{"Nombre", "Tipo", "Inicio (bytes)", "Tamaño (bytes)", "Porcentaje"}
+	for c, texto := range encabezados {
+		sb.WriteString(fmt.Sprintf(`<text x="%.0f" y="%.0f" font-size="12" font-weight="bold" fill="#6a2c70">%s</text>
+`, columnas[c], yTabla, texto))
+	}
+
+	y := yTabla
+	fila := func(seg Segmento, sangria string) {
+		y += 18
+		valores := []string{
+			sangria + seg.Nombre,
+			nombresTipoSegmento[seg.Tipo],
+			fmt.Sprintf("%d", seg.Inicio),
+			fmt.Sprintf("%d", seg.Tamaño),
+			fmt.Sprintf("%.2f%%", seg.Porcentaje),
+		}
+		for c, texto := range valores {
+			sb.WriteString(fmt.Sprintf(`<text x="%.0f" y="%.0f" font-size="12" xml:space="preserve">%s</text>
+`, columnas[c], y, html.EscapeString(texto)))
+		}
+	}
+	for i, seg := range segmentos {
+		fila(seg, "")
+		for _, interno := range internos[i] {
+			fila(interno, "  └ ")
+		}
+	}
+
+	// Leyenda
+	y += 36
+	x := svgMargen
+	for _, tipo := range []string{"mbr", "primaria", "extendida", "ebr", "logica", "libre"} {
+		sb.WriteString(fmt.Sprintf(`<rect x="%.0f" y="%.0f" width="16" height="16" fill="%s" stroke="#666"/><text x="%.0f" y="%.0f" font-size="12">%s</text>
+`, x, y-12, coloresSegmento[tipo], x+22, y, nombresTipoSegmento[tipo]))
+		x += 130
+	}
+
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+var nombresTipoSegmento = map[string]string{
+	"mbr":       "MBR",
+	"primaria":  "Primaria",
+	"extendida": "Extendida",
+	"ebr":       "EBR",
+	"logica":    "Lógica",
+	"libre":     "Libre",
+}
+
+// nodoSvg es un inodo o bloque del árbol con su posición calculada
+type nodoSvg struct {
+	clase  string // "inodo", "carpeta" o "archivo"
+	lineas []string
+	hijos  []hijoSvg
+	x, y   float64
+}
+
+// hijoSvg guarda desde qué línea del padre sale la arista
+type hijoSvg struct {
+	nodo  *nodoSvg
+	linea int
+}
+
+var coloresNodo = map[string]string{
+	"inodo":   "#cce5ff",
+	"carpeta": "#ffcccc",
+	"archivo": "#ffffcc",
+}
+
+func (n *nodoSvg) alto() float64 {
+	return float64(len(n.lineas))*svgAltoLinea + 8
+}
+
+func recortarSvg(texto string) string {
+	runas := []rune(texto)
+	if len(runas) > svgCaracteres {
+		return string(runas[:svgCaracteres-1]) + "…"
+	}
+	return texto
+}
+
+// construirArbolSvg arma el árbol de inodos y bloques desde la raíz usando posiciones absolutas
+func construirArbolSvg(file almacenamiento.Disco, sb *structures.SuperBloque) *nodoSvg {
+	visitados := make(map[int32]bool)
+
+	var procesarInodo func(posInodo int32) *nodoSvg
+	procesarInodo = func(posInodo int32) *nodoSvg {
+		if visitados[posInodo] {
+			return nil // evita ciclos en carpetas dañadas
+		}
+		visitados[posInodo] = true
+
+		inodo, err := utils.LeerInodoPorPosicion(file, posInodo)
+		if err != nil {
+			return nil
+		}
+
+		tipo := "Archivo"
+		if inodo.I_type[0] == '0' {
+			tipo = "Carpeta"
+		}
+		nodo := &nodoSvg{clase: "inodo", lineas: []string{
+			fmt.Sprintf("Inodo %d (%s)", indiceInodo(sb, posInodo), tipo),
+			fmt.Sprintf("uid: %d  gid: %d  perm: %s", inodo.I_uid, inodo.I_gid, string(inodo.I_perm[:])),
+			fmt.Sprintf("i_s: %d", inodo.I_s),
+		}}
+
+		for i := 0; i < 12; i++ {
+			if inodo.I_block[i] == -1 {
+				continue
+			}
+			numBloque := indiceBloque(sb, inodo.I_block[i])
+			nodo.lineas = append(nodo.lineas, fmt.Sprintf("i_block[%d]: %d", i, numBloque))
+			linea := len(nodo.lineas) - 1
+
+			if inodo.I_type[0] != '0' {
+				bloque, err := leerBloqueArchivo(file, inodo.I_block[i])
+				if err != nil {
+					continue
+				}
+				hijo := &nodoSvg{clase: "archivo", lineas: []string{fmt.Sprintf("Bloque archivo %d", numBloque)}}
+				contenido := strings.TrimRight(string(bloque.B_content[:]), "\x00\n")
+				for k, texto := range strings.Split(contenido, "\n") {
+					if k == svgLineasBloque {
+						hijo.lineas = append(hijo.lineas, "…")
+						break
+					}
+					hijo.lineas = append(hijo.lineas, recortarSvg(texto))
+				}
+				nodo.hijos = append(nodo.hijos, hijoSvg{nodo: hijo, linea: linea})
+				continue
+			}
+
+			bloque, err := leerBloqueCarpeta(file, inodo.I_block[i])
+			if err != nil {
+				continue
+			}
+			hijo := &nodoSvg{clase: "carpeta", lineas: []string{fmt.Sprintf("Bloque carpeta %d", numBloque)}}
+			for _, entrada := range bloque.B_content {
+				nombre := utils.ConvertirByteAString(entrada.B_name[:])
+				if entrada.B_inodo == -1 {
+					hijo.lineas = append(hijo.lineas, "— : -1")
+					continue
+				}
+				hijo.lineas = append(hijo.lineas, fmt.Sprintf("%s : %d", recortarSvg(nombre), indiceInodo(sb, entrada.B_inodo)))
+				if nombre == "" || nombre == "." || nombre == ".." {
+					continue
+				}
+				if nieto := procesarInodo(entrada.B_inodo); nieto != nil {
+					hijo.hijos = append(hijo.hijos, hijoSvg{nodo: nieto, linea: len(hijo.lineas) - 1})
+				}
+			}
+			nodo.hijos = append(nodo.hijos, hijoSvg{nodo: hijo, linea: linea})
+		}
+		return nodo
+	}
+
+	return procesarInodo(sb.S_inode_start)
+}
+
+// ubicarNodo asigna columnas por profundidad y centra cada padre respecto a sus hijos.
+// Devuelve el alto que ocupa el subárbol y la profundidad máxima alcanzada.
+func ubicarNodo(n *nodoSvg, profundidad int, y0 float64) (float64, int) {
+	n.x = svgMargen + float64(profundidad)*(svgAnchoNodo+svgSeparacionX)
+	if len(n.hijos) == 0 {
+		n.y = y0
+		return n.alto(), profundidad
+	}
+
+	cursor := y0
+	maxProfundidad := profundidad
+	for _, hijo := range n.hijos {
+		h, p := ubicarNodo(hijo.nodo, profundidad+1, cursor)
+		cursor += h + svgSeparacionY
+		if p > maxProfundidad {
+			maxProfundidad = p
+		}
+	}
+	total := cursor - svgSeparacionY - y0
+
+	if total >= n.alto() {
+		n.y = y0 + (total-n.alto())/2
+		return total, maxProfundidad
+	}
+	n.y = y0
+	return n.alto(), maxProfundidad
+}
+
+func dibujarNodoSvg(sb *strings.Builder, n *nodoSvg) {
+	for _, hijo := range n.hijos {
+		// La arista sale de la línea del apuntador y llega a la mitad del hijo
+		x1 := n.x + svgAnchoNodo
+		y1 := n.y + 4 + (float64(hijo.linea)+0.5)*svgAltoLinea
+		x2 := hijo.nodo.x
+		y2 := hijo.nodo.y + hijo.nodo.alto()/2
+		medio := (x1 + x2) / 2
+		sb.WriteString(fmt.Sprintf(`<path d="M %.1f %.1f C %.1f %.1f, %.1f %.1f, %.1f %.1f" fill="none" stroke="#999" stroke-width="1.2" marker-end="url(#flecha)"/>
+`, x1, y1, medio, y1, medio, y2, x2, y2))
+		dibujarNodoSvg(sb, hijo.nodo)
+	}
+
+	sb.WriteString(fmt.Sprintf(`<rect x="%.1f" y="%.1f" width="%.0f" height="%.1f" rx="6" fill="%s" stroke="#666" stroke-width="1.5"/>
+`, n.x, n.y, svgAnchoNodo, n.alto(), coloresNodo[n.clase]))
+	for i, linea := range n.lineas {
+		peso := "normal"
+		if i == 0 {
+			peso = "bold"
+		}
+		sb.WriteString(fmt.Sprintf(`<text x="%.1f" y="%.1f" font-size="11" font-weight="%s" xml:space="preserve">%s</text>
+`, n.x+6, n.y+4+float64(i+1)*svgAltoLinea-3, peso, html.EscapeString(linea)))
+	}
+}
+
+// generarSvgTree dibuja el árbol de inodos y bloques sin depender de Graphviz
+func generarSvgTree(file almacenamiento.Disco, sb structures.SuperBloque) string {
+	raiz := construirArbolSvg(file, &sb)
+	if raiz == nil {
+		return `<svg xmlns="http://www.w3.org/2000/svg" width="400" height="60"><text x="20" y="35" font-family="Arial">No se pudo leer el inodo raíz</text></svg>` + "\n"
+	}
+
+	yInicio := svgMargen + 30
+	altoArbol, profundidad := ubicarNodo(raiz, 0, yInicio)
+	ancho := 2*svgMargen + float64(profundidad+1)*(svgAnchoNodo+svgSeparacionX) - svgSeparacionX
+	alto := yInicio + altoArbol + svgMargen
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Arial, sans-serif">
+<defs><marker id="flecha" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="7" markerHeight="7" orient="auto"><path d="M 0 0 L 10 5 L 0 10 z" fill="#999"/></marker></defs>
+<rect width="100%%" height="100%%" fill="#f9f9f9"/>
+<text x="%.0f" y="%.0f" font-size="18" font-weight="bold" fill="#6a2c70">ÁRBOL DEL SISTEMA DE ARCHIVOS</text>
+`, ancho, alto, ancho, alto, svgMargen, svgMargen+10))
+	dibujarNodoSvg(&out, raiz)
+	out.WriteString("</svg>\n")
+	return out.String()
+}
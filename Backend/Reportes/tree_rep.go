@@ -2,54 +2,55 @@ package Reportes
 
 import (
 	"Proyecto/Estructuras/structures"
-	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/motor"
 	"Proyecto/comandos/utils"
-	"encoding/binary"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 )
 
-func generarReporteTree(id string, path string) (string, bool) {
-	repDir := "VDIC-MIA/Rep"
-	if _, err := os.Stat(repDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(repDir, 0755); err != nil {
-			return fmt.Sprintf("[REP TREE]: Error al crear %s: %v", repDir, err), true
-		}
+func generarReporteTree(m *motor.Motor, id string, path string, formato string) (string, error) {
+	rutaReporte, err := resolverRutaReporte(m, path, formato)
+	if err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP TREE]: %w", err)
 	}
 
-	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	particionMontada, err := m.ParticionMontadaPorID(id)
 	if err != nil {
-		return fmt.Sprintf("[REP TREE]: Partición con ID '%s' no montada", id), true
+		return "", errores.Nuevof(errores.NoMontada, "[REP TREE]: Partición con ID '%s' no montada", id)
 	}
 
-	file, err := os.Open(particionMontada.DiskPath)
+	file, err := m.AbrirDiscoConCache(particionMontada.DiskPath, false)
 	if err != nil {
-		return "[REP TREE]: Error al abrir disco", true
+		return "", errores.Nuevo(errores.Interno, "[REP TREE]: Error al abrir disco")
 	}
 	defer file.Close()
 
 	inicioParticion := particionMontada.Partition.Part_start
 	sb, err := utils.LeerSuperBloque(file, inicioParticion)
 	if err != nil {
-		return "[REP TREE]: Error al leer superbloque", true
+		return "", errores.Nuevo(errores.Interno, "[REP TREE]: Error al leer superbloque")
 	}
 
-	htmlContent := generarHtmlTreeVisual(file, sb)
-
-	baseName := filepath.Base(path)
-	htmlFileName := strings.TrimSuffix(baseName, filepath.Ext(baseName)) + ".html"
-	htmlFilePath := filepath.Join(repDir, htmlFileName)
+	var contenido string
+	switch formato {
+	case "dot":
+		contenido = generarDotTree(file, sb)
+	case "svg":
+		contenido = generarSvgTree(file, sb)
+	default:
+		contenido = generarHtmlTreeVisual(file, sb)
+	}
 
-	if err := os.WriteFile(htmlFilePath, []byte(htmlContent), 0644); err != nil {
-		return fmt.Sprintf("[REP TREE]: Error al escribir: %v", err), true
+	if err := escribirReporte(m, rutaReporte, []byte(contenido), "tree", id); err != nil {
+		return "", errores.Nuevof(errores.Interno, "[REP TREE]: Error al escribir: %w", err)
 	}
 
-	return fmt.Sprintf("[REP TREE]: Reporte generado en %s", htmlFilePath), false
+	return fmt.Sprintf("[REP TREE]: Reporte generado en %s", rutaReporte), nil
 }
 
-func generarHtmlTreeVisual(file *os.File, sb structures.SuperBloque) string {
+func generarHtmlTreeVisual(file almacenamiento.Disco, sb structures.SuperBloque) string {
 	var sbBuilder strings.Builder
 
 	sbBuilder.WriteString(`<!DOCTYPE html>
@@ -107,10 +108,7 @@ func generarHtmlTreeVisual(file *os.File, sb structures.SuperBloque) string {
 	procesarInodo = func(numInodo int32, profundidad int) {
 		posInodo := sb.S_inode_start + numInodo*92
 		var inodo structures.TablaInodo
-		if _, err := file.Seek(int64(posInodo), 0); err != nil {
-			return
-		}
-		if err := binary.Read(file, binary.LittleEndian, &inodo); err != nil {
+		if err := utils.LeerEstructura(file, posInodo, &inodo); err != nil {
 			return
 		}
 
@@ -129,10 +127,7 @@ func generarHtmlTreeVisual(file *os.File, sb structures.SuperBloque) string {
 				if inodo.I_block[i] != -1 {
 					posBloque := sb.S_block_start + (inodo.I_block[i] * 64)
 					var bloque structures.BloqueCarpeta
-					if _, err := file.Seek(int64(posBloque), 0); err != nil {
-						continue
-					}
-					if err := binary.Read(file, binary.LittleEndian, &bloque); err != nil {
+					if err := utils.LeerEstructura(file, posBloque, &bloque); err != nil {
 						continue
 					}
 
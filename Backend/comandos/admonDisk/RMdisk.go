@@ -1,46 +1,47 @@
 package admonDisk
 
 import (
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
 	"Proyecto/comandos/utils"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/fatih/color"
 )
 
-func RmdiskExecute(comando string, props map[string]string) (string, bool) {
+func RmdiskExecute(comando string, props map[string]string) (string, interface{}, error) {
 	diskName := props["diskname"]
 
 	if diskName == "" {
 		msg := "[RMDISK ERROR]: Parámetro 'diskname' vacío"
 		color.Red(msg)
-		return msg, true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, msg)
 	}
 
 	if !strings.HasSuffix(strings.ToLower(diskName), ".mia") {
 		msg := "[RMDISK ERROR]: El disco debe tener extensión .mia"
 		color.Red(msg)
-		return msg, true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, msg)
 	}
 
 	path := utils.DirectorioDisco + diskName
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	if !almacenamiento.Existe(path) {
 		msg := fmt.Sprintf("[RMDISK ERROR]: Disco no encontrado: %s", diskName)
 		color.Red(msg)
-		return msg, true
+		return "", nil, errores.Nuevo(errores.NoEncontrado, msg)
 	}
 
-	if err := os.Remove(path); err != nil {
+	if err := almacenamiento.Eliminar(path); err != nil {
 		msg := fmt.Sprintf("[RMDISK ERROR]: No se pudo eliminar '%s': %v", diskName, err)
 		color.Red(msg)
-		return msg, true
+		return "", nil, errores.Nuevo(errores.Interno, msg)
 	}
 
 	msg := fmt.Sprintf("[RMDISK]: Disco '%s' eliminado correctamente", diskName)
 	color.Green("===========================================================")
 	color.Green("%s", msg)
 	color.Green("===========================================================")
-	return msg, false
+	return msg, map[string]interface{}{"disco": diskName}, nil
 }
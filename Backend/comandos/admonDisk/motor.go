@@ -0,0 +1,47 @@
+package admonDisk
+
+import (
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/motor"
+	"Proyecto/comandos/utils"
+)
+
+// ParticionMontada representa una partición montada en el sistema
+type ParticionMontada = motor.ParticionMontada
+
+// MotorComandos devuelve un motor sobre las carpetas y el backend del servidor con una
+// copia de la sesión indicada (nil para los comandos que no la usan). Cada llamada da un
+// motor independiente: lo que cambie su sesión no toca la del que lo pidió.
+func MotorComandos(sesion *global.SesionUsuario) *motor.Motor {
+	m := motor.Nuevo(opcionesServidor())
+	m.UsarSesion(sesionMotor(sesion))
+	return m
+}
+
+// opcionesServidor devuelve las opciones del motor con la configuración del servidor.
+// Sin generador de reportes: rep llama a Reportes.Generar con este mismo motor.
+func opcionesServidor() motor.Opciones {
+	return motor.Opciones{
+		Discos:         utils.DirectorioDisco,
+		Reportes:       utils.DirectorioReportes,
+		Almacenamiento: almacenamiento.Actual(),
+	}
+}
+
+func sesionMotor(s *global.SesionUsuario) *motor.Sesion {
+	if s == nil {
+		return nil
+	}
+	sesion := &motor.Sesion{
+		Usuario: s.UsuarioActual,
+		UID:     s.UID,
+		GID:     s.GID,
+		ID:      s.IDParticion,
+		Disco:   s.PathDisco,
+	}
+	if s.Particion != nil {
+		sesion.Particion = *s.Particion
+	}
+	return sesion
+}
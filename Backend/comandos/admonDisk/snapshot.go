@@ -0,0 +1,452 @@
+package admonDisk
+
+import (
+	"Proyecto/Estructuras/size"
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/utils"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ParticionSnapshot resume una partición del MBR guardado en el snapshot
+type ParticionSnapshot struct {
+	Nombre string `json:"nombre"`
+	Tipo   string `json:"tipo"`
+	Inicio int32  `json:"inicio"`
+	Tamano int32  `json:"tamano"`
+	Estado int8   `json:"estado"`
+	ID     string `json:"id"`
+}
+
+// MetadataSnapshot se guarda junto a la copia del disco (<nombre>.json)
+type MetadataSnapshot struct {
+	Nombre      string              `json:"nombre"`
+	Disco       string              `json:"disco"`
+	Fecha       int32               `json:"fecha"`
+	MbrTamano   int32               `json:"mbr_tamano"`
+	MbrFirma    int32               `json:"mbr_firma"`
+	MbrFit      string              `json:"mbr_fit"`
+	Particiones []ParticionSnapshot `json:"particiones"`
+	Montadas    []string            `json:"montadas"`
+}
+
+// SnapshotExecute maneja el comando snapshot (-diskname/-name o -list)
+func SnapshotExecute(comando string, parametros map[string]string) (string, interface{}, error) {
+	if strings.TrimSpace(parametros["list"]) != "" {
+		return listarSnapshots(strings.TrimSpace(parametros["diskname"]))
+	}
+
+	diskName, er, strError := utils.TieneDiskName(strings.TrimSpace(parametros["diskname"]))
+	if er {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[SNAPSHOT ERROR]: "+strError)
+	}
+
+	nombre, er, strError := utils.TieneName(strings.TrimSpace(parametros["name"]))
+	if er {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[SNAPSHOT ERROR]: "+strError)
+	}
+
+	pathDisco, err := MotorComandos(nil).RutaDisco(diskName)
+	if err != nil {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[SNAPSHOT ERROR]: "+err.Error())
+	}
+
+	return crearSnapshot(pathDisco, nombre)
+}
+
+// RestoreExecute maneja el comando restore (-diskname, -name y opcional -partition)
+func RestoreExecute(comando string, parametros map[string]string) (string, interface{}, error) {
+	diskName, er, strError := utils.TieneDiskName(strings.TrimSpace(parametros["diskname"]))
+	if er {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[RESTORE ERROR]: "+strError)
+	}
+
+	nombre, er, strError := utils.TieneName(strings.TrimSpace(parametros["name"]))
+	if er {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[RESTORE ERROR]: "+strError)
+	}
+
+	pathDisco, err := MotorComandos(nil).RutaDisco(diskName)
+	if err != nil {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[RESTORE ERROR]: "+err.Error())
+	}
+
+	particion := strings.TrimSpace(parametros["partition"])
+	if particion != "" {
+		return restaurarParticion(pathDisco, nombre, particion)
+	}
+	return restaurarDisco(pathDisco, nombre)
+}
+
+func crearSnapshot(pathDisco string, nombre string) (string, interface{}, error) {
+	diskName := filepath.Base(pathDisco)
+	if !nombreSnapshotValido(nombre) {
+		msg := fmt.Sprintf("[SNAPSHOT ERROR]: Nombre de snapshot inválido: '%s'", nombre)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, msg)
+	}
+
+	if !utils.ExisteArchivo("SNAPSHOT", pathDisco) {
+		msg := fmt.Sprintf("[SNAPSHOT ERROR]: Disco no encontrado: %s", diskName)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.NoEncontrado, msg)
+	}
+
+	// La copia y su metadata se guardan con el backend de almacenamiento, igual que los discos
+	dirSnapshots := directorioSnapshotsDisco(diskName)
+	pathCopia := filepath.Join(dirSnapshots, nombre+".mia")
+	if almacenamiento.Existe(pathCopia) {
+		msg := fmt.Sprintf("[SNAPSHOT ERROR]: Ya existe el snapshot '%s' para %s", nombre, diskName)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.YaExiste, msg)
+	}
+
+	mbr, er, strError := utils.ObtenerEstructuraMBR(pathDisco)
+	if er {
+		return "", nil, errores.Nuevo(errores.Interno, "[SNAPSHOT ERROR]: "+strError)
+	}
+
+	meta, err := construirMetadataSnapshot(nombre, pathDisco, mbr)
+	if err != nil {
+		msg := fmt.Sprintf("[SNAPSHOT ERROR]: No se pudieron leer las particiones lógicas: %v", err)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.Interno, msg)
+	}
+
+	if err := almacenamiento.Copiar(pathDisco, pathCopia); err != nil {
+		almacenamiento.Eliminar(pathCopia)
+		msg := fmt.Sprintf("[SNAPSHOT ERROR]: No se pudo copiar el disco: %v", err)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.Interno, msg)
+	}
+	contenido, _ := json.MarshalIndent(meta, "", "  ")
+	if err := almacenamiento.EscribirTodo(filepath.Join(dirSnapshots, nombre+".json"), contenido); err != nil {
+		almacenamiento.Eliminar(pathCopia)
+		msg := fmt.Sprintf("[SNAPSHOT ERROR]: No se pudo guardar la metadata: %v", err)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.Interno, msg)
+	}
+
+	detalles := fmt.Sprintf(`  Snapshot:       %s
+  Disco:          %s
+  Fecha:          %s
+  Particiones:    %d
+  Montadas:       %s`,
+		nombre, diskName, utils.IntFechaToStr(meta.Fecha), len(meta.Particiones), strings.Join(meta.Montadas, ", "))
+
+	salida := utils.SuccessBanner("SNAPSHOT CREADO EXITOSAMENTE", detalles)
+	color.Green(salida)
+	return salida, map[string]interface{}{"snapshot": nombre, "disco": diskName}, nil
+}
+
+func listarSnapshots(diskName string) (string, interface{}, error) {
+	patron := filepath.Join(utils.DirectorioSnapshots, "*", "*.json")
+	if diskName != "" {
+		pathDisco, err := MotorComandos(nil).RutaDisco(diskName)
+		if err != nil {
+			return "", nil, errores.Nuevo(errores.ParametroInvalido, "[SNAPSHOT ERROR]: "+err.Error())
+		}
+		patron = filepath.Join(directorioSnapshotsDisco(filepath.Base(pathDisco)), "*.json")
+	}
+
+	archivos, err := almacenamiento.Listar(patron)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[SNAPSHOT ERROR]: Error al listar snapshots: %w", err)
+	}
+
+	var metas []MetadataSnapshot
+	for _, archivo := range archivos {
+		meta, err := leerMetadataSnapshot(archivo)
+		if err != nil {
+			continue
+		}
+		metas = append(metas, meta)
+	}
+
+	if len(metas) == 0 {
+		return "[SNAPSHOT]: No hay snapshots guardados", nil, nil
+	}
+
+	sort.Slice(metas, func(i, j int) bool {
+		if metas[i].Disco != metas[j].Disco {
+			return metas[i].Disco < metas[j].Disco
+		}
+		return metas[i].Fecha < metas[j].Fecha
+	})
+
+	var salida strings.Builder
+	salida.WriteString("===========================================================\n")
+	salida.WriteString("                 SNAPSHOTS DISPONIBLES\n")
+	salida.WriteString("===========================================================\n")
+	salida.WriteString(fmt.Sprintf("%-14s %-16s %-24s %-12s\n", "DISCO", "SNAPSHOT", "FECHA", "MONTADAS"))
+	salida.WriteString("-----------------------------------------------------------\n")
+	for _, meta := range metas {
+		salida.WriteString(fmt.Sprintf("%-14s %-16s %-24s %-12s\n",
+			meta.Disco, meta.Nombre, utils.IntFechaToStr(meta.Fecha), strings.Join(meta.Montadas, ",")))
+	}
+	salida.WriteString("-----------------------------------------------------------\n")
+	salida.WriteString(fmt.Sprintf("Total de snapshots: %d\n", len(metas)))
+	salida.WriteString("===========================================================")
+
+	color.Cyan(salida.String())
+	return salida.String(), nil, nil
+}
+
+func restaurarDisco(pathDisco string, nombre string) (string, interface{}, error) {
+	diskName := filepath.Base(pathDisco)
+	pathCopia, meta, err := buscarSnapshot(pathDisco, nombre)
+	if err != nil {
+		color.Red(err.Error())
+		return "", nil, err
+	}
+
+	if sesion := global.SesionActiva; sesion != nil && filepath.Clean(sesion.PathDisco) == filepath.Clean(pathDisco) {
+		msg := fmt.Sprintf("[RESTORE ERROR]: Hay una sesión activa de '%s' en %s. Use LOGOUT primero", sesion.UsuarioActual, diskName)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.SinPermiso, msg)
+	}
+
+	if err := almacenamiento.Copiar(pathCopia, pathDisco); err != nil {
+		msg := fmt.Sprintf("[RESTORE ERROR]: No se pudo restaurar el disco: %v", err)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.Interno, msg)
+	}
+
+	detalles := fmt.Sprintf(`  Snapshot:       %s
+  Disco:          %s
+  Fecha snapshot: %s
+  Montadas:       %s`,
+		nombre, diskName, utils.IntFechaToStr(meta.Fecha), strings.Join(meta.Montadas, ", "))
+
+	salida := utils.SuccessBanner("DISCO RESTAURADO EXITOSAMENTE", detalles)
+	color.Green(salida)
+	return salida, nil, nil
+}
+
+// restaurarParticion copia únicamente el rango de bytes de una partición desde el snapshot.
+// Las lógicas se buscan en la cadena de EBR; su EBR no se copia para no cambiar el enlace.
+func restaurarParticion(pathDisco string, nombre string, nombreParticion string) (string, interface{}, error) {
+	diskName := filepath.Base(pathDisco)
+	pathCopia, meta, err := buscarSnapshot(pathDisco, nombre)
+	if err != nil {
+		color.Red(err.Error())
+		return "", nil, err
+	}
+
+	var origen *ParticionSnapshot
+	for i := range meta.Particiones {
+		if meta.Particiones[i].Nombre == nombreParticion {
+			origen = &meta.Particiones[i]
+			break
+		}
+	}
+	if origen == nil {
+		msg := fmt.Sprintf("[RESTORE ERROR]: La partición '%s' no existe en el snapshot '%s'", nombreParticion, nombre)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.NoEncontrado, msg)
+	}
+
+	mbr, er, strError := utils.ObtenerEstructuraMBR(pathDisco)
+	if er {
+		return "", nil, errores.Nuevo(errores.Interno, "[RESTORE ERROR]: "+strError)
+	}
+
+	actuales, err := particionesDisco(pathDisco, mbr)
+	if err != nil {
+		msg := fmt.Sprintf("[RESTORE ERROR]: No se pudieron leer las particiones lógicas: %v", err)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.Interno, msg)
+	}
+
+	var actual *ParticionSnapshot
+	for i := range actuales {
+		if actuales[i].Nombre == nombreParticion {
+			actual = &actuales[i]
+			break
+		}
+	}
+	if actual == nil || actual.Tipo != origen.Tipo || actual.Inicio != origen.Inicio || actual.Tamano != origen.Tamano {
+		msg := fmt.Sprintf("[RESTORE ERROR]: La partición '%s' cambió de posición o tamaño desde el snapshot", nombreParticion)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, msg)
+	}
+
+	if sesion := global.SesionActiva; sesion != nil && filepath.Clean(sesion.PathDisco) == filepath.Clean(pathDisco) &&
+		sesion.Particion != nil && utils.ConvertirByteAString(sesion.Particion.Part_name[:]) == nombreParticion {
+		msg := fmt.Sprintf("[RESTORE ERROR]: La partición '%s' tiene una sesión activa de '%s'. Use LOGOUT primero", nombreParticion, sesion.UsuarioActual)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.SinPermiso, msg)
+	}
+
+	if err := copiarRangoDisco(pathCopia, pathDisco, int64(origen.Inicio), int64(origen.Tamano)); err != nil {
+		msg := fmt.Sprintf("[RESTORE ERROR]: No se pudo restaurar la partición: %v", err)
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.Interno, msg)
+	}
+
+	detalles := fmt.Sprintf(`  Snapshot:       %s
+  Disco:          %s
+  Partición:      %s
+  Inicio:         %d bytes
+  Tamaño:         %d bytes`,
+		nombre, diskName, nombreParticion, origen.Inicio, origen.Tamano)
+
+	salida := utils.SuccessBanner("PARTICIÓN RESTAURADA EXITOSAMENTE", detalles)
+	color.Green(salida)
+	return salida, nil, nil
+}
+
+func buscarSnapshot(pathDisco string, nombre string) (string, MetadataSnapshot, error) {
+	diskName := filepath.Base(pathDisco)
+	if !nombreSnapshotValido(nombre) {
+		return "", MetadataSnapshot{}, errores.Nuevof(errores.ParametroInvalido, "[RESTORE ERROR]: Nombre de snapshot inválido: '%s'", nombre)
+	}
+
+	if !utils.ExisteArchivo("RESTORE", pathDisco) {
+		return "", MetadataSnapshot{}, errores.Nuevof(errores.NoEncontrado, "[RESTORE ERROR]: Disco no encontrado: %s", diskName)
+	}
+
+	dirSnapshots := directorioSnapshotsDisco(diskName)
+	pathCopia := filepath.Join(dirSnapshots, nombre+".mia")
+	if !almacenamiento.Existe(pathCopia) {
+		return "", MetadataSnapshot{}, errores.Nuevof(errores.NoEncontrado, "[RESTORE ERROR]: No existe el snapshot '%s' para %s", nombre, diskName)
+	}
+
+	meta, err := leerMetadataSnapshot(filepath.Join(dirSnapshots, nombre+".json"))
+	if err != nil {
+		return "", MetadataSnapshot{}, errores.Nuevof(errores.Interno, "[RESTORE ERROR]: Metadata del snapshot '%s' dañada: %v", nombre, err)
+	}
+
+	return pathCopia, meta, nil
+}
+
+func construirMetadataSnapshot(nombre string, pathDisco string, mbr structures.MBR) (MetadataSnapshot, error) {
+	meta := MetadataSnapshot{
+		Nombre:    nombre,
+		Disco:     filepath.Base(pathDisco),
+		Fecha:     utils.ObFechaInt(),
+		MbrTamano: mbr.Mbr_tamano,
+		MbrFirma:  mbr.Mbr_disk_signature,
+		MbrFit:    string(mbr.Dsk_fit),
+		Montadas:  []string{},
+	}
+
+	particiones, err := particionesDisco(pathDisco, mbr)
+	if err != nil {
+		return meta, err
+	}
+	meta.Particiones = particiones
+
+	for _, p := range particiones {
+		if p.Estado == 1 && p.ID != "" {
+			meta.Montadas = append(meta.Montadas, p.ID)
+		}
+	}
+
+	return meta, nil
+}
+
+// particionesDisco resume las particiones del MBR y las lógicas de la cadena de EBR
+func particionesDisco(pathDisco string, mbr structures.MBR) ([]ParticionSnapshot, error) {
+	particiones := []ParticionSnapshot{}
+	var extendida *structures.Partition
+	for i, p := range mbr.Mbr_partitions {
+		if p.Part_s <= 0 {
+			continue
+		}
+		particiones = append(particiones, ParticionSnapshot{
+			Nombre: utils.ConvertirByteAString(p.Part_name[:]),
+			Tipo:   string(p.Part_type),
+			Inicio: p.Part_start,
+			Tamano: p.Part_s,
+			Estado: p.Part_status,
+			ID:     utils.ConvertirByteAString(p.Part_id[:]),
+		})
+		if p.Part_type == 'E' {
+			extendida = &mbr.Mbr_partitions[i]
+		}
+	}
+	if extendida == nil {
+		return particiones, nil
+	}
+
+	file, err := almacenamiento.Abrir(pathDisco, false)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Se limita el recorrido al número de EBR que caben en la extendida por si la cadena tiene un ciclo
+	var ebr structures.EBR
+	posicion := extendida.Part_start
+	for saltos := int32(0); posicion != -1 && saltos <= extendida.Part_s/size.SizeEBR(); saltos++ {
+		if err := utils.LeerEstructura(file, posicion, &ebr); err != nil {
+			return nil, err
+		}
+		if ebr.Part_s > 0 {
+			particiones = append(particiones, ParticionSnapshot{
+				Nombre: utils.ConvertirByteAString(ebr.Name[:]),
+				Tipo:   "L",
+				Inicio: ebr.Part_start,
+				Tamano: ebr.Part_s,
+				Estado: ebr.Part_mount,
+			})
+		}
+		posicion = ebr.Part_next
+	}
+
+	return particiones, nil
+}
+
+func leerMetadataSnapshot(path string) (MetadataSnapshot, error) {
+	var meta MetadataSnapshot
+	contenido, err := almacenamiento.LeerTodo(path)
+	if err != nil {
+		return meta, err
+	}
+	err = json.Unmarshal(contenido, &meta)
+	return meta, err
+}
+
+func directorioSnapshotsDisco(diskName string) string {
+	return filepath.Join(utils.DirectorioSnapshots, strings.TrimSuffix(diskName, filepath.Ext(diskName)))
+}
+
+func nombreSnapshotValido(nombre string) bool {
+	return nombre != "" && nombre != "." && nombre != ".." && !strings.ContainsAny(nombre, `/\`)
+}
+
+func copiarRangoDisco(origen string, destino string, inicio int64, tamanio int64) error {
+	entrada, err := almacenamiento.Abrir(origen, false)
+	if err != nil {
+		return err
+	}
+	defer entrada.Close()
+
+	salida, err := almacenamiento.Abrir(destino, true)
+	if err != nil {
+		return err
+	}
+	defer salida.Close()
+
+	buffer := make([]byte, 64*1024)
+	for hecho := int64(0); hecho < tamanio; {
+		n := min(int64(len(buffer)), tamanio-hecho)
+		if _, err := entrada.ReadAt(buffer[:n], inicio+hecho); err != nil {
+			return err
+		}
+		if _, err := salida.WriteAt(buffer[:n], inicio+hecho); err != nil {
+			return err
+		}
+		hecho += n
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+// admonFS/defrag.go
+package admonFS
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/utils"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// DefragExecute acomoda los bloques de cada inodo en bloques contiguos
+func DefragExecute(comando string, parametros map[string]string) (string, interface{}, error) {
+	id := strings.TrimSpace(parametros["id"])
+	if id == "" {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[DEFRAG]: Parámetro -id es obligatorio")
+	}
+
+	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.NoMontada, "[DEFRAG]: Partición con ID '%s' no encontrada o no montada", id)
+	}
+
+	disco, err := almacenamiento.Abrir(particionMontada.DiskPath, true)
+	if err != nil {
+		return "", nil, errores.Nuevo(errores.Interno, "[DEFRAG]: Error al abrir el disco")
+	}
+
+	// Las estructuras se leen y modifican en memoria; Close las escribe al disco
+	file := utils.NuevaCache(disco)
+	defer file.Close()
+
+	inicio := particionMontada.Partition.Part_start
+	sb, err := utils.LeerSuperBloque(file, inicio)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[DEFRAG]: Error al leer SuperBloque: %w", err)
+	}
+
+	return desfragmentar(file, &sb, inicio, id, particionMontada.PartName)
+}
+
+func desfragmentar(file *utils.CacheDisco, sb *structures.SuperBloque, inicioParticion int32, id string, nombrePart string) (string, interface{}, error) {
+	// Defrag no repara: con cualquier problema que fsck reportaría no se toca el disco,
+	// así un bloque huérfano no se libera en silencio al compactar
+	color.Cyan("→ Revisando el sistema de archivos...")
+	problemas, err := revisarSistemaArchivos(file, sb)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[DEFRAG]: %w", err)
+	}
+	if len(problemas) > 0 {
+		msg := fmt.Sprintf("[DEFRAG]: Sistema de archivos inconsistente (%d problemas, revise con fsck), no se modificó el disco: %s", len(problemas), problemas[0])
+		color.Red(msg)
+		return "", nil, errores.Nuevo(errores.Interno, msg)
+	}
+
+	color.Cyan("→ Analizando inodos y bloques...")
+	inodos, err := utils.InodosEnUso(file, sb)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[DEFRAG]: %w", err)
+	}
+
+	bitmap, err := utils.LeerBitmapBloques(file, sb)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[DEFRAG]: Error al leer bitmap de bloques: %w", err)
+	}
+
+	// Nuevo acomodo: los bloques de cada inodo uno tras otro, en orden de inodo
+	nuevaPos := make(map[int32]int32)
+	siguiente := sb.S_block_start
+	movidos := 0
+	for _, in := range inodos {
+		for _, b := range in.Bloques {
+			nuevaPos[b.Pos] = siguiente
+			if b.Pos != siguiente {
+				movidos++
+			}
+			siguiente += sb.S_block_s
+		}
+	}
+	usados := int32(len(nuevaPos))
+	antes := utils.PuntajeFragmentacion(sb, inodos)
+
+	if movidos == 0 {
+		salida := fmt.Sprintf("[DEFRAG]: La partición '%s' ya está desfragmentada", id)
+		color.Yellow(salida)
+		return salida, nil, nil
+	}
+
+	// Cada bloque se mueve siguiendo la cadena de destinos: antes de pisar un bloque que
+	// todavía no se movió se lee su contenido, así solo hay un par de bloques en memoria
+	// a la vez sin importar el tamaño de la partición
+	color.Cyan("→ Moviendo %d bloques...", movidos)
+	apuntadores := make(map[int32]bool)
+	for _, in := range inodos {
+		for _, b := range in.Bloques {
+			if b.Apuntador {
+				apuntadores[b.Pos] = true
+			}
+		}
+	}
+	leer := func(pos int32) ([]byte, error) {
+		buf := make([]byte, sb.S_block_s)
+		if err := utils.LeerBytes(file, int64(pos), buf); err != nil {
+			return nil, errores.Nuevof(errores.Interno, "[DEFRAG]: Error al leer bloque en %d: %w", pos, err)
+		}
+		if apuntadores[pos] {
+			for k := 0; k+4 <= len(buf); k += 4 {
+				p := int32(binary.LittleEndian.Uint32(buf[k:]))
+				if p != -1 {
+					binary.LittleEndian.PutUint32(buf[k:], uint32(nuevaPos[p]))
+				}
+			}
+		}
+		return buf, nil
+	}
+	escribir := func(pos int32, buf []byte) error {
+		if err := utils.EscribirBytes(file, int64(pos), buf); err != nil {
+			return errores.Nuevof(errores.Interno, "[DEFRAG]: Error al escribir bloque en %d: %w", pos, err)
+		}
+		return nil
+	}
+
+	movido := make(map[int32]bool, len(nuevaPos))
+	for _, in := range inodos {
+		for _, b := range in.Bloques {
+			if movido[b.Pos] {
+				continue
+			}
+			buf, err := leer(b.Pos)
+			if err != nil {
+				return "", nil, err
+			}
+			actual := b.Pos
+			for {
+				movido[actual] = true
+				destino := nuevaPos[actual]
+				var siguienteBuf []byte
+				if _, usado := nuevaPos[destino]; usado && !movido[destino] {
+					if siguienteBuf, err = leer(destino); err != nil {
+						return "", nil, err
+					}
+				}
+				if err := escribir(destino, buf); err != nil {
+					return "", nil, err
+				}
+				if siguienteBuf == nil {
+					break
+				}
+				buf, actual = siguienteBuf, destino
+			}
+		}
+	}
+
+	color.Cyan("→ Actualizando apuntadores de inodos...")
+	for _, in := range inodos {
+		for i, p := range in.Inodo.I_block {
+			if p != -1 {
+				in.Inodo.I_block[i] = nuevaPos[p]
+			}
+		}
+		if err := utils.EscribirInodo(file, in.Pos, &in.Inodo); err != nil {
+			return "", nil, errores.Nuevof(errores.Interno, "[DEFRAG]: %w", err)
+		}
+	}
+
+	// Los inodos no cambian de lugar: su bitmap solo se lee para actualizar S_first_ino
+	color.Cyan("→ Reescribiendo bitmap de bloques...")
+	bitmapInodos, err := utils.LeerBitmapInodos(file, sb)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[DEFRAG]: Error al leer bitmap de inodos: %w", err)
+	}
+	primerInodoLibre := int32(strings.IndexByte(string(bitmapInodos), '0'))
+
+	for i := range bitmap {
+		if int32(i) < usados {
+			bitmap[i] = '1'
+		} else {
+			bitmap[i] = '0'
+		}
+	}
+	if err := utils.EscribirBitmap(file, sb, sb.S_bm_block_start, bitmap, 0, sb.S_blocks_count); err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[DEFRAG]: Error al escribir bitmap de bloques: %w", err)
+	}
+
+	sb.S_free_blocks_count = sb.S_blocks_count - usados
+	sb.S_first_blo = usados
+	sb.S_first_ino = primerInodoLibre
+	if err := utils.EscribirSuperBloque(file, inicioParticion, sb); err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[DEFRAG]: %w", err)
+	}
+
+	inodosFinal, err := utils.InodosEnUso(file, sb)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[DEFRAG]: Error al verificar el resultado: %w", err)
+	}
+	despues := utils.PuntajeFragmentacion(sb, inodosFinal)
+
+	if err := file.Flush(); err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[DEFRAG]: Error al guardar los cambios en el disco: %w", err)
+	}
+
+	detalles := fmt.Sprintf(`  ID:                 %s
+  Partición:          %s
+  Inodos revisados:   %d
+  Bloques en uso:     %d
+  Bloques movidos:    %d
+  Fragmentación:      %.1f%% → %.1f%%`,
+		id, nombrePart, len(inodos), usados, movidos, antes, despues)
+
+	salida := utils.SuccessBanner("DESFRAGMENTACIÓN COMPLETADA", detalles)
+	color.Green(salida)
+	return salida, nil, nil
+}
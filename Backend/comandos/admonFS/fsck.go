@@ -0,0 +1,181 @@
+// admonFS/fsck.go
+package admonFS
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/utils"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// maxProblemasFsck limita cuántos problemas se listan en la salida
+const maxProblemasFsck = 20
+
+// FsckExecute revisa que los bitmaps y el SuperBloque coincidan con los inodos y bloques
+// que realmente se usan. Solo lee el disco.
+func FsckExecute(comando string, parametros map[string]string) (string, interface{}, error) {
+	id := strings.TrimSpace(parametros["id"])
+	if id == "" {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[FSCK]: Parámetro -id es obligatorio")
+	}
+
+	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.NoMontada, "[FSCK]: Partición con ID '%s' no encontrada o no montada", id)
+	}
+
+	disco, err := almacenamiento.Abrir(particionMontada.DiskPath, false)
+	if err != nil {
+		return "", nil, errores.Nuevo(errores.Interno, "[FSCK]: Error al abrir el disco")
+	}
+
+	file := utils.NuevaCache(disco)
+	defer file.Close()
+
+	sb, err := utils.LeerSuperBloque(file, particionMontada.Partition.Part_start)
+	if err != nil || sb.S_magic != 0xEF53 {
+		return "", nil, errores.Nuevof(errores.Interno, "[FSCK]: La partición '%s' no está formateada", id)
+	}
+
+	problemas, err := revisarSistemaArchivos(file, &sb)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[FSCK]: %w", err)
+	}
+
+	formato := "1 (bitmaps ASCII)"
+	if utils.BitmapsEmpaquetados(&sb) {
+		formato = "2 (bitmaps empaquetados)"
+	}
+	detalles := fmt.Sprintf(`  ID:                 %s
+  Partición:          %s
+  Versión formato:    %s
+  Problemas:          %d`,
+		id, particionMontada.PartName, formato, len(problemas))
+
+	if len(problemas) == 0 {
+		salida := utils.SuccessBanner("SISTEMA DE ARCHIVOS CONSISTENTE", detalles)
+		color.Green(salida)
+		return salida, nil, nil
+	}
+
+	listados := problemas
+	if len(listados) > maxProblemasFsck {
+		listados = listados[:maxProblemasFsck]
+	}
+	detalles += "\n  ------------------------------------------------------"
+	for _, p := range listados {
+		detalles += "\n  • " + p
+	}
+	if len(problemas) > len(listados) {
+		detalles += fmt.Sprintf("\n  ... y %d más", len(problemas)-len(listados))
+	}
+
+	// Encontrar problemas no es un éxito: el comando falla con la lista para que un script
+	// o la API lo detecten
+	msg := fmt.Sprintf("[FSCK]: La partición '%s' tiene %d problemas de consistencia\n%s", id, len(problemas), utils.SuccessBanner("SISTEMA DE ARCHIVOS CON ERRORES", detalles))
+	color.Red(msg)
+	return "", nil, errores.Nuevo(errores.Interno, msg)
+}
+
+// revisarSistemaArchivos compara los bitmaps con el árbol de carpetas y los bloques de cada inodo
+func revisarSistemaArchivos(file almacenamiento.Disco, sb *structures.SuperBloque) ([]string, error) {
+	bitmapInodos, err := utils.LeerBitmapInodos(file, sb)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer bitmap de inodos: %v", err)
+	}
+	bitmapBloques, err := utils.LeerBitmapBloques(file, sb)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer bitmap de bloques: %v", err)
+	}
+	inodos, err := utils.InodosEnUso(file, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	var problemas []string
+
+	// Inodos alcanzables desde la raíz que el bitmap marca como libres
+	rutas := utils.RutasInodos(file, sb)
+	posiciones := make([]int32, 0, len(rutas))
+	for pos := range rutas {
+		posiciones = append(posiciones, pos)
+	}
+	sort.Slice(posiciones, func(i, j int) bool { return posiciones[i] < posiciones[j] })
+	for _, pos := range posiciones {
+		ruta := rutas[pos]
+		indice := (pos - sb.S_inode_start) / sb.S_inode_s
+		if indice < 0 || indice >= sb.S_inodes_count || (pos-sb.S_inode_start)%sb.S_inode_s != 0 {
+			problemas = append(problemas, fmt.Sprintf("'%s' apunta a una posición de inodo inválida (%d)", ruta, pos))
+			continue
+		}
+		if bitmapInodos[indice] != '1' {
+			problemas = append(problemas, fmt.Sprintf("el inodo %d ('%s') está libre en el bitmap", indice, ruta))
+		}
+	}
+
+	finBloques := sb.S_block_start + sb.S_blocks_count*sb.S_block_s
+	referenciados := make(map[int32]int32)
+	for _, in := range inodos {
+		if in.Ruta == "" {
+			problemas = append(problemas, fmt.Sprintf("el inodo %d está usado pero ninguna carpeta lo enlaza", in.Numero))
+		}
+		for _, b := range in.Bloques {
+			if b.Pos < sb.S_block_start || b.Pos >= finBloques || (b.Pos-sb.S_block_start)%sb.S_block_s != 0 {
+				problemas = append(problemas, fmt.Sprintf("el inodo %d apunta a una posición de bloque inválida (%d)", in.Numero, b.Pos))
+				continue
+			}
+			indice := (b.Pos - sb.S_block_start) / sb.S_block_s
+			if otro, ok := referenciados[indice]; ok {
+				problemas = append(problemas, fmt.Sprintf("el bloque %d lo usan los inodos %d y %d", indice, otro, in.Numero))
+				continue
+			}
+			referenciados[indice] = in.Numero
+			if bitmapBloques[indice] != '1' {
+				problemas = append(problemas, fmt.Sprintf("el bloque %d del inodo %d está libre en el bitmap", indice, in.Numero))
+			}
+		}
+	}
+	for i, b := range bitmapBloques {
+		if _, ok := referenciados[int32(i)]; b == '1' && !ok {
+			problemas = append(problemas, fmt.Sprintf("el bloque %d está marcado como usado pero ningún inodo lo usa", i))
+		}
+	}
+
+	// Los contadores del SuperBloque deben coincidir con los bitmaps
+	inodosLibres := int32(strings.Count(string(bitmapInodos), "0"))
+	bloquesLibres := int32(strings.Count(string(bitmapBloques), "0"))
+	if sb.S_free_inodes_count != inodosLibres {
+		problemas = append(problemas, fmt.Sprintf("S_free_inodes_count es %d pero el bitmap tiene %d inodos libres", sb.S_free_inodes_count, inodosLibres))
+	}
+	if sb.S_free_blocks_count != bloquesLibres {
+		problemas = append(problemas, fmt.Sprintf("S_free_blocks_count es %d pero el bitmap tiene %d bloques libres", sb.S_free_blocks_count, bloquesLibres))
+	}
+
+	if utils.BitmapsEmpaquetados(sb) {
+		problemas = append(problemas, revisarRellenoBitmap(file, sb, "inodos", sb.S_bm_inode_start, sb.S_inodes_count)...)
+		problemas = append(problemas, revisarRellenoBitmap(file, sb, "bloques", sb.S_bm_block_start, sb.S_blocks_count)...)
+	}
+	return problemas, nil
+}
+
+// revisarRellenoBitmap verifica que los bits sobrantes del último byte de un bitmap
+// empaquetado estén en cero
+func revisarRellenoBitmap(file almacenamiento.Disco, sb *structures.SuperBloque, nombre string, inicio, cantidad int32) []string {
+	if cantidad%8 == 0 {
+		return nil
+	}
+	ultimo := make([]byte, 1)
+	if err := utils.LeerBytes(file, int64(inicio+utils.TamanioBitmap(sb, cantidad)-1), ultimo); err != nil {
+		return []string{fmt.Sprintf("no se pudo leer el último byte del bitmap de %s", nombre)}
+	}
+	if ultimo[0]>>(cantidad%8) != 0 {
+		return []string{fmt.Sprintf("el bitmap de %s tiene bits de relleno encendidos (0x%02X)", nombre, ultimo[0])}
+	}
+	return nil
+}
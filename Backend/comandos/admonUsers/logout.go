@@ -1,22 +1,19 @@
 package admonUsers
 
 import (
+	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/errores"
 	"Proyecto/comandos/global"
 	"Proyecto/comandos/utils"
 	"fmt"
-
-	"github.com/fatih/color"
 )
 
 // LogoutExecute maneja el comando logout
-func LogoutExecute(comando string, parametros map[string]string) (string, bool) {
-	// Verificar que haya sesión activa
-	if global.SesionActiva == nil {
-		return "[LOGOUT]: No hay sesión activa", true
+func LogoutExecute(comando string, parametros map[string]string) (string, interface{}, error) {
+	usuarioSaliente, err := admonDisk.MotorComandos(global.SesionActiva).Logout()
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[LOGOUT]: %w", err)
 	}
-
-	usuarioSaliente := global.SesionActiva.UsuarioActual
-
 	global.SesionActiva = nil
 
 	salida := utils.SuccessBanner(
@@ -24,12 +21,5 @@ func LogoutExecute(comando string, parametros map[string]string) (string, bool)
 		fmt.Sprintf("  Usuario:        %s\n  La sesión ha sido cerrada correctamente", usuarioSaliente),
 	)
 
-	color.Green("═══════════════════════════════════════════════════════════")
-	color.Green("SESIÓN CERRADA EXITOSAMENTE")
-	color.Green("═══════════════════════════════════════════════════════════")
-	color.Cyan("  Usuario:        %s", usuarioSaliente)
-	color.Yellow("  La sesión ha sido cerrada correctamente")
-	color.Green("═══════════════════════════════════════════════════════════")
-
-	return salida, false
+	return salida, map[string]interface{}{"usuario": usuarioSaliente}, nil
 }
@@ -0,0 +1,151 @@
+// Package almacenamiento abstrae dónde viven los discos virtuales (.mia). Todos los
+// subsistemas abren los discos por ruta a través de este paquete, así que cambiar el
+// backend (archivos del host o memoria) no requiere tocar los comandos.
+package almacenamiento
+
+import (
+	"io"
+	"sync"
+)
+
+// Disco es un disco virtual abierto
+type Disco interface {
+	io.ReaderAt
+	io.WriterAt
+	Size() (int64, error)
+	Sync() error
+	Close() error
+}
+
+// Backend crea, abre y borra discos identificados por su ruta
+type Backend interface {
+	// Crear crea (o vacía) el disco con tamanio bytes en cero
+	Crear(ruta string, tamanio int64) (Disco, error)
+	// Abrir abre un disco existente; escritura indica si se va a modificar
+	Abrir(ruta string, escritura bool) (Disco, error)
+	Existe(ruta string) bool
+	Eliminar(ruta string) error
+	// Listar devuelve, ordenadas, las rutas de los discos que coinciden con el patrón
+	// (misma sintaxis que filepath.Match)
+	Listar(patron string) ([]string, error)
+}
+
+var (
+	backend        Backend = Archivos{}
+	bloqueoBackend sync.RWMutex
+)
+
+// Usar cambia el backend para todas las aperturas siguientes
+func Usar(b Backend) {
+	bloqueoBackend.Lock()
+	backend = b
+	bloqueoBackend.Unlock()
+}
+
+// Actual devuelve el backend en uso
+func Actual() Backend {
+	bloqueoBackend.RLock()
+	defer bloqueoBackend.RUnlock()
+	return backend
+}
+
+// Crear crea el disco en el backend actual
+func Crear(ruta string, tamanio int64) (Disco, error) {
+	return Actual().Crear(ruta, tamanio)
+}
+
+// Abrir abre el disco en el backend actual
+func Abrir(ruta string, escritura bool) (Disco, error) {
+	return Actual().Abrir(ruta, escritura)
+}
+
+// Existe indica si el disco existe en el backend actual
+func Existe(ruta string) bool {
+	return Actual().Existe(ruta)
+}
+
+// Eliminar borra el disco del backend actual
+func Eliminar(ruta string) error {
+	return Actual().Eliminar(ruta)
+}
+
+// Listar devuelve los discos del backend actual que coinciden con el patrón
+func Listar(patron string) ([]string, error) {
+	return Actual().Listar(patron)
+}
+
+// EscribirTodo guarda datos como el contenido completo de ruta en el backend actual.
+// Sirve para archivos pequeños que acompañan a los discos, como la metadata de un snapshot.
+func EscribirTodo(ruta string, datos []byte) error {
+	salida, err := Crear(ruta, int64(len(datos)))
+	if err != nil {
+		return err
+	}
+	if _, err := salida.WriteAt(datos, 0); err != nil {
+		salida.Close()
+		return err
+	}
+	if err := salida.Sync(); err != nil {
+		salida.Close()
+		return err
+	}
+	return salida.Close()
+}
+
+// LeerTodo devuelve el contenido completo de ruta en el backend actual
+func LeerTodo(ruta string) ([]byte, error) {
+	entrada, err := Abrir(ruta, false)
+	if err != nil {
+		return nil, err
+	}
+	defer entrada.Close()
+
+	tamanio, err := entrada.Size()
+	if err != nil {
+		return nil, err
+	}
+	datos := make([]byte, tamanio)
+	if _, err := entrada.ReadAt(datos, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return datos, nil
+}
+
+// Copiar copia el contenido completo de un disco en otro, creando o vaciando el destino
+func Copiar(origen string, destino string) error {
+	entrada, err := Abrir(origen, false)
+	if err != nil {
+		return err
+	}
+	defer entrada.Close()
+
+	tamanio, err := entrada.Size()
+	if err != nil {
+		return err
+	}
+	salida, err := Crear(destino, tamanio)
+	if err != nil {
+		return err
+	}
+
+	buffer := make([]byte, 64*1024)
+	for pos := int64(0); pos < tamanio; pos += int64(len(buffer)) {
+		n := int64(len(buffer))
+		if tamanio-pos < n {
+			n = tamanio - pos
+		}
+		if _, err := entrada.ReadAt(buffer[:n], pos); err != nil {
+			salida.Close()
+			return err
+		}
+		if _, err := salida.WriteAt(buffer[:n], pos); err != nil {
+			salida.Close()
+			return err
+		}
+	}
+	if err := salida.Sync(); err != nil {
+		salida.Close()
+		return err
+	}
+	return salida.Close()
+}
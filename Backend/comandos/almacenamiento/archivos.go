@@ -0,0 +1,67 @@
+package almacenamiento
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Archivos guarda cada disco como un archivo del host (el comportamiento de siempre)
+type Archivos struct{}
+
+// discoArchivo es un disco respaldado por un archivo del host
+type discoArchivo struct {
+	file *os.File
+}
+
+func (d *discoArchivo) ReadAt(p []byte, off int64) (int, error)  { return d.file.ReadAt(p, off) }
+func (d *discoArchivo) WriteAt(p []byte, off int64) (int, error) { return d.file.WriteAt(p, off) }
+func (d *discoArchivo) Sync() error                              { return d.file.Sync() }
+func (d *discoArchivo) Close() error                             { return d.file.Close() }
+
+func (d *discoArchivo) Size() (int64, error) {
+	info, err := d.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (Archivos) Crear(ruta string, tamanio int64) (Disco, error) {
+	if err := os.MkdirAll(filepath.Dir(ruta), 0777); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(ruta)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Truncate(tamanio); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &discoArchivo{file: file}, nil
+}
+
+func (Archivos) Abrir(ruta string, escritura bool) (Disco, error) {
+	modo := os.O_RDONLY
+	if escritura {
+		modo = os.O_RDWR
+	}
+	file, err := os.OpenFile(ruta, modo, 0666)
+	if err != nil {
+		return nil, err
+	}
+	return &discoArchivo{file: file}, nil
+}
+
+func (Archivos) Existe(ruta string) bool {
+	_, err := os.Stat(ruta)
+	return err == nil
+}
+
+func (Archivos) Eliminar(ruta string) error {
+	return os.Remove(ruta)
+}
+
+func (Archivos) Listar(patron string) ([]string, error) {
+	return filepath.Glob(patron)
+}
@@ -0,0 +1,167 @@
+package almacenamiento
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ErrDiscoCerrado se devuelve al usar un disco en memoria después de cerrarlo
+var ErrDiscoCerrado = errors.New("disco cerrado")
+
+// Memoria guarda los discos en memoria; nada se escribe en el host. Los datos viven
+// mientras viva el backend.
+type Memoria struct {
+	bloqueo sync.Mutex
+	discos  map[string]*datosMemoria
+}
+
+// datosMemoria es el contenido de un disco, compartido por todas sus aperturas
+type datosMemoria struct {
+	bloqueo sync.RWMutex
+	datos   []byte
+}
+
+// discoMemoria es una apertura de un disco en memoria
+type discoMemoria struct {
+	datos     *datosMemoria
+	escritura bool
+	cerrado   bool
+}
+
+// NuevaMemoria crea un backend en memoria vacío
+func NuevaMemoria() *Memoria {
+	return &Memoria{discos: make(map[string]*datosMemoria)}
+}
+
+func claveMemoria(ruta string) string {
+	return filepath.Clean(ruta)
+}
+
+// Crear vacía el disco si ya existe en lugar de reemplazarlo, igual que os.Create: las
+// aperturas que siguen abiertas ven el contenido nuevo
+func (m *Memoria) Crear(ruta string, tamanio int64) (Disco, error) {
+	m.bloqueo.Lock()
+	defer m.bloqueo.Unlock()
+	clave := claveMemoria(ruta)
+	d, ok := m.discos[clave]
+	if !ok {
+		d = &datosMemoria{}
+		m.discos[clave] = d
+	}
+	d.bloqueo.Lock()
+	d.datos = make([]byte, tamanio)
+	d.bloqueo.Unlock()
+	return &discoMemoria{datos: d, escritura: true}, nil
+}
+
+func (m *Memoria) Abrir(ruta string, escritura bool) (Disco, error) {
+	m.bloqueo.Lock()
+	defer m.bloqueo.Unlock()
+	d, ok := m.discos[claveMemoria(ruta)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: ruta, Err: fs.ErrNotExist}
+	}
+	return &discoMemoria{datos: d, escritura: escritura}, nil
+}
+
+func (m *Memoria) Existe(ruta string) bool {
+	m.bloqueo.Lock()
+	defer m.bloqueo.Unlock()
+	_, ok := m.discos[claveMemoria(ruta)]
+	return ok
+}
+
+func (m *Memoria) Eliminar(ruta string) error {
+	m.bloqueo.Lock()
+	defer m.bloqueo.Unlock()
+	clave := claveMemoria(ruta)
+	if _, ok := m.discos[clave]; !ok {
+		return &fs.PathError{Op: "remove", Path: ruta, Err: fs.ErrNotExist}
+	}
+	delete(m.discos, clave)
+	return nil
+}
+
+func (m *Memoria) Listar(patron string) ([]string, error) {
+	patron = claveMemoria(patron)
+	m.bloqueo.Lock()
+	defer m.bloqueo.Unlock()
+	var rutas []string
+	for ruta := range m.discos {
+		coincide, err := filepath.Match(patron, ruta)
+		if err != nil {
+			return nil, err
+		}
+		if coincide {
+			rutas = append(rutas, ruta)
+		}
+	}
+	sort.Strings(rutas)
+	return rutas, nil
+}
+
+func (d *discoMemoria) ReadAt(p []byte, off int64) (int, error) {
+	if d.cerrado {
+		return 0, ErrDiscoCerrado
+	}
+	d.datos.bloqueo.RLock()
+	defer d.datos.bloqueo.RUnlock()
+	if off < 0 {
+		return 0, errors.New("offset negativo")
+	}
+	if off >= int64(len(d.datos.datos)) {
+		return 0, io.EOF
+	}
+	n := copy(p, d.datos.datos[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt escribe en el disco y lo agranda si se escribe más allá del final, igual que un archivo
+func (d *discoMemoria) WriteAt(p []byte, off int64) (int, error) {
+	if d.cerrado {
+		return 0, ErrDiscoCerrado
+	}
+	if !d.escritura {
+		return 0, errors.New("disco abierto solo para lectura")
+	}
+	if off < 0 {
+		return 0, errors.New("offset negativo")
+	}
+	d.datos.bloqueo.Lock()
+	defer d.datos.bloqueo.Unlock()
+	if fin := off + int64(len(p)); fin > int64(len(d.datos.datos)) {
+		d.datos.datos = append(d.datos.datos, make([]byte, fin-int64(len(d.datos.datos)))...)
+	}
+	return copy(d.datos.datos[off:], p), nil
+}
+
+func (d *discoMemoria) Size() (int64, error) {
+	if d.cerrado {
+		return 0, ErrDiscoCerrado
+	}
+	d.datos.bloqueo.RLock()
+	defer d.datos.bloqueo.RUnlock()
+	return int64(len(d.datos.datos)), nil
+}
+
+func (d *discoMemoria) Sync() error {
+	if d.cerrado {
+		return ErrDiscoCerrado
+	}
+	return nil
+}
+
+func (d *discoMemoria) Close() error {
+	if d.cerrado {
+		return ErrDiscoCerrado
+	}
+	d.cerrado = true
+	return nil
+}
@@ -0,0 +1,72 @@
+package almacenamiento
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMemoriaCrearVaciaElDiscoAbierto(t *testing.T) {
+	m := NuevaMemoria()
+	viejo, err := m.Crear("Disks/A.mia", 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := viejo.WriteAt([]byte("abcd"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un mkdisk sobre el mismo nombre no debe dejar al handle abierto con otro buffer
+	if _, err := m.Crear("Disks/A.mia", 4); err != nil {
+		t.Fatal(err)
+	}
+	if tam, _ := viejo.Size(); tam != 4 {
+		t.Fatalf("el handle abierto ve %d bytes, se esperaban 4", tam)
+	}
+	if _, err := viejo.WriteAt([]byte("zz"), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	nuevo, err := m.Abrir("Disks/./A.mia", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := nuevo.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, []byte{0, 0, 'z', 'z'}) {
+		t.Fatalf("contenido %q, se esperaba la escritura del handle viejo sobre el disco vacío", buf)
+	}
+}
+
+func TestMemoriaDisco(t *testing.T) {
+	m := NuevaMemoria()
+	d, _ := m.Crear("A.mia", 4)
+	lectura, _ := m.Abrir("A.mia", false)
+
+	casos := []struct {
+		nombre string
+		op     func() error
+		falla  bool
+	}{
+		{"lectura dentro del disco", func() error { _, err := d.ReadAt(make([]byte, 4), 0); return err }, false},
+		{"lectura pasando el fin", func() error { _, err := d.ReadAt(make([]byte, 8), 0); return err }, true},
+		{"escritura más allá del fin", func() error { _, err := d.WriteAt([]byte("x"), 9); return err }, false},
+		{"escritura en apertura de lectura", func() error { _, err := lectura.WriteAt([]byte("x"), 0); return err }, true},
+		{"abrir disco inexistente", func() error { _, err := m.Abrir("B.mia", false); return err }, true},
+		{"cerrar", d.Close, false},
+		{"cerrar dos veces", d.Close, true},
+	}
+	for _, c := range casos {
+		if err := c.op(); (err != nil) != c.falla {
+			t.Errorf("%s: error %v", c.nombre, err)
+		}
+	}
+
+	if tam, _ := lectura.Size(); tam != 10 {
+		t.Errorf("tamaño %d después de escribir en 9, se esperaba 10", tam)
+	}
+	if _, err := d.ReadAt(make([]byte, 1), 0); err != ErrDiscoCerrado {
+		t.Errorf("leer un disco cerrado devolvió %v", err)
+	}
+}
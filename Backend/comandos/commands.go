@@ -1,232 +1,390 @@
 package comandos
 
 import (
+	"Proyecto/Reportes"
 	"Proyecto/comandos/admonDisk"
 	"Proyecto/comandos/admonFS"
 	"Proyecto/comandos/admonUsers"
+	"Proyecto/comandos/errores"
 	"Proyecto/comandos/filecomands"
 
 	/*royecto/comandos/admonUsers"*/
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-type Handler func(comando string, props map[string]string) (string, bool)
+type Handler func(comando string, props map[string]string) (string, interface{}, error)
+
+// TipoParametro indica cómo se valida el valor de un parámetro
+type TipoParametro int
+
+const (
+	TipoTexto   TipoParametro = iota
+	TipoEntero                // número entero en base 10
+	TipoBandera               // puede escribirse sin valor (-p); se guarda como "true"
+)
+
+// ParamDef declara un parámetro aceptado por un comando
+type ParamDef struct {
+	Nombre      string
+	Tipo        TipoParametro
+	Requerido   bool
+	Defecto     string
+	Permitidos  []string // si no está vacío, el valor debe ser uno de estos (sin distinguir mayúsculas)
+	Descripcion string
+}
 
 type CommandDef struct {
-	Allowed  map[string]bool
-	Required []string
-	Defaults map[string]string
-	Run      Handler
+	Descripcion string
+	Parametros  []ParamDef
+	Run         Handler
+}
+
+// parametro busca la declaración de un parámetro por nombre
+func (def CommandDef) parametro(nombre string) (ParamDef, bool) {
+	for _, p := range def.Parametros {
+		if strings.EqualFold(p.Nombre, nombre) {
+			return p, true
+		}
+	}
+	return ParamDef{}, false
 }
 
+var fits = []string{"BF", "FF", "WF"}
+
+// commands es el único registro de comandos: el dispatcher, la validación y los
+// valores por defecto salen de aquí, así que un comando nuevo solo se agrega en este mapa
 var commands = map[string]CommandDef{
 	"mkdisk": {
-		Allowed: map[string]bool{
-			"size": true, "fit": true, "unit": true,
+		Descripcion: "Crea un disco virtual",
+		Parametros: []ParamDef{
+			{Nombre: "size", Tipo: TipoEntero, Requerido: true, Descripcion: "Tamaño del disco"},
+			{Nombre: "fit", Defecto: "FF", Permitidos: fits, Descripcion: "Ajuste para las particiones"},
+			{Nombre: "unit", Defecto: "M", Permitidos: []string{"K", "M"}, Descripcion: "Unidad de -size"},
 		},
-		Required: []string{"size"},
-		Defaults: map[string]string{"fit": "FF", "unit": "M"},
-		Run:      admonDisk.MkdiskExecute,
+		Run: admonDisk.MkdiskExecute,
 	},
 	"rmdisk": {
-		Allowed: map[string]bool{
-			"diskname": true,
+		Descripcion: "Elimina un disco virtual",
+		Parametros: []ParamDef{
+			{Nombre: "diskname", Requerido: true, Descripcion: "Nombre del disco (.mia)"},
 		},
-		Required: []string{"diskname"},
-		Defaults: map[string]string{},
-		Run:      admonDisk.RmdiskExecute,
+		Run: admonDisk.RmdiskExecute,
 	},
 	"fdisk": {
-		Allowed: map[string]bool{
-			"size": true, "unit": true, "diskName": true, "type": true, "fit": true, "name": true,
+		Descripcion: "Crea una partición en un disco",
+		Parametros: []ParamDef{
+			{Nombre: "size", Tipo: TipoEntero, Requerido: true, Descripcion: "Tamaño de la partición"},
+			{Nombre: "unit", Defecto: "K", Permitidos: []string{"B", "K", "M"}, Descripcion: "Unidad de -size"},
+			{Nombre: "diskname", Requerido: true, Descripcion: "Disco donde se crea la partición"},
+			{Nombre: "type", Defecto: "P", Permitidos: []string{"P", "E", "L"}, Descripcion: "Primaria, extendida o lógica"},
+			{Nombre: "fit", Defecto: "WF", Permitidos: fits, Descripcion: "Ajuste de la partición"},
+			{Nombre: "name", Requerido: true, Descripcion: "Nombre de la partición"},
 		},
-		Required: []string{"size", "diskname", "name"},
-		Defaults: map[string]string{"unit": "K", "type": "P", "fit": "WF"},
-		Run:      admonDisk.FdiskExecute,
+		Run: admonDisk.FdiskExecute,
 	},
 	"mount": {
-		Allowed: map[string]bool{
-			"diskname": true, "name": true,
+		Descripcion: "Monta una partición y le asigna un ID",
+		Parametros: []ParamDef{
+			{Nombre: "diskname", Requerido: true, Descripcion: "Disco de la partición"},
+			{Nombre: "name", Requerido: true, Descripcion: "Nombre de la partición"},
 		},
-		Required: []string{"diskname", "name"},
-		Defaults: map[string]string{},
-		Run:      admonDisk.MountExecute,
+		Run: admonDisk.MountExecute,
 	},
 	"mounted": {
-		Allowed:  map[string]bool{},
-		Required: []string{},
-		Defaults: map[string]string{},
-		Run:      admonDisk.MountedExecute,
+		Descripcion: "Lista las particiones montadas",
+		Run:         admonDisk.MountedExecute,
+	},
+	"snapshot": {
+		Descripcion: "Crea o lista snapshots de un disco",
+		Parametros: []ParamDef{
+			{Nombre: "diskname", Descripcion: "Disco del snapshot"},
+			{Nombre: "name", Descripcion: "Nombre del snapshot"},
+			{Nombre: "list", Tipo: TipoBandera, Descripcion: "Lista los snapshots en lugar de crear uno"},
+		},
+		Run: admonDisk.SnapshotExecute,
+	},
+	"restore": {
+		Descripcion: "Restaura un disco o una partición desde un snapshot",
+		Parametros: []ParamDef{
+			{Nombre: "diskname", Requerido: true, Descripcion: "Disco a restaurar"},
+			{Nombre: "name", Requerido: true, Descripcion: "Nombre del snapshot"},
+			{Nombre: "partition", Descripcion: "Restaura solo esta partición"},
+		},
+		Run: admonDisk.RestoreExecute,
 	},
 	"mkfs": {
-		Allowed: map[string]bool{
-			"id": true, "type": true,
+		Descripcion: "Formatea una partición montada con EXT2",
+		Parametros: []ParamDef{
+			{Nombre: "id", Requerido: true, Descripcion: "ID de la partición montada"},
+			{Nombre: "type", Defecto: "FULL", Permitidos: []string{"FULL"}, Descripcion: "Tipo de formateo"},
+			{Nombre: "fs", Defecto: "2fs", Permitidos: []string{"2fs"}, Descripcion: "Sistema de archivos"},
+			{Nombre: "version", Defecto: "1", Permitidos: []string{"1", "2"}, Descripcion: "1 = bitmaps ASCII (un byte por bit), 2 = bitmaps empaquetados, solo si se pide"},
 		},
-		Required: []string{"id"},
-		Defaults: map[string]string{"type": "FULL"},
-		Run:      admonFS.MkfsExecute,
+		Run: admonFS.MkfsExecute,
 	},
-	"cat": {
-		Allowed: map[string]bool{
-			"file1": true, "file2": true, "file3": true, "file4": true, "file5": true,
-			"file6": true, "file7": true, "file8": true, "file9": true, "file10": true,
+	"defrag": {
+		Descripcion: "Compacta los bloques de una partición",
+		Parametros: []ParamDef{
+			{Nombre: "id", Requerido: true, Descripcion: "ID de la partición montada"},
 		},
-		Required: []string{"file1"},
-		Defaults: map[string]string{},
-		Run:      filecomands.CatExecute,
+		Run: admonFS.DefragExecute,
+	},
+	"fsck": {
+		Descripcion: "Revisa la consistencia de una partición sin modificarla",
+		Parametros: []ParamDef{
+			{Nombre: "id", Requerido: true, Descripcion: "ID de la partición montada"},
+		},
+		Run: admonFS.FsckExecute,
+	},
+	"cat": {
+		Descripcion: "Muestra el contenido de uno o más archivos",
+		Parametros:  parametrosCat(),
+		Run:         filecomands.CatExecute,
 	},
-	// En el mapa commands, agregar después de "cat":
-
 	"login": {
-		Allowed: map[string]bool{
-			"user": true, "pass": true, "id": true,
+		Descripcion: "Inicia sesión en una partición",
+		Parametros: []ParamDef{
+			{Nombre: "user", Requerido: true, Descripcion: "Usuario"},
+			{Nombre: "pass", Requerido: true, Descripcion: "Contraseña"},
+			{Nombre: "id", Requerido: true, Descripcion: "ID de la partición montada"},
 		},
-		Required: []string{"user", "pass", "id"},
-		Defaults: map[string]string{},
-		Run:      admonUsers.LoginExecute,
+		Run: admonUsers.LoginExecute,
 	},
 	"logout": {
-		Allowed:  map[string]bool{},
-		Required: []string{},
-		Defaults: map[string]string{},
-		Run:      admonUsers.LogoutExecute,
+		Descripcion: "Cierra la sesión activa",
+		Run:         admonUsers.LogoutExecute,
 	},
 	"mkgrp": {
-		Allowed: map[string]bool{
-			"name": true,
+		Descripcion: "Crea un grupo",
+		Parametros: []ParamDef{
+			{Nombre: "name", Requerido: true, Descripcion: "Nombre del grupo"},
 		},
-		Required: []string{"name"},
-		Defaults: map[string]string{},
-		Run:      filecomands.MkgrpExecute,
+		Run: filecomands.MkgrpExecute,
 	},
 	"mkusr": {
-		Allowed: map[string]bool{
-			"user": true, "pass": true, "grp": true,
+		Descripcion: "Crea un usuario",
+		Parametros: []ParamDef{
+			{Nombre: "user", Requerido: true, Descripcion: "Nombre del usuario"},
+			{Nombre: "pass", Requerido: true, Descripcion: "Contraseña"},
+			{Nombre: "grp", Requerido: true, Descripcion: "Grupo del usuario"},
 		},
-		Required: []string{"user", "pass", "grp"},
-		Defaults: map[string]string{},
-		Run:      filecomands.MkusrExecute,
+		Run: filecomands.MkusrExecute,
+	},
+	"mkdir": {
+		Descripcion: "Crea un directorio",
+		Parametros: []ParamDef{
+			{Nombre: "path", Requerido: true, Descripcion: "Ruta del directorio"},
+			{Nombre: "p", Tipo: TipoBandera, Descripcion: "Crea también los directorios padre"},
+		},
+		Run: filecomands.MkdirExecute,
 	},
 	"mkfile": {
-		Allowed: map[string]bool{
-			"path": true, "cont": true, "size": true, "r": true,
+		Descripcion: "Crea un archivo",
+		Parametros: []ParamDef{
+			{Nombre: "path", Requerido: true, Descripcion: "Ruta del archivo"},
+			{Nombre: "cont", Descripcion: "Contenido del archivo"},
+			{Nombre: "size", Tipo: TipoEntero, Descripcion: "Tamaño en bytes, relleno con dígitos"},
+			{Nombre: "r", Tipo: TipoBandera, Descripcion: "Crea también las carpetas padre"},
+		},
+		Run: filecomands.MkfileExecute,
+	},
+	"import": {
+		Descripcion: "Copia un archivo o carpeta del host a la partición",
+		Parametros: []ParamDef{
+			{Nombre: "src", Requerido: true, Descripcion: "Ruta en el host"},
+			{Nombre: "dest", Requerido: true, Descripcion: "Ruta destino en la partición"},
+		},
+		Run: filecomands.ImportExecute,
+	},
+	"export": {
+		Descripcion: "Copia una ruta de la partición al host",
+		Parametros: []ParamDef{
+			{Nombre: "id", Requerido: true, Descripcion: "ID de la partición montada"},
+			{Nombre: "path", Defecto: "/", Descripcion: "Ruta a exportar"},
+			{Nombre: "dest", Requerido: true, Descripcion: "Destino en el host"},
+			{Nombre: "format", Defecto: "dir", Permitidos: []string{"dir", "tar"}, Descripcion: "Carpeta o archivo tar"},
+		},
+		Run: filecomands.ExportExecute,
+	},
+	"exec": {
+		Descripcion: "Ejecuta un script .smia del host",
+		Parametros: []ParamDef{
+			{Nombre: "path", Requerido: true, Descripcion: "Ruta del script; si es relativa se resuelve contra -base"},
+			{Nombre: "base", Descripcion: "Carpeta base de -path; por defecto la del script que hace el exec, o utils.DirectorioScripts fuera de un script"},
+		},
+		// Sin Run: general.EjecutarComandos corre el script dentro de la ejecución en curso
+	},
+	"rep": {
+		Descripcion: "Genera un reporte",
+		Parametros: []ParamDef{
+			{Nombre: "name", Requerido: true, Descripcion: "Tipo de reporte"},
+			{Nombre: "id", Descripcion: "ID de la partición montada"},
+			{Nombre: "path", Descripcion: "Ruta del reporte dentro de la carpeta de reportes"},
+			{Nombre: "namereport", Descripcion: "Alias de -path"},
+			{Nombre: "format", Descripcion: "Formato de salida"},
+			{Nombre: "path_file_ls", Descripcion: "Archivo o carpeta para los reportes file y ls"},
+			{Nombre: "diskname", Descripcion: "Disco para el reporte raw"},
+			{Nombre: "offset", Descripcion: "Byte inicial del reporte raw (admite 0x)"},
+			{Nombre: "len", Descripcion: "Cantidad de bytes del reporte raw (admite 0x)"},
 		},
-		Required: []string{"path"},
-		Defaults: map[string]string{},
-		Run:      filecomands.MkfileExecute,
+		Run: Reportes.RepExecute,
 	},
 }
 
-func DiskCommandProps(comando string, instrucciones []string) (string, bool) {
-	//fmt.Println(comando, instrucciones)
-	cmd := strings.ToLower(comando)
-	def, ok := commands[cmd]
-
-	if !ok {
-		// return nil, fmt.Sprintf("Comando no reconocido: %s", comando), true
-		return fmt.Sprintf("Comando no reconocido: %s", comando), true
+func parametrosCat() []ParamDef {
+	params := make([]ParamDef, 0, 10)
+	for i := 1; i <= 10; i++ {
+		params = append(params, ParamDef{Nombre: fmt.Sprintf("file%d", i), Descripcion: "Ruta de un archivo a mostrar"})
 	}
+	return params
+}
+
+// Buscar devuelve la definición registrada de un comando
+func Buscar(comando string) (CommandDef, bool) {
+	def, ok := commands[strings.ToLower(strings.TrimSpace(comando))]
+	return def, ok
+}
 
-	// fmt.Println(def)
-	allowedLower := make(map[string]bool, len(def.Allowed))
-	for k := range def.Allowed {
-		allowedLower[strings.ToLower(k)] = true
+// Nombres devuelve los comandos registrados en orden alfabético
+func Nombres() []string {
+	nombres := make([]string, 0, len(commands))
+	for nombre := range commands {
+		nombres = append(nombres, nombre)
 	}
+	sort.Strings(nombres)
+	return nombres
+}
 
+// Validar revisa los parámetros contra la declaración del comando y devuelve el mapa
+// que recibe el handler, con los valores por defecto aplicados. banderas son los
+// parámetros escritos sin valor (-p).
+func (def CommandDef) Validar(comando string, valores map[string]string, banderas []string) (map[string]string, error) {
+	cmd := strings.ToUpper(comando)
 	props := make(map[string]string)
-	for k, v := range def.Defaults {
-		props[strings.ToLower(k)] = v
+
+	for _, bandera := range banderas {
+		p, ok := def.parametro(bandera)
+		if !ok {
+			return nil, errores.Nuevof(errores.ParametroInvalido, "[%s]: Parámetro no permitido: -%s", cmd, strings.ToLower(bandera))
+		}
+		if p.Tipo != TipoBandera {
+			return nil, errores.Nuevof(errores.ParametroInvalido, "[%s]: Parámetro -%s requiere un valor", cmd, p.Nombre)
+		}
+		props[p.Nombre] = "true"
 	}
 
-	seen := make(map[string]bool)
+	for clave, valor := range valores {
+		p, ok := def.parametro(clave)
+		if !ok {
+			return nil, errores.Nuevof(errores.ParametroInvalido, "[%s]: Parámetro no permitido: -%s", cmd, strings.ToLower(clave))
+		}
+		if _, repetido := props[p.Nombre]; repetido {
+			return nil, errores.Nuevof(errores.ParametroInvalido, "[%s]: Parámetro -%s repetido", cmd, p.Nombre)
+		}
+		if err := p.validarValor(cmd, strings.TrimSpace(valor)); err != nil {
+			return nil, err
+		}
+		props[p.Nombre] = valor
+	}
 
-	// parseamos los parámetros
-	for _, token := range instrucciones {
-		token = strings.TrimSpace(token)
-		if token == "" {
+	for _, p := range def.Parametros {
+		if strings.TrimSpace(props[p.Nombre]) != "" {
 			continue
 		}
-
-		if !strings.Contains(token, "=") {
-			// return nil, fmt.Sprintf("Parámetro inválido: '%v'", token), true
-			return fmt.Sprintf("Parámetro inválido: '%v'", token), true
+		if p.Requerido {
+			return nil, errores.Nuevof(errores.ParametroInvalido, "[%s]: Parámetro -%s es obligatorio", cmd, p.Nombre)
+		}
+		if p.Defecto != "" {
+			props[p.Nombre] = p.Defecto
 		}
+	}
 
-		parts := strings.SplitN(token, "=", 2)
-		key := strings.ToLower(strings.TrimSpace(parts[0]))
-		val := strings.TrimSpace(parts[1])
+	return props, nil
+}
 
-		if key == "" {
-			// return nil, fmt.Sprintf("Parámetro inválido: '%s'", token), true
-			return fmt.Sprintf("Parámetro inválido: '%s'", token), true
-		}
+func (p ParamDef) validarValor(cmd string, valor string) error {
+	if valor == "" {
+		return nil
+	}
 
-		if !allowedLower[key] {
-			// return nil, fmt.Sprintf("Parámetro no permitido para '%s': '%s'", cmd, key), true
-			return fmt.Sprintf("Parámetro no permitido para '%s': '%s'", cmd, key), true
+	if p.Tipo == TipoEntero {
+		if _, err := strconv.Atoi(valor); err != nil {
+			return errores.Nuevof(errores.ParametroInvalido, "[%s]: Parámetro -%s debe ser un número entero", cmd, p.Nombre)
 		}
+	}
 
-		if seen[key] {
-			// return nil, fmt.Sprintf("Parámetro no permitido: %s", key), true
-			return fmt.Sprintf("Parámetro no permitido: %s", key), true
+	if len(p.Permitidos) > 0 {
+		for _, permitido := range p.Permitidos {
+			if strings.EqualFold(valor, permitido) {
+				return nil
+			}
 		}
-
-		seen[key] = true
-		props[key] = val
+		return errores.Nuevof(errores.ParametroInvalido, "[%s]: Valor '%s' no permitido para -%s (valores permitidos: %s)",
+			cmd, valor, p.Nombre, strings.Join(p.Permitidos, ", "))
 	}
+	return nil
+}
 
-	// verificar valores mínimos
-	for _, req := range def.Required {
-		reqLower := strings.ToLower(req)
-		if strings.TrimSpace(props[reqLower]) == "" {
-			// return nil, fmt.Sprintf("Parámetro obligatorio faltante: %s", req), true
-			return fmt.Sprintf("Parámetro obligatorio faltante: %s", req), true
-		}
+// Ejecutar valida los parámetros del comando y llama a su handler
+func Ejecutar(comando string, valores map[string]string, banderas []string) (string, interface{}, error) {
+	def, ok := Buscar(comando)
+	if !ok {
+		return "", nil, errores.Nuevof(errores.ComandoDesconocido, "Comando no reconocido: %s", comando)
 	}
 
-	// spec, ok := commands[cmd]
+	props, err := def.Validar(comando, valores, banderas)
+	if err != nil {
+		return "", nil, err
+	}
 	if def.Run == nil {
-		return fmt.Sprintf("Comando que no tiene handler: %s", cmd), true
+		return "", nil, errores.Nuevof(errores.ComandoDesconocido, "Comando que no tiene handler: %s", comando)
 	}
 
-	return def.Run(comando, props)
-
+	return def.Run(strings.ToLower(comando), props)
 }
 
-func DiskExecuteCommanWithProps(command string, parameters []string) {
-	temp, ok := DiskCommandProps(command, parameters)
-	if !ok {
-		return
-	}
+func DiskCommandProps(comando string, instrucciones []string) (string, interface{}, error) {
+	valores := make(map[string]string)
+	var banderas []string
 
-	fmt.Println(temp)
-}
+	// parseamos los parámetros
+	for _, token := range instrucciones {
+		token = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(token), "-"))
+		if token == "" {
+			continue
+		}
 
-func DiskExecuteWithOutput(command string, rawParams map[string]string) (string, bool) {
-	// Reutiliza la lógica de validación y ejecución
-	// pero sin imprimir, solo retornando
-	cmd := strings.ToLower(command)
-	def, ok := commands[cmd]
-	if !ok {
-		return fmt.Sprintf("Comando no reconocido: %s", command), true
-	}
+		if !strings.Contains(token, "=") {
+			banderas = append(banderas, token)
+			continue
+		}
 
-	props := make(map[string]string)
-	for k, v := range def.Defaults {
-		props[strings.ToLower(k)] = v
+		parts := strings.SplitN(token, "=", 2)
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		if key == "" {
+			return "", nil, errores.Nuevof(errores.ParametroInvalido, "Parámetro inválido: '%s'", token)
+		}
+		if _, repetido := valores[key]; repetido {
+			return "", nil, errores.Nuevof(errores.ParametroInvalido, "Parámetro no permitido: %s", key)
+		}
+		valores[key] = strings.TrimSpace(parts[1])
 	}
 
-	for k, v := range rawParams {
-		props[strings.ToLower(k)] = v
-	}
+	return Ejecutar(comando, valores, banderas)
+}
 
-	// Validar required
-	for _, req := range def.Required {
-		if strings.TrimSpace(props[strings.ToLower(req)]) == "" {
-			return fmt.Sprintf("Parámetro obligatorio faltante: %s", req), true
-		}
+func DiskExecuteCommanWithProps(command string, parameters []string) {
+	_, _, err := DiskCommandProps(command, parameters)
+	if err == nil {
+		return
 	}
 
-	return def.Run(command, props)
+	fmt.Println(err)
+}
+
+func DiskExecuteWithOutput(command string, rawParams map[string]string) (string, interface{}, error) {
+	return Ejecutar(command, rawParams, nil)
 }
@@ -0,0 +1,61 @@
+package comandos
+
+import (
+	"Proyecto/comandos/errores"
+	"reflect"
+	"testing"
+)
+
+func TestValidar(t *testing.T) {
+	casos := []struct {
+		comando  string
+		valores  map[string]string
+		banderas []string
+		quiere   map[string]string // nil si debe fallar
+	}{
+		{"mkdisk", map[string]string{"size": "5"}, nil,
+			map[string]string{"size": "5", "fit": "FF", "unit": "M"}},
+		{"mkdisk", map[string]string{"size": "5", "unit": "k", "fit": "bf"}, nil,
+			map[string]string{"size": "5", "unit": "k", "fit": "bf"}},
+		{"mkdisk", map[string]string{"size": "cinco"}, nil, nil},
+		{"mkdisk", map[string]string{"size": "5", "unit": "G"}, nil, nil},
+		{"mkdisk", map[string]string{"unit": "M"}, nil, nil},
+		{"mkdisk", map[string]string{"size": "5", "color": "rojo"}, nil, nil},
+		{"mkfs", map[string]string{"id": "191A"}, nil,
+			map[string]string{"id": "191A", "type": "FULL", "fs": "2fs", "version": "1"}},
+		{"mkfs", map[string]string{"id": "191A", "version": "3"}, nil, nil},
+		{"mkdir", map[string]string{"path": "/a/b"}, []string{"p"},
+			map[string]string{"path": "/a/b", "p": "true"}},
+		{"mkdir", map[string]string{"path": "/a/b"}, []string{"r"}, nil},
+		{"mkdir", nil, []string{"path"}, nil},
+		{"mkfile", map[string]string{"path": "/a.txt"}, []string{"r"},
+			map[string]string{"path": "/a.txt", "r": "true"}},
+		{"mkfile", map[string]string{"path": "/a.txt", "size": "-"}, nil, nil},
+	}
+	for _, c := range casos {
+		def, ok := Buscar(c.comando)
+		if !ok {
+			t.Fatalf("%s no está registrado", c.comando)
+		}
+		props, err := def.Validar(c.comando, c.valores, c.banderas)
+		if c.quiere == nil {
+			if !errores.Es(err, errores.ParametroInvalido) {
+				t.Errorf("%s %v %v: se esperaba ERR_INVALID_PARAM, se obtuvo %v", c.comando, c.valores, c.banderas, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %v %v: error inesperado %v", c.comando, c.valores, c.banderas, err)
+			continue
+		}
+		if !reflect.DeepEqual(props, c.quiere) {
+			t.Errorf("%s %v %v: %v, se esperaba %v", c.comando, c.valores, c.banderas, props, c.quiere)
+		}
+	}
+}
+
+func TestEjecutarComandoDesconocido(t *testing.T) {
+	if _, _, err := Ejecutar("formatear", nil, nil); !errores.Es(err, errores.ComandoDesconocido) {
+		t.Errorf("se esperaba ERR_UNKNOWN_COMMAND, se obtuvo %v", err)
+	}
+}
@@ -1,11 +1,23 @@
 package controllers
 
 import (
+	"Proyecto/Reportes"
+	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/errores"
 	"Proyecto/comandos/general"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/utils"
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
 func HandleCommand(w http.ResponseWriter, r *http.Request) {
@@ -28,40 +40,49 @@ func HandleCommand(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var requestBody struct {
-		Comandos *string `json:"Comandos"`
+		Comandos      *string `json:"Comandos"`
+		Transaccional bool    `json:"Transaccional"` // opcional: revertir todo si un comando falla
 	}
 
 	decoder := json.NewDecoder(r.Body)
 	decoder.DisallowUnknownFields()
 
 	if err := decoder.Decode(&requestBody); err != nil {
-		json.NewEncoder(w).Encode(general.ResultadoSalida("JSON inválido o campos no permitidos", true, nil))
+		json.NewEncoder(w).Encode(general.ResultadoFallido("JSON inválido o campos no permitidos", errores.ParametroInvalido, nil))
 		return
 	}
 
 	if requestBody.Comandos == nil || strings.TrimSpace(*requestBody.Comandos) == "" {
-		json.NewEncoder(w).Encode(general.ResultadoSalida("El campo 'Comandos' es obligatorio y no puede ser nulo", true, nil))
+		json.NewEncoder(w).Encode(general.ResultadoFallido("El campo 'Comandos' es obligatorio y no puede ser nulo", errores.ParametroInvalido, nil))
 		return
 	}
 
-	comandosLista := strings.Split(strings.TrimSpace(*requestBody.Comandos), "\n")
-	var comandosValidos []string
-	for _, cmd := range comandosLista {
-		if trimmed := strings.TrimSpace(cmd); trimmed != "" {
-			comandosValidos = append(comandosValidos, trimmed)
-		}
-	}
+	responderEjecucion(w, general.SepararLineas(*requestBody.Comandos), requestBody.Transaccional)
+}
 
-	if len(comandosValidos) == 0 {
-		json.NewEncoder(w).Encode(general.ResultadoSalida("No hay comandos válidos para ejecutar", true, nil))
+// responderEjecucion ejecuta las líneas de la petición tal como llegaron, con las vacías
+// incluidas, para que la línea y la columna de cada resultado sean las del texto enviado
+func responderEjecucion(w http.ResponseWriter, lineas []string, transaccional bool) {
+	global.BloqueoDiscos.Lock()
+	ejecucion := general.EjecutarComandos(lineas, transaccional)
+	global.BloqueoDiscos.Unlock()
+
+	// Si la transacción se revirtió, se indica el comando que la provocó
+	if transaccion := ejecucion.Transaccion; transaccion != nil && transaccion.Revertida {
+		resultado := general.ResultadoFallido(transaccion.Mensaje, transaccion.Codigo, ejecucion.Salidas)
+		resultado.Errors = ejecucion.Fallos
+		resultado.Results = ejecucion.Resultados
+		json.NewEncoder(w).Encode(resultado)
 		return
 	}
 
-	_, todasSalidas, _ := general.GlobalCom(comandosValidos)
-
-	//Devolver todas las salidas al frontend
-	if err := json.NewEncoder(w).Encode(general.ResultadoSalida("", false, todasSalidas)); err != nil {
-		json.NewEncoder(w).Encode(general.ResultadoSalida("Error interno al generar respuesta", true, nil))
+	// "data" conserva el texto de cada línea para los clientes anteriores; "results" trae
+	// cada comando ejecutado con sus parámetros, su código y su duración
+	resultado := general.ResultadoSalida("", false, ejecucion.Salidas)
+	resultado.Errors = ejecucion.Fallos
+	resultado.Results = ejecucion.Resultados
+	if err := json.NewEncoder(w).Encode(resultado); err != nil {
+		json.NewEncoder(w).Encode(general.ResultadoFallido("Error interno al generar respuesta", errores.Interno, nil))
 	}
 }
 
@@ -92,43 +113,36 @@ func HandleReportsObtener(w http.ResponseWriter, r *http.Request) {
 	decoder.DisallowUnknownFields()
 
 	if err := decoder.Decode(&requestBody); err != nil {
-		json.NewEncoder(w).Encode(general.ResultadoSalida("JSON inválido o campos no permitidos", true, nil))
+		json.NewEncoder(w).Encode(general.ResultadoFallido("JSON inválido o campos no permitidos", errores.ParametroInvalido, nil))
 		return
 	}
 
 	if requestBody.Comandos == nil || strings.TrimSpace(*requestBody.Comandos) == "" {
-		json.NewEncoder(w).Encode(general.ResultadoSalida("El campo 'Comandos' es obligatorio y no puede ser nulo", true, nil))
-		return
-	}
-
-	comandosLista := strings.Split(strings.TrimSpace(*requestBody.Comandos), "\n")
-	var comandosRep []string // Solo comandos 'rep'
-	for _, cmd := range comandosLista {
-		trimmed := strings.TrimSpace(cmd)
-		if trimmed != "" {
-			// Verificar si es un comando 'rep'
-			parts := strings.Fields(trimmed)
-			if len(parts) > 0 && strings.ToLower(parts[0]) == "rep" {
-				comandosRep = append(comandosRep, trimmed)
-			} else if strings.HasPrefix(trimmed, "#") || trimmed == "" {
-				// Añadir comentarios y líneas vacías también
-				comandosRep = append(comandosRep, trimmed)
-			}
-			// Opcional: puedes manejar errores para comandos no 'rep' aquí si lo deseas
+		json.NewEncoder(w).Encode(general.ResultadoFallido("El campo 'Comandos' es obligatorio y no puede ser nulo", errores.ParametroInvalido, nil))
+		return
+	}
+
+	// Solo se ejecutan los comandos rep; las demás líneas quedan vacías en su lugar para
+	// no mover el número de línea de los resultados
+	lineas := general.SepararLineas(*requestBody.Comandos)
+	hayRep := false
+	for i, linea := range lineas {
+		campos := strings.Fields(linea)
+		switch {
+		case len(campos) == 0 || strings.HasPrefix(campos[0], "#"):
+		case strings.EqualFold(campos[0], "rep"):
+			hayRep = true
+		default:
+			lineas[i] = ""
 		}
 	}
 
-	if len(comandosRep) == 0 {
-		json.NewEncoder(w).Encode(general.ResultadoSalida("No hay comandos 'rep' válidos para ejecutar", true, nil))
+	if !hayRep {
+		json.NewEncoder(w).Encode(general.ResultadoFallido("No hay comandos 'rep' válidos para ejecutar", errores.ParametroInvalido, nil))
 		return
 	}
 
-	_, todasSalidas, _ := general.GlobalCom(comandosRep)
-
-	// Devolver todas las salidas al frontend
-	if err := json.NewEncoder(w).Encode(general.ResultadoSalida("", false, todasSalidas)); err != nil {
-		json.NewEncoder(w).Encode(general.ResultadoSalida("Error interno al generar respuesta", true, nil))
-	}
+	responderEjecucion(w, lineas, false)
 }
 
 func HandleListReports(w http.ResponseWriter, r *http.Request) {
@@ -140,19 +154,56 @@ func HandleListReports(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
 
-	repDir := "VDIC-MIA/Rep"
-	var reports []string
+	repDir := utils.DirectorioReportes
+	reports := []string{}
+	detalles := []reporteListado{}
 
 	if _, err := os.Stat(repDir); os.IsNotExist(err) {
 		// Si no existe la carpeta, devolver lista vacía
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"error": false,
-			"data":  []string{},
+			"error":    false,
+			"data":     reports,
+			"reportes": detalles,
 		})
 		return
 	}
 
-	files, err := os.ReadDir(repDir)
+	indice := Reportes.LeerIndiceReportes()
+	// Se recorren también las subcarpetas creadas con rep -path
+	err := filepath.WalkDir(repDir, func(ruta string, entrada fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entrada.IsDir() {
+			if ruta != repDir && strings.HasPrefix(entrada.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relativa, err := filepath.Rel(repDir, ruta)
+		if err != nil {
+			return nil
+		}
+		name := filepath.ToSlash(relativa)
+		// Solo incluir reportes con un tipo de contenido conocido
+		if _, ok := tipoContenidoReporte(entrada.Name()); !ok {
+			return nil
+		}
+		reports = append(reports, name)
+
+		detalle := reporteListado{Nombre: name}
+		if info, err := entrada.Info(); err == nil {
+			detalle.Tamanio = info.Size()
+			detalle.Generado = info.ModTime().Format(time.RFC3339)
+		}
+		if registro, ok := indice[name]; ok {
+			detalle.Tipo = registro.Tipo
+			detalle.ID = registro.ID
+			detalle.Generado = registro.Generado
+		}
+		detalles = append(detalles, detalle)
+		return nil
+	})
 	if err != nil {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"error":   true,
@@ -162,18 +213,138 @@ func HandleListReports(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	for _, file := range files {
-		if !file.IsDir() {
-			name := file.Name()
-			// Solo incluir archivos HTML o TXT (reportes)
-			if strings.HasSuffix(strings.ToLower(name), ".html") || strings.HasSuffix(strings.ToLower(name), ".txt") {
-				reports = append(reports, name)
-			}
+	// "data" conserva la lista de nombres que ya usa el frontend
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":    false,
+		"data":     reports,
+		"reportes": detalles,
+	})
+}
+
+// reporteListado son los metadatos de cada reporte en /reportes/list
+type reporteListado struct {
+	Nombre   string `json:"nombre"`
+	Tamanio  int64  `json:"tamanio"`
+	Generado string `json:"generado"`
+	Tipo     string `json:"tipo,omitempty"`
+	ID       string `json:"id,omitempty"`
+}
+
+// tiposContenidoReporte son las extensiones que se sirven como reporte
+var tiposContenidoReporte = map[string]string{
+	".html": "text/html; charset=utf-8",
+	".txt":  "text/plain; charset=utf-8",
+	".svg":  "image/svg+xml",
+	".dot":  "text/vnd.graphviz; charset=utf-8",
+	".json": "application/json",
+}
+
+func tipoContenidoReporte(nombre string) (string, bool) {
+	if strings.HasPrefix(nombre, ".") {
+		return "", false
+	}
+	tipo, ok := tiposContenidoReporte[strings.ToLower(filepath.Ext(nombre))]
+	return tipo, ok
+}
+
+// nombreReporteValido acepta rutas relativas como "sub/r.html" sin "..", barras invertidas
+// ni componentes ocultos
+func nombreReporteValido(nombre string) bool {
+	if nombre == "" || strings.Contains(nombre, `\`) || path.Clean(nombre) != nombre || path.IsAbs(nombre) {
+		return false
+	}
+	for _, parte := range strings.Split(nombre, "/") {
+		if parte == ".." || strings.HasPrefix(parte, ".") {
+			return false
 		}
 	}
+	return true
+}
+
+// HandleReportFile atiende GET /reportes/file/{name...} y sirve el reporte con su Content-Type
+func HandleReportFile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	nombre := r.PathValue("name")
+	if !nombreReporteValido(nombre) {
+		http.Error(w, "Nombre de reporte inválido", http.StatusBadRequest)
+		return
+	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": false,
-		"data":  reports,
-	})
+	tipo, ok := tipoContenidoReporte(path.Base(nombre))
+	if !ok {
+		http.Error(w, "Reporte no encontrado", http.StatusNotFound)
+		return
+	}
+
+	ruta := filepath.Join(utils.DirectorioReportes, filepath.FromSlash(nombre))
+	// Verificación extra: la ruta final debe seguir dentro de la carpeta de reportes
+	if rel, err := filepath.Rel(utils.DirectorioReportes, ruta); err != nil || strings.HasPrefix(rel, "..") {
+		http.Error(w, "Nombre de reporte inválido", http.StatusBadRequest)
+		return
+	}
+
+	file, err := os.Open(ruta)
+	if err != nil {
+		http.Error(w, "Reporte no encontrado", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.Error(w, "Reporte no encontrado", http.StatusNotFound)
+		return
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		http.Error(w, "Error al leer el reporte", http.StatusInternalServerError)
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, "Error al leer el reporte", http.StatusInternalServerError)
+		return
+	}
+
+	// ServeContent responde 304 cuando If-None-Match coincide con este ETag
+	w.Header().Set("ETag", fmt.Sprintf(`"%x"`, hash.Sum(nil)[:16]))
+	w.Header().Set("Content-Type", tipo)
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, nombre, info.ModTime(), file)
+}
+
+// HandleInspect atiende GET /inspect/{id} con todas las estructuras de la partición en JSON
+func HandleInspect(w http.ResponseWriter, r *http.Request) {
+	global.BloqueoDiscos.Lock()
+	inspeccion, err := Reportes.InspeccionarParticion(admonDisk.MotorComandos(nil), r.PathValue("id"))
+	global.BloqueoDiscos.Unlock()
+
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(estadoHTTP(errores.CodigoDe(err)))
+		json.NewEncoder(w).Encode(general.ResultadoFallido(err.Error(), errores.CodigoDe(err), nil))
+		return
+	}
+	responderFS(w, http.StatusOK, "", inspeccion)
+}
+
+// estadoHTTP traduce el código de un error de comando al estado HTTP de la respuesta
+func estadoHTTP(codigo errores.Codigo) int {
+	switch codigo {
+	case errores.NoEncontrado, errores.NoMontada:
+		return http.StatusNotFound
+	case errores.ParametroInvalido:
+		return http.StatusBadRequest
+	case errores.SinSesion:
+		return http.StatusUnauthorized
+	case errores.SinPermiso:
+		return http.StatusForbidden
+	case errores.YaExiste:
+		return http.StatusConflict
+	case errores.SinEspacio:
+		return http.StatusInsufficientStorage
+	default:
+		return http.StatusInternalServerError
+	}
 }
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/general"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/sintaxis"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ejecutarPeticion envía el cuerpo al handler con los discos en memoria y decodifica la respuesta
+func ejecutarPeticion(t *testing.T, handler http.HandlerFunc, cuerpo interface{}) general.ResultadoAPI {
+	t.Helper()
+	anterior := almacenamiento.Actual()
+	almacenamiento.Usar(almacenamiento.NuevaMemoria())
+	global.SesionActiva = nil
+	t.Cleanup(func() {
+		almacenamiento.Usar(anterior)
+		global.SesionActiva = nil
+	})
+
+	contenido, err := json.Marshal(cuerpo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	respuesta := httptest.NewRecorder()
+	handler(respuesta, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(contenido))))
+
+	var resultado general.ResultadoAPI
+	if err := json.NewDecoder(respuesta.Body).Decode(&resultado); err != nil {
+		t.Fatalf("respuesta inválida: %v\n%s", err, respuesta.Body.String())
+	}
+	return resultado
+}
+
+func TestHandleCommandConservaLineasVacias(t *testing.T) {
+	resultado := ejecutarPeticion(t, HandleCommand, map[string]string{
+		"Comandos": "\n# discos\n\n  mkdisk -size=\r\nmkdisk -size=1 -unit=M\n",
+	})
+
+	if len(resultado.Results) != 2 {
+		t.Fatalf("%d resultados, se esperaban 2: %+v", len(resultado.Results), resultado.Results)
+	}
+
+	// La columna se cuenta sobre la línea enviada, con su sangría
+	_, err := sintaxis.Analizar("mkdisk -size=", 1)
+	var sinSangria *sintaxis.ErrorSintaxis
+	if !errors.As(err, &sinSangria) {
+		t.Fatalf("se esperaba un error de sintaxis, se obtuvo %v", err)
+	}
+	fallido := resultado.Results[0]
+	if fallido.Linea != 4 || fallido.Codigo != errores.Sintaxis || fallido.Columna != sinSangria.Columna+2 {
+		t.Errorf("línea %d, código %q, columna %d; se esperaba línea 4, %q, columna %d",
+			fallido.Linea, fallido.Codigo, fallido.Columna, errores.Sintaxis, sinSangria.Columna+2)
+	}
+	if len(resultado.Errors) != 1 || resultado.Errors[0].Linea != 4 {
+		t.Errorf("errors: %+v", resultado.Errors)
+	}
+	if creado := resultado.Results[1]; creado.Linea != 5 || !creado.Exito {
+		t.Errorf("línea %d, éxito %v; se esperaba la línea 5 sin error: %s", creado.Linea, creado.Exito, creado.Mensaje)
+	}
+}
+
+func TestHandleReportsObtenerConservaLineas(t *testing.T) {
+	resultado := ejecutarPeticion(t, HandleReportsObtener, map[string]string{
+		"Comandos": "mkdisk -size=1 -unit=M\n\n# reportes\nrep -id=191A -path=mbr -name=mbr",
+	})
+
+	// El mkdisk no se ejecuta, pero su línea se sigue contando
+	if len(resultado.Results) != 1 {
+		t.Fatalf("%d resultados, se esperaba solo el rep: %+v", len(resultado.Results), resultado.Results)
+	}
+	if rep := resultado.Results[0]; rep.Comando != "rep" || rep.Linea != 4 {
+		t.Errorf("comando %q en la línea %d; se esperaba rep en la línea 4", rep.Comando, rep.Linea)
+	}
+}
@@ -0,0 +1,478 @@
+package controllers
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/general"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// peticionFS agrupa el disco abierto de la sesión activa para los endpoints /fs/{id}/...
+type peticionFS struct {
+	w      http.ResponseWriter
+	r      *http.Request
+	file   *utils.CacheDisco // caché sobre el disco; Close baja lo pendiente
+	sb     structures.SuperBloque
+	sesion *global.SesionUsuario
+
+	asignador *utils.Asignador // solo en peticiones de escritura
+}
+
+// nodoFS describe un archivo o carpeta en las respuestas JSON
+type nodoFS struct {
+	Nombre      string   `json:"nombre"`
+	Ruta        string   `json:"ruta"`
+	Tipo        string   `json:"tipo"`
+	Tamanio     int32    `json:"tamanio"`
+	Permisos    string   `json:"permisos"`
+	UID         int32    `json:"uid"`
+	GID         int32    `json:"gid"`
+	Propietario string   `json:"propietario"`
+	Grupo       string   `json:"grupo"`
+	Creado      string   `json:"creado"`
+	Modificado  string   `json:"modificado"`
+	Contenido   *string  `json:"contenido,omitempty"`
+	Hijos       []nodoFS `json:"hijos,omitempty"`
+}
+
+// HandleFSArbol atiende GET /fs/{id}/tree[?path=]
+func HandleFSArbol(w http.ResponseWriter, r *http.Request) {
+	global.BloqueoDiscos.Lock()
+	defer global.BloqueoDiscos.Unlock()
+
+	p, ok := abrirPeticionFS(w, r, false)
+	if !ok {
+		return
+	}
+	defer p.cerrar()
+
+	ruta := rutaConsulta(r.URL.Query().Get("path"))
+	if ruta == "" {
+		ruta = "/"
+	}
+
+	u, ok := p.resolverExistente(ruta)
+	if !ok {
+		return
+	}
+	if !utils.TienePermisoLectura(&u.inodo, p.sesion, u.nombre) {
+		responderFS(w, http.StatusForbidden, fmt.Sprintf("No tiene permisos de lectura en '%s'", ruta), nil)
+		return
+	}
+
+	usuarios, grupos := utils.LeerUsuariosGrupos(p.file, &p.sb)
+	visitados := map[int32]bool{u.pos: true}
+	arbol := p.construirNodo(ruta, &u.inodo, usuarios, grupos, visitados)
+	responderFS(w, http.StatusOK, "", arbol)
+}
+
+// HandleFSLeerArchivo atiende GET /fs/{id}/file?path=
+func HandleFSLeerArchivo(w http.ResponseWriter, r *http.Request) {
+	global.BloqueoDiscos.Lock()
+	defer global.BloqueoDiscos.Unlock()
+
+	p, ok := abrirPeticionFS(w, r, false)
+	if !ok {
+		return
+	}
+	defer p.cerrar()
+
+	ruta, ok := p.rutaObligatoria(r.URL.Query().Get("path"))
+	if !ok {
+		return
+	}
+
+	u, ok := p.resolverExistente(ruta)
+	if !ok {
+		return
+	}
+	if u.inodo.I_type[0] == '0' {
+		responderFS(w, http.StatusBadRequest, fmt.Sprintf("'%s' es una carpeta", ruta), nil)
+		return
+	}
+	if !utils.TienePermisoLectura(&u.inodo, p.sesion, u.nombre) {
+		responderFS(w, http.StatusForbidden, fmt.Sprintf("No tiene permisos de lectura en '%s'", ruta), nil)
+		return
+	}
+
+	contenido, err := utils.LeerContenidoArchivo(p.file, &p.sb, &u.inodo)
+	if err != nil {
+		responderFS(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	usuarios, grupos := utils.LeerUsuariosGrupos(p.file, &p.sb)
+	nodo := describirInodo(ruta, &u.inodo, usuarios, grupos)
+	nodo.Contenido = &contenido
+	responderFS(w, http.StatusOK, "", nodo)
+}
+
+// HandleFSEscribirArchivo atiende PUT /fs/{id}/file?path= con cuerpo {"Contenido": "..."}
+func HandleFSEscribirArchivo(w http.ResponseWriter, r *http.Request) {
+	var requestBody struct {
+		Contenido *string `json:"Contenido"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&requestBody); err != nil || requestBody.Contenido == nil {
+		responderFS(w, http.StatusBadRequest, "JSON inválido: se espera {\"Contenido\": \"...\"}", nil)
+		return
+	}
+	contenido := *requestBody.Contenido
+
+	global.BloqueoDiscos.Lock()
+	defer global.BloqueoDiscos.Unlock()
+
+	p, ok := abrirPeticionFS(w, r, true)
+	if !ok {
+		return
+	}
+	defer p.cerrar()
+
+	// El máximo depende del tamaño de bloque de la partición
+	if maximo := utils.MaxContenidoArchivo(&p.sb); int64(len(contenido)) > maximo {
+		responderFS(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Un archivo admite como máximo %d bytes", maximo), nil)
+		return
+	}
+
+	ruta, ok := p.rutaObligatoria(r.URL.Query().Get("path"))
+	if !ok {
+		return
+	}
+
+	u, err := resolverUbicacion(p.file, &p.sb, ruta)
+	if err != nil {
+		responderFS(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+	if !u.existePadre {
+		responderFS(w, http.StatusNotFound, fmt.Sprintf("La carpeta '%s' no existe", path.Dir(ruta)), nil)
+		return
+	}
+
+	if u.existe {
+		if u.inodo.I_type[0] == '0' {
+			responderFS(w, http.StatusConflict, fmt.Sprintf("'%s' es una carpeta", ruta), nil)
+			return
+		}
+		if !utils.TienePermisoEscritura(&u.inodo, p.sesion, "") {
+			responderFS(w, http.StatusForbidden, fmt.Sprintf("No tiene permisos de escritura en '%s'", ruta), nil)
+			return
+		}
+		if err := utils.SobrescribirArchivo(p.file, &p.sb, &u.inodo, u.pos, contenido); err != nil {
+			responderFS(w, http.StatusInsufficientStorage, err.Error(), nil)
+			return
+		}
+		if p.guardarCambios() {
+			responderFS(w, http.StatusOK, fmt.Sprintf("Archivo '%s' actualizado", ruta), nil)
+		}
+		return
+	}
+
+	if !p.nombreValido(u.nombre) {
+		return
+	}
+	if !utils.TienePermisoEscritura(&u.padre, p.sesion, "") {
+		responderFS(w, http.StatusForbidden, fmt.Sprintf("No tiene permisos de escritura en el directorio '%s'", path.Dir(ruta)), nil)
+		return
+	}
+	if err := utils.CrearArchivo(p.file, &p.sb, &u.padre, u.posPadre, u.nombre, contenido, p.sesion); err != nil {
+		p.sincronizarSuperBloque()
+		responderFS(w, http.StatusInsufficientStorage, err.Error(), nil)
+		return
+	}
+	if p.guardarCambios() {
+		responderFS(w, http.StatusCreated, fmt.Sprintf("Archivo '%s' creado", ruta), nil)
+	}
+}
+
+// HandleFSCrearCarpeta atiende POST /fs/{id}/dir con cuerpo {"Path": "...", "P": true}
+func HandleFSCrearCarpeta(w http.ResponseWriter, r *http.Request) {
+	var requestBody struct {
+		Path string `json:"Path"`
+		P    bool   `json:"P"` // igual que mkdir -p: crea las carpetas intermedias
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&requestBody); err != nil {
+		responderFS(w, http.StatusBadRequest, "JSON inválido: se espera {\"Path\": \"...\", \"P\": false}", nil)
+		return
+	}
+
+	global.BloqueoDiscos.Lock()
+	defer global.BloqueoDiscos.Unlock()
+
+	p, ok := abrirPeticionFS(w, r, true)
+	if !ok {
+		return
+	}
+	defer p.cerrar()
+
+	ruta, ok := p.rutaObligatoria(requestBody.Path)
+	if !ok {
+		return
+	}
+
+	u, err := resolverUbicacion(p.file, &p.sb, ruta)
+	if err != nil {
+		responderFS(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+	if u.existe {
+		responderFS(w, http.StatusConflict, fmt.Sprintf("'%s' ya existe", ruta), nil)
+		return
+	}
+	if !u.existePadre && !requestBody.P {
+		responderFS(w, http.StatusNotFound, fmt.Sprintf("La carpeta '%s' no existe (use \"P\": true para crearla)", path.Dir(ruta)), nil)
+		return
+	}
+
+	// Crear cada nivel que falte, revisando permisos en su carpeta padre
+	actual := "/"
+	for _, parte := range strings.Split(strings.Trim(ruta, "/"), "/") {
+		actual = path.Join(actual, parte)
+		nivel, err := resolverUbicacion(p.file, &p.sb, actual)
+		if err != nil {
+			p.sincronizarSuperBloque()
+			responderFS(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+		if nivel.existe {
+			if nivel.inodo.I_type[0] != '0' {
+				p.sincronizarSuperBloque()
+				responderFS(w, http.StatusConflict, fmt.Sprintf("'%s' es un archivo", actual), nil)
+				return
+			}
+			continue
+		}
+		if !p.nombreValido(parte) {
+			p.sincronizarSuperBloque()
+			return
+		}
+		if !utils.TienePermisoEscritura(&nivel.padre, p.sesion, "") {
+			p.sincronizarSuperBloque()
+			responderFS(w, http.StatusForbidden, fmt.Sprintf("No tiene permisos de escritura en el directorio '%s'", path.Dir(actual)), nil)
+			return
+		}
+		if err := utils.CrearDirectorio(p.file, &p.sb, &nivel.padre, nivel.posPadre, parte, p.sesion); err != nil {
+			p.sincronizarSuperBloque()
+			responderFS(w, http.StatusInsufficientStorage, err.Error(), nil)
+			return
+		}
+	}
+
+	if p.guardarCambios() {
+		responderFS(w, http.StatusCreated, fmt.Sprintf("Carpeta '%s' creada", ruta), nil)
+	}
+}
+
+// HandleFSEliminar atiende DELETE /fs/{id}/entry?path=[&recursive=true]
+func HandleFSEliminar(w http.ResponseWriter, r *http.Request) {
+	global.BloqueoDiscos.Lock()
+	defer global.BloqueoDiscos.Unlock()
+
+	p, ok := abrirPeticionFS(w, r, true)
+	if !ok {
+		return
+	}
+	defer p.cerrar()
+
+	ruta, ok := p.rutaObligatoria(r.URL.Query().Get("path"))
+	if !ok {
+		return
+	}
+
+	u, ok := p.resolverExistente(ruta)
+	if !ok {
+		return
+	}
+	if !utils.TienePermisoEscritura(&u.padre, p.sesion, "") {
+		responderFS(w, http.StatusForbidden, fmt.Sprintf("No tiene permisos de escritura en el directorio '%s'", path.Dir(ruta)), nil)
+		return
+	}
+
+	if u.inodo.I_type[0] == '0' && r.URL.Query().Get("recursive") != "true" {
+		entradas, err := utils.ListarCarpeta(p.file, &u.inodo)
+		if err != nil {
+			responderFS(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+		if len(entradas) > 0 {
+			responderFS(w, http.StatusConflict, fmt.Sprintf("La carpeta '%s' no está vacía (use recursive=true)", ruta), nil)
+			return
+		}
+	}
+
+	if err := utils.EliminarEntrada(p.file, &p.sb, &u.padre, u.posPadre, u.nombre); err != nil {
+		p.sincronizarSuperBloque()
+		responderFS(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+	if p.guardarCambios() {
+		responderFS(w, http.StatusOK, fmt.Sprintf("'%s' eliminado", ruta), nil)
+	}
+}
+
+// abrirPeticionFS aplica las mismas reglas de sesión que cat/mkfile y abre el disco de la sesión
+func abrirPeticionFS(w http.ResponseWriter, r *http.Request, escritura bool) (*peticionFS, bool) {
+	if global.SesionActiva == nil {
+		responderFS(w, http.StatusUnauthorized, "No hay sesión activa. Use LOGIN primero", nil)
+		return nil, false
+	}
+
+	id := r.PathValue("id")
+	if !strings.EqualFold(id, global.SesionActiva.IDParticion) {
+		responderFS(w, http.StatusForbidden, fmt.Sprintf("La sesión activa pertenece a la partición '%s', no a '%s'", global.SesionActiva.IDParticion, id), nil)
+		return nil, false
+	}
+
+	disco, err := almacenamiento.Abrir(global.SesionActiva.PathDisco, escritura)
+	if err != nil {
+		responderFS(w, http.StatusInternalServerError, "Error al abrir el disco", nil)
+		return nil, false
+	}
+
+	file := utils.NuevaCache(disco)
+	sb, err := utils.LeerSuperBloque(file, global.SesionActiva.Particion.Part_start)
+	if err != nil {
+		file.Close()
+		responderFS(w, http.StatusInternalServerError, "Error al leer SuperBloque: "+err.Error(), nil)
+		return nil, false
+	}
+
+	p := &peticionFS{w: w, r: r, file: file, sb: sb, sesion: global.SesionActiva}
+	if escritura {
+		p.asignador, err = utils.IniciarAsignacion(file, &p.sb, global.SesionActiva.Particion.Part_fit)
+		if err != nil {
+			file.Close()
+			responderFS(w, http.StatusInternalServerError, err.Error(), nil)
+			return nil, false
+		}
+	}
+	return p, true
+}
+
+// cerrar escribe lo que quede pendiente si la petición terminó por un error y cierra el disco.
+// Las respuestas exitosas ya guardaron todo con guardarCambios.
+func (p *peticionFS) cerrar() {
+	p.file.Close()
+}
+
+// rutaConsulta normaliza una ruta recibida por query o cuerpo a una ruta absoluta limpia
+func rutaConsulta(ruta string) string {
+	ruta = strings.TrimSpace(ruta)
+	if ruta == "" {
+		return ""
+	}
+	return path.Clean("/" + ruta)
+}
+
+func (p *peticionFS) rutaObligatoria(valor string) (string, bool) {
+	ruta := rutaConsulta(valor)
+	if ruta == "" || ruta == "/" {
+		responderFS(p.w, http.StatusBadRequest, "El parámetro 'path' es obligatorio y no puede ser la raíz", nil)
+		return "", false
+	}
+	return ruta, true
+}
+
+func (p *peticionFS) nombreValido(nombre string) bool {
+	if maximo := len(structures.Content{}.B_name); len(nombre) > maximo {
+		responderFS(p.w, http.StatusBadRequest, fmt.Sprintf("El nombre '%s' supera los %d caracteres", nombre, maximo), nil)
+		return false
+	}
+	return true
+}
+
+func (p *peticionFS) resolverExistente(ruta string) (*ubicacionRuta, bool) {
+	u, err := resolverUbicacion(p.file, &p.sb, ruta)
+	if err != nil {
+		responderFS(p.w, http.StatusInternalServerError, err.Error(), nil)
+		return nil, false
+	}
+	if !u.existe {
+		responderFS(p.w, http.StatusNotFound, fmt.Sprintf("'%s' no existe", ruta), nil)
+		return nil, false
+	}
+	return u, true
+}
+
+// sincronizarSuperBloque guarda los contadores aunque la operación haya fallado a medias
+func (p *peticionFS) sincronizarSuperBloque() error {
+	return utils.EscribirSuperBloque(p.file, p.sesion.Particion.Part_start, &p.sb)
+}
+
+// guardarCambios escribe el SuperBloque, los bitmaps y la caché antes de responder; cerrar
+// corre diferido y para entonces la respuesta ya salió, así que un fallo se informa aquí con 500
+func (p *peticionFS) guardarCambios() bool {
+	if err := p.sincronizarSuperBloque(); err != nil {
+		responderFS(p.w, http.StatusInternalServerError, err.Error(), nil)
+		return false
+	}
+	if err := utils.TerminarEscritura(p.file, p.asignador); err != nil {
+		responderFS(p.w, http.StatusInternalServerError, fmt.Sprintf("Error al guardar los cambios en el disco: %v", err), nil)
+		return false
+	}
+	return true
+}
+
+// construirNodo describe la ruta y, si es una carpeta legible, todo su contenido
+func (p *peticionFS) construirNodo(ruta string, inodo *structures.TablaInodo, usuarios map[int32]string, grupos map[int32]string, visitados map[int32]bool) nodoFS {
+	nodo := describirInodo(ruta, inodo, usuarios, grupos)
+	if inodo.I_type[0] != '0' || !utils.TienePermisoLectura(inodo, p.sesion, "") {
+		return nodo
+	}
+
+	entradas, err := utils.ListarCarpeta(p.file, inodo)
+	if err != nil {
+		return nodo
+	}
+	for _, entrada := range entradas {
+		if visitados[entrada.PosInodo] {
+			continue
+		}
+		visitados[entrada.PosInodo] = true
+
+		hijo, err := utils.LeerInodoPorPosicion(p.file, entrada.PosInodo)
+		if err != nil {
+			continue
+		}
+		nodo.Hijos = append(nodo.Hijos, p.construirNodo(path.Join(ruta, entrada.Nombre), &hijo, usuarios, grupos, visitados))
+	}
+	return nodo
+}
+
+func describirInodo(ruta string, inodo *structures.TablaInodo, usuarios map[int32]string, grupos map[int32]string) nodoFS {
+	tipo := "archivo"
+	if inodo.I_type[0] == '0' {
+		tipo = "carpeta"
+	}
+	nombre := path.Base(ruta)
+
+	return nodoFS{
+		Nombre:      nombre,
+		Ruta:        ruta,
+		Tipo:        tipo,
+		Tamanio:     inodo.I_s,
+		Permisos:    fmt.Sprintf("%03o", utils.PermisoOctal(inodo)),
+		UID:         inodo.I_uid,
+		GID:         inodo.I_gid,
+		Propietario: usuarios[inodo.I_uid],
+		Grupo:       grupos[inodo.I_gid],
+		Creado:      utils.IntFechaToStr(inodo.I_ctime),
+		Modificado:  utils.IntFechaToStr(inodo.I_mtime),
+	}
+}
+
+// responderFS envía el ResultadoAPI habitual con el código HTTP correspondiente
+func responderFS(w http.ResponseWriter, status int, mensaje string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(general.ResultadoSalida(mensaje, status >= 400, data))
+}
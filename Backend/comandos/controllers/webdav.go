@@ -0,0 +1,619 @@
+package controllers
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/utils"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// PrefijoWebDAV es la ruta bajo la que se exponen las particiones montadas
+const PrefijoWebDAV = "/dav/"
+
+const metodosWebDAV = "OPTIONS, PROPFIND, GET, HEAD, PUT, MKCOL, DELETE, MOVE"
+
+// peticionDAV agrupa lo necesario para atender una petición sobre una partición
+type peticionDAV struct {
+	w         http.ResponseWriter
+	r         *http.Request
+	file      *utils.CacheDisco // caché sobre el disco; Close baja lo pendiente
+	sb        *structures.SuperBloque
+	asignador *utils.Asignador // nil en los métodos de solo lectura
+	sesion    *global.SesionUsuario
+	id        string
+	inicio    int32
+}
+
+// ubicacionRuta es una ruta resuelta dentro de la partición
+type ubicacionRuta struct {
+	nombre      string
+	padre       structures.TablaInodo
+	posPadre    int32
+	inodo       structures.TablaInodo
+	pos         int32
+	existe      bool
+	existePadre bool
+}
+
+// HandleWebDAV mapea /dav/<id>/... sobre el árbol de la partición montada con ese ID
+func HandleWebDAV(w http.ResponseWriter, r *http.Request) {
+	id, ruta := dividirRutaDAV(r.URL.Path)
+	if id == "" {
+		http.Error(w, "Debe indicar el ID de una partición montada: /dav/<id>/", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("DAV", "1")
+		w.Header().Set("Allow", metodosWebDAV)
+		w.Header().Set("MS-Author-Via", "DAV")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	global.BloqueoDiscos.Lock()
+	defer global.BloqueoDiscos.Unlock()
+
+	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Partición con ID '%s' no encontrada o no montada", id), http.StatusNotFound)
+		return
+	}
+
+	// GET, HEAD y PROPFIND no modifican la partición: el disco se abre solo para lectura
+	escritura := r.Method != "PROPFIND" && r.Method != http.MethodGet && r.Method != http.MethodHead
+
+	disco, err := almacenamiento.Abrir(particionMontada.DiskPath, escritura)
+	if err != nil {
+		http.Error(w, "Error al abrir el disco", http.StatusInternalServerError)
+		return
+	}
+
+	file := utils.NuevaCache(disco)
+	defer file.Close()
+
+	sb, err := utils.LeerSuperBloque(file, particionMontada.Partition.Part_start)
+	if err != nil {
+		http.Error(w, "Partición no formateada o error al leer SuperBloque", http.StatusConflict)
+		return
+	}
+
+	var asignador *utils.Asignador
+	if escritura {
+		// El asignador queda en la caché: Close guarda sus bitmaps salvo que se descarten
+		asignador, err = utils.IniciarAsignacion(file, &sb, particionMontada.Partition.Part_fit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	sesion, ok := autenticarDAV(r, file, &sb)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Basic realm="MIA %s"`, id))
+		http.Error(w, "Usuario o contraseña incorrectos", http.StatusUnauthorized)
+		return
+	}
+	sesion.IDParticion = id
+	sesion.PathDisco = particionMontada.DiskPath
+	sesion.Particion = &particionMontada.Partition
+
+	p := &peticionDAV{
+		w:         w,
+		r:         r,
+		file:      file,
+		sb:        &sb,
+		asignador: asignador,
+		sesion:    sesion,
+		id:        id,
+		inicio:    particionMontada.Partition.Part_start,
+	}
+
+	switch r.Method {
+	case "PROPFIND":
+		p.propfind(ruta)
+	case http.MethodGet, http.MethodHead:
+		p.get(ruta)
+	case http.MethodPut:
+		p.put(ruta)
+	case "MKCOL":
+		p.mkcol(ruta)
+	case http.MethodDelete:
+		p.delete(ruta)
+	case "MOVE":
+		p.move(ruta)
+	default:
+		w.Header().Set("Allow", metodosWebDAV)
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// dividirRutaDAV separa "/dav/191A/a/b" en "191A" y "/a/b"
+func dividirRutaDAV(rutaURL string) (string, string) {
+	resto := strings.TrimPrefix(rutaURL, PrefijoWebDAV)
+	partes := strings.SplitN(resto, "/", 2)
+	id := partes[0]
+	ruta := "/"
+	if len(partes) == 2 {
+		ruta = path.Clean("/" + partes[1])
+	}
+	return id, ruta
+}
+
+// autenticarDAV valida la autenticación básica contra users.txt de la partición
+func autenticarDAV(r *http.Request, file almacenamiento.Disco, sb *structures.SuperBloque) (*global.SesionUsuario, bool) {
+	usuario, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, false
+	}
+
+	inodoUsers, err := utils.LeerInodo(file, sb, 1)
+	if err != nil {
+		return nil, false
+	}
+	contenidoUsers, err := utils.LeerContenidoArchivo(file, sb, &inodoUsers)
+	if err != nil {
+		return nil, false
+	}
+
+	uid, gid, encontrado := utils.ValidarCredenciales(contenidoUsers, usuario, password)
+	if !encontrado {
+		return nil, false
+	}
+
+	return &global.SesionUsuario{UsuarioActual: usuario, UID: uid, GID: gid}, true
+}
+
+func (p *peticionDAV) resolver(ruta string) (*ubicacionRuta, error) {
+	return resolverUbicacion(p.file, p.sb, ruta)
+}
+
+// resolverUbicacion busca la ruta y su carpeta padre; no falla si el último elemento no existe
+func resolverUbicacion(file almacenamiento.Disco, sb *structures.SuperBloque, ruta string) (*ubicacionRuta, error) {
+	u := &ubicacionRuta{}
+
+	if ruta == "/" {
+		raiz, pos, err := utils.LeerInodoDesdeRuta(file, sb, "/")
+		if err != nil {
+			return nil, err
+		}
+		u.inodo, u.pos, u.existe = raiz, pos, true
+		u.padre, u.posPadre, u.existePadre = raiz, pos, true
+		return u, nil
+	}
+
+	u.nombre = path.Base(ruta)
+	padre, posPadre, err := utils.LeerInodoDesdeRuta(file, sb, path.Dir(ruta))
+	if err != nil || padre.I_type[0] != '0' {
+		return u, nil
+	}
+	u.padre, u.posPadre, u.existePadre = padre, posPadre, true
+
+	pos, existe, err := utils.BuscarEnCarpeta(file, sb, &padre, u.nombre)
+	if err != nil {
+		return nil, err
+	}
+	if existe {
+		inodo, err := utils.LeerInodoPorPosicion(file, pos)
+		if err != nil {
+			return nil, err
+		}
+		u.inodo, u.pos, u.existe = inodo, pos, true
+	}
+
+	return u, nil
+}
+
+// sincronizarSuperBloque guarda los contadores aunque la operación haya fallado a medias
+func (p *peticionDAV) sincronizarSuperBloque() error {
+	return utils.EscribirSuperBloque(p.file, p.inicio, p.sb)
+}
+
+// guardarCambios escribe el SuperBloque, los bitmaps y la caché antes de responder: si
+// algo falla el cliente recibe 500 en lugar de creer que la escritura quedó en el disco
+func (p *peticionDAV) guardarCambios() bool {
+	if err := p.sincronizarSuperBloque(); err != nil {
+		http.Error(p.w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if err := utils.TerminarEscritura(p.file, p.asignador); err != nil {
+		http.Error(p.w, fmt.Sprintf("Error al guardar los cambios en el disco: %v", err), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+func (p *peticionDAV) href(ruta string, carpeta bool) string {
+	var partes []string
+	for _, parte := range strings.Split(strings.Trim(ruta, "/"), "/") {
+		if parte != "" {
+			partes = append(partes, url.PathEscape(parte))
+		}
+	}
+	href := PrefijoWebDAV + url.PathEscape(p.id) + "/" + strings.Join(partes, "/")
+	if carpeta && !strings.HasSuffix(href, "/") {
+		href += "/"
+	}
+	return href
+}
+
+type multistatusDAV struct {
+	XMLName    xml.Name       `xml:"D:multistatus"`
+	XmlnsD     string         `xml:"xmlns:D,attr"`
+	Respuestas []respuestaDAV `xml:"D:response"`
+}
+
+type respuestaDAV struct {
+	Href     string      `xml:"D:href"`
+	Propstat propstatDAV `xml:"D:propstat"`
+}
+
+type propstatDAV struct {
+	Prop   propDAV `xml:"D:prop"`
+	Status string  `xml:"D:status"`
+}
+
+type propDAV struct {
+	DisplayName   string         `xml:"D:displayname"`
+	ResourceType  tipoRecursoDAV `xml:"D:resourcetype"`
+	ContentLength *int32         `xml:"D:getcontentlength,omitempty"`
+	ContentType   string         `xml:"D:getcontenttype,omitempty"`
+	LastModified  string         `xml:"D:getlastmodified"`
+	CreationDate  string         `xml:"D:creationdate"`
+	ETag          string         `xml:"D:getetag"`
+}
+
+type tipoRecursoDAV struct {
+	Collection *struct{} `xml:"D:collection,omitempty"`
+}
+
+func (p *peticionDAV) propfind(ruta string) {
+	u, err := p.resolver(ruta)
+	if err != nil {
+		http.Error(p.w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !u.existe {
+		http.Error(p.w, fmt.Sprintf("'%s' no existe", ruta), http.StatusNotFound)
+		return
+	}
+	if !utils.TienePermisoLectura(&u.inodo, p.sesion, u.nombre) {
+		http.Error(p.w, "Sin permisos de lectura", http.StatusForbidden)
+		return
+	}
+
+	// Sin cabecera Depth el estándar indica "infinity"
+	profundidad := -1
+	switch strings.ToLower(strings.TrimSpace(p.r.Header.Get("Depth"))) {
+	case "0":
+		profundidad = 0
+	case "1":
+		profundidad = 1
+	}
+
+	ms := multistatusDAV{XmlnsD: "DAV:"}
+	visitados := map[int32]bool{u.pos: true}
+	p.agregarPropiedades(&ms, ruta, &u.inodo, u.pos, profundidad, visitados)
+
+	p.w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	p.w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(p.w, xml.Header)
+	xml.NewEncoder(p.w).Encode(ms)
+}
+
+func (p *peticionDAV) agregarPropiedades(ms *multistatusDAV, ruta string, inodo *structures.TablaInodo, pos int32, profundidad int, visitados map[int32]bool) {
+	carpeta := inodo.I_type[0] == '0'
+
+	nombre := path.Base(ruta)
+	if ruta == "/" {
+		nombre = p.id
+	}
+
+	prop := propDAV{
+		DisplayName:  nombre,
+		LastModified: time.Unix(int64(inodo.I_mtime), 0).UTC().Format(http.TimeFormat),
+		CreationDate: time.Unix(int64(inodo.I_ctime), 0).UTC().Format(time.RFC3339),
+		ETag:         etagInodo(inodo, pos),
+	}
+	if carpeta {
+		prop.ResourceType.Collection = &struct{}{}
+	} else {
+		tamanio := inodo.I_s
+		prop.ContentLength = &tamanio
+		prop.ContentType = tipoContenido(nombre)
+	}
+
+	ms.Respuestas = append(ms.Respuestas, respuestaDAV{
+		Href:     p.href(ruta, carpeta),
+		Propstat: propstatDAV{Prop: prop, Status: "HTTP/1.1 200 OK"},
+	})
+
+	// Solo se listan carpetas que el usuario puede leer
+	if !carpeta || profundidad == 0 || !utils.TienePermisoLectura(inodo, p.sesion, "") {
+		return
+	}
+
+	entradas, err := utils.ListarCarpeta(p.file, inodo)
+	if err != nil {
+		return
+	}
+	for _, entrada := range entradas {
+		if visitados[entrada.PosInodo] {
+			continue
+		}
+		visitados[entrada.PosInodo] = true
+
+		hijo, err := utils.LeerInodoPorPosicion(p.file, entrada.PosInodo)
+		if err != nil {
+			continue
+		}
+		siguiente := profundidad - 1
+		if profundidad < 0 {
+			siguiente = -1
+		}
+		p.agregarPropiedades(ms, path.Join(ruta, entrada.Nombre), &hijo, entrada.PosInodo, siguiente, visitados)
+	}
+}
+
+func etagInodo(inodo *structures.TablaInodo, pos int32) string {
+	return fmt.Sprintf(`"%x-%x-%x"`, pos, inodo.I_mtime, inodo.I_s)
+}
+
+func tipoContenido(nombre string) string {
+	if tipo := mime.TypeByExtension(path.Ext(nombre)); tipo != "" {
+		return tipo
+	}
+	return "application/octet-stream"
+}
+
+func (p *peticionDAV) get(ruta string) {
+	u, err := p.resolver(ruta)
+	if err != nil {
+		http.Error(p.w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !u.existe {
+		http.Error(p.w, fmt.Sprintf("'%s' no existe", ruta), http.StatusNotFound)
+		return
+	}
+	if u.inodo.I_type[0] == '0' {
+		p.w.Header().Set("Allow", "OPTIONS, PROPFIND, PUT, MKCOL, DELETE, MOVE")
+		http.Error(p.w, "Es una carpeta: use PROPFIND para listar su contenido", http.StatusMethodNotAllowed)
+		return
+	}
+	if !utils.TienePermisoLectura(&u.inodo, p.sesion, u.nombre) {
+		http.Error(p.w, "Sin permisos de lectura", http.StatusForbidden)
+		return
+	}
+
+	contenido, err := utils.LeerContenidoArchivo(p.file, p.sb, &u.inodo)
+	if err != nil {
+		http.Error(p.w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	p.w.Header().Set("Content-Type", tipoContenido(u.nombre))
+	p.w.Header().Set("ETag", etagInodo(&u.inodo, u.pos))
+	http.ServeContent(p.w, p.r, u.nombre, time.Unix(int64(u.inodo.I_mtime), 0), strings.NewReader(contenido))
+}
+
+func (p *peticionDAV) put(ruta string) {
+	u, err := p.resolver(ruta)
+	if err != nil {
+		http.Error(p.w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ruta == "/" || (u.existe && u.inodo.I_type[0] == '0') {
+		http.Error(p.w, "No se puede escribir sobre una carpeta", http.StatusMethodNotAllowed)
+		return
+	}
+	if !u.existePadre {
+		http.Error(p.w, fmt.Sprintf("La carpeta '%s' no existe", path.Dir(ruta)), http.StatusConflict)
+		return
+	}
+	if len(u.nombre) > len(structures.Content{}.B_name) {
+		http.Error(p.w, fmt.Sprintf("El nombre '%s' supera los %d caracteres", u.nombre, len(structures.Content{}.B_name)), http.StatusBadRequest)
+		return
+	}
+
+	maximo := utils.MaxContenidoArchivo(p.sb)
+	cuerpo, err := io.ReadAll(io.LimitReader(p.r.Body, maximo+1))
+	if err != nil {
+		http.Error(p.w, "Error al leer el contenido", http.StatusBadRequest)
+		return
+	}
+	if int64(len(cuerpo)) > maximo {
+		http.Error(p.w, fmt.Sprintf("Un archivo admite como máximo %d bytes", maximo), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if u.existe {
+		if !utils.TienePermisoEscritura(&u.inodo, p.sesion, "") {
+			http.Error(p.w, "Sin permisos de escritura", http.StatusForbidden)
+			return
+		}
+		if err := utils.SobrescribirArchivo(p.file, p.sb, &u.inodo, u.pos, string(cuerpo)); err != nil {
+			http.Error(p.w, err.Error(), http.StatusInsufficientStorage)
+			return
+		}
+		if p.guardarCambios() {
+			p.w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+
+	if !utils.TienePermisoEscritura(&u.padre, p.sesion, "") {
+		http.Error(p.w, fmt.Sprintf("Sin permisos de escritura en '%s'", path.Dir(ruta)), http.StatusForbidden)
+		return
+	}
+	if err := utils.CrearArchivoComo(p.file, p.sb, &u.padre, u.posPadre, u.nombre, string(cuerpo), p.sesion.UID, p.sesion.GID); err != nil {
+		p.sincronizarSuperBloque()
+		http.Error(p.w, err.Error(), http.StatusInsufficientStorage)
+		return
+	}
+	if p.guardarCambios() {
+		p.w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (p *peticionDAV) mkcol(ruta string) {
+	if p.r.ContentLength > 0 {
+		http.Error(p.w, "MKCOL no admite cuerpo", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	u, err := p.resolver(ruta)
+	if err != nil {
+		http.Error(p.w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if u.existe {
+		http.Error(p.w, fmt.Sprintf("'%s' ya existe", ruta), http.StatusMethodNotAllowed)
+		return
+	}
+	if !u.existePadre {
+		http.Error(p.w, fmt.Sprintf("La carpeta '%s' no existe", path.Dir(ruta)), http.StatusConflict)
+		return
+	}
+	if len(u.nombre) > len(structures.Content{}.B_name) {
+		http.Error(p.w, fmt.Sprintf("El nombre '%s' supera los %d caracteres", u.nombre, len(structures.Content{}.B_name)), http.StatusBadRequest)
+		return
+	}
+	if !utils.TienePermisoEscritura(&u.padre, p.sesion, "") {
+		http.Error(p.w, fmt.Sprintf("Sin permisos de escritura en '%s'", path.Dir(ruta)), http.StatusForbidden)
+		return
+	}
+
+	if err := utils.CrearDirectorioComo(p.file, p.sb, &u.padre, u.posPadre, u.nombre, p.sesion.UID, p.sesion.GID); err != nil {
+		p.sincronizarSuperBloque()
+		http.Error(p.w, err.Error(), http.StatusInsufficientStorage)
+		return
+	}
+	if p.guardarCambios() {
+		p.w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (p *peticionDAV) delete(ruta string) {
+	if ruta == "/" {
+		http.Error(p.w, "No se puede eliminar la raíz", http.StatusForbidden)
+		return
+	}
+
+	u, err := p.resolver(ruta)
+	if err != nil {
+		http.Error(p.w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !u.existe {
+		http.Error(p.w, fmt.Sprintf("'%s' no existe", ruta), http.StatusNotFound)
+		return
+	}
+	if !utils.TienePermisoEscritura(&u.padre, p.sesion, "") {
+		http.Error(p.w, fmt.Sprintf("Sin permisos de escritura en '%s'", path.Dir(ruta)), http.StatusForbidden)
+		return
+	}
+
+	if err := utils.EliminarEntrada(p.file, p.sb, &u.padre, u.posPadre, u.nombre); err != nil {
+		p.sincronizarSuperBloque()
+		http.Error(p.w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if p.guardarCambios() {
+		p.w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (p *peticionDAV) move(ruta string) {
+	destinoURL, err := url.Parse(p.r.Header.Get("Destination"))
+	if err != nil || destinoURL.Path == "" {
+		http.Error(p.w, "Cabecera Destination inválida", http.StatusBadRequest)
+		return
+	}
+	idDestino, rutaDestino := dividirRutaDAV(destinoURL.Path)
+	if !strings.HasPrefix(destinoURL.Path, PrefijoWebDAV) || idDestino != p.id {
+		http.Error(p.w, "Solo se puede mover dentro de la misma partición", http.StatusBadGateway)
+		return
+	}
+
+	if ruta == "/" || rutaDestino == "/" {
+		http.Error(p.w, "No se puede mover la raíz", http.StatusForbidden)
+		return
+	}
+	if rutaDestino == ruta || strings.HasPrefix(rutaDestino+"/", ruta+"/") {
+		http.Error(p.w, "El destino no puede ser el origen ni estar dentro de él", http.StatusForbidden)
+		return
+	}
+	if strings.HasPrefix(ruta+"/", rutaDestino+"/") {
+		http.Error(p.w, "El destino no puede contener al origen", http.StatusForbidden)
+		return
+	}
+
+	origen, err := p.resolver(ruta)
+	if err != nil {
+		http.Error(p.w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !origen.existe {
+		http.Error(p.w, fmt.Sprintf("'%s' no existe", ruta), http.StatusNotFound)
+		return
+	}
+
+	destino, err := p.resolver(rutaDestino)
+	if err != nil {
+		http.Error(p.w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !destino.existePadre {
+		http.Error(p.w, fmt.Sprintf("La carpeta '%s' no existe", path.Dir(rutaDestino)), http.StatusConflict)
+		return
+	}
+	if len(destino.nombre) > len(structures.Content{}.B_name) {
+		http.Error(p.w, fmt.Sprintf("El nombre '%s' supera los %d caracteres", destino.nombre, len(structures.Content{}.B_name)), http.StatusBadRequest)
+		return
+	}
+	if !utils.TienePermisoEscritura(&origen.padre, p.sesion, "") || !utils.TienePermisoEscritura(&destino.padre, p.sesion, "") {
+		http.Error(p.w, "Sin permisos de escritura en la carpeta de origen o de destino", http.StatusForbidden)
+		return
+	}
+
+	sobrescribir := !strings.EqualFold(strings.TrimSpace(p.r.Header.Get("Overwrite")), "F")
+	if destino.existe {
+		if !sobrescribir {
+			http.Error(p.w, fmt.Sprintf("'%s' ya existe", rutaDestino), http.StatusPreconditionFailed)
+			return
+		}
+		// Borrar el destino y mover son un solo cambio: si cualquiera falla se descartan
+		// ambos y el disco queda como estaba
+		if err := utils.EliminarEntrada(p.file, p.sb, &destino.padre, destino.posPadre, destino.nombre); err != nil {
+			p.file.Descartar()
+			http.Error(p.w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := utils.MoverEntrada(p.file, p.sb, origen.posPadre, origen.nombre, destino.posPadre, destino.nombre); err != nil {
+		p.file.Descartar()
+		http.Error(p.w, err.Error(), http.StatusInsufficientStorage)
+		return
+	}
+	if !p.guardarCambios() {
+		return
+	}
+
+	if destino.existe {
+		p.w.WriteHeader(http.StatusNoContent)
+	} else {
+		p.w.WriteHeader(http.StatusCreated)
+	}
+}
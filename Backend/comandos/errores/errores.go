@@ -0,0 +1,71 @@
+// Package errores define los errores de los comandos. Cada error lleva un código estable
+// (ERR_NOT_MOUNTED, ERR_NO_SPACE, ...) que viaja hasta el JSON de la API para que el
+// frontend no tenga que interpretar el mensaje en español.
+package errores
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Codigo identifica el tipo de error sin depender del texto del mensaje
+type Codigo string
+
+const (
+	NoMontada          Codigo = "ERR_NOT_MOUNTED"
+	SinEspacio         Codigo = "ERR_NO_SPACE"
+	SinPermiso         Codigo = "ERR_PERMISSION"
+	NoEncontrado       Codigo = "ERR_NOT_FOUND"
+	ParametroInvalido  Codigo = "ERR_INVALID_PARAM"
+	YaExiste           Codigo = "ERR_ALREADY_EXISTS"
+	SinSesion          Codigo = "ERR_NO_SESSION"
+	ComandoDesconocido Codigo = "ERR_UNKNOWN_COMMAND"
+	Sintaxis           Codigo = "ERR_SYNTAX"
+	Interno            Codigo = "ERR_INTERNAL" // lectura/escritura del disco u otro fallo inesperado
+)
+
+// Error es un error de comando con su código
+type Error struct {
+	Codigo  Codigo
+	Mensaje string
+	Causa   error
+}
+
+func (e *Error) Error() string { return e.Mensaje }
+
+func (e *Error) Unwrap() error { return e.Causa }
+
+// Nuevo crea un error con el código y el mensaje indicados
+func Nuevo(codigo Codigo, mensaje string) *Error {
+	return &Error{Codigo: codigo, Mensaje: mensaje}
+}
+
+// Nuevof crea un error con mensaje formateado. Si el formato envuelve con %w un error
+// que ya tiene código, se conserva ese código porque describe mejor la causa: un
+// "Error al crear archivo" provocado por falta de bloques sigue siendo ERR_NO_SPACE.
+func Nuevof(codigo Codigo, formato string, args ...any) *Error {
+	err := fmt.Errorf(formato, args...)
+	causa := errors.Unwrap(err)
+	var tipado *Error
+	if errors.As(causa, &tipado) {
+		codigo = tipado.Codigo
+	}
+	return &Error{Codigo: codigo, Mensaje: err.Error(), Causa: causa}
+}
+
+// CodigoDe devuelve el código del error; los errores sin código son ERR_INTERNAL
+func CodigoDe(err error) Codigo {
+	if err == nil {
+		return ""
+	}
+	var tipado *Error
+	if errors.As(err, &tipado) {
+		return tipado.Codigo
+	}
+	return Interno
+}
+
+// Es indica si el error (o alguna de sus causas) tiene el código indicado
+func Es(err error, codigo Codigo) bool {
+	return err != nil && CodigoDe(err) == codigo
+}
@@ -0,0 +1,275 @@
+// filecomands/export.go
+package filecomands
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/utils"
+	"archive/tar"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// exportador guarda el estado compartido mientras se recorre el árbol
+type exportador struct {
+	file      almacenamiento.Disco
+	sb        *structures.SuperBloque
+	sesion    *global.SesionUsuario // los permisos de lectura se evalúan con este usuario
+	usuarios  map[int32]string
+	grupos    map[int32]string
+	tw        *tar.Writer
+	destino   string
+	visitados map[int32]bool
+	carpetas  int
+	archivos  int
+	bytes     int
+	errores   []string
+	sinLeer   []string // entradas omitidas por falta de permiso de lectura
+}
+
+// ExportExecute maneja el comando export
+func ExportExecute(comando string, parametros map[string]string) (string, interface{}, error) {
+	id := strings.TrimSpace(parametros["id"])
+	if id == "" {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[EXPORT]: Parámetro -id es obligatorio")
+	}
+
+	ruta := strings.TrimSpace(parametros["path"])
+	if ruta == "" {
+		ruta = "/"
+	}
+
+	dest := strings.TrimSpace(parametros["dest"])
+	if dest == "" {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[EXPORT]: Parámetro -dest es obligatorio")
+	}
+
+	formato := strings.ToLower(strings.TrimSpace(parametros["format"]))
+	if formato == "" {
+		formato = "dir"
+	}
+	if formato != "dir" && formato != "tar" {
+		return "", nil, errores.Nuevof(errores.ParametroInvalido, "[EXPORT]: Formato '%s' no soportado (use dir o tar)", formato)
+	}
+
+	return exportarParticion(id, ruta, dest, formato)
+}
+
+func exportarParticion(id string, ruta string, dest string, formato string) (string, interface{}, error) {
+	// Solo se exporta lo que el usuario de la sesión podría leer con cat
+	sesion := global.SesionActiva
+	if sesion == nil {
+		return "", nil, errores.Nuevo(errores.SinSesion, "[EXPORT]: No hay sesión activa. Use LOGIN primero")
+	}
+	if sesion.IDParticion != id {
+		return "", nil, errores.Nuevof(errores.SinSesion, "[EXPORT]: La sesión activa pertenece a la partición '%s', no a '%s'", sesion.IDParticion, id)
+	}
+
+	particionMontada, err := admonDisk.GetMountedPartitionByID(id)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.NoMontada, "[EXPORT]: Partición con ID '%s' no encontrada o no montada", id)
+	}
+
+	disco, err := almacenamiento.Abrir(particionMontada.DiskPath, false)
+	if err != nil {
+		return "", nil, errores.Nuevo(errores.Interno, "[EXPORT]: Error al abrir el disco")
+	}
+
+	file := utils.NuevaCache(disco)
+	defer file.Close()
+
+	sb, errSB := utils.LeerSuperBloque(file, particionMontada.Partition.Part_start)
+	if errSB != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[EXPORT]: Error al leer SuperBloque: %v", errSB)
+	}
+
+	if !strings.HasPrefix(ruta, "/") {
+		ruta = "/" + ruta
+	}
+	ruta = path.Clean(ruta)
+
+	inodo, posInodo, errRuta := utils.LeerInodoDesdeRuta(file, &sb, ruta)
+	if errRuta != nil {
+		if errores.Es(errRuta, errores.NoEncontrado) {
+			return "", nil, errores.Nuevof(errores.NoEncontrado, "[EXPORT]: %w", errRuta)
+		}
+		return "", nil, errores.Nuevof(errores.Interno, "[EXPORT]: %w", errRuta)
+	}
+	if !utils.TienePermisoLectura(&inodo, sesion, "") {
+		return "", nil, errores.Nuevof(errores.SinPermiso, "[EXPORT]: No tiene permisos de lectura sobre '%s'", ruta)
+	}
+
+	usuarios, grupos := utils.LeerUsuariosGrupos(file, &sb)
+	exp := &exportador{
+		file:      file,
+		sb:        &sb,
+		sesion:    sesion,
+		usuarios:  usuarios,
+		grupos:    grupos,
+		destino:   dest,
+		visitados: make(map[int32]bool),
+	}
+
+	if formato == "tar" {
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return "", nil, errores.Nuevof(errores.Interno, "[EXPORT]: No se pudo crear la carpeta de '%s': %w", dest, err)
+		}
+		salidaTar, err := os.Create(dest)
+		if err != nil {
+			return "", nil, errores.Nuevof(errores.Interno, "[EXPORT]: No se pudo crear '%s': %w", dest, err)
+		}
+		defer salidaTar.Close()
+		exp.tw = tar.NewWriter(salidaTar)
+	} else if err := os.MkdirAll(dest, 0755); err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[EXPORT]: No se pudo crear '%s': %w", dest, err)
+	}
+
+	if inodo.I_type[0] == '0' {
+		exp.visitados[posInodo] = true
+		exp.exportarCarpeta(&inodo, "")
+	} else {
+		exp.exportarArchivo(&inodo, path.Base(ruta))
+	}
+
+	if exp.tw != nil {
+		if err := exp.tw.Close(); err != nil {
+			return "", nil, errores.Nuevof(errores.Interno, "[EXPORT]: Error al cerrar el archivo tar: %w", err)
+		}
+	}
+
+	detalles := fmt.Sprintf(`  ID:             %s
+  Ruta:           %s
+  Destino:        %s
+  Formato:        %s
+  Carpetas:       %d
+  Archivos:       %d (%d bytes)
+  Sin permiso:    %d
+  Errores:        %d`,
+		id, ruta, dest, formato, exp.carpetas, exp.archivos, exp.bytes, len(exp.sinLeer), len(exp.errores))
+
+	var salida strings.Builder
+	salida.WriteString(utils.SuccessBanner("EXPORTACIÓN FINALIZADA", detalles))
+	for _, e := range exp.sinLeer {
+		salida.WriteString("\n  • " + e + ": sin permiso de lectura")
+	}
+	for _, e := range exp.errores {
+		salida.WriteString("\n  • " + e)
+	}
+
+	color.Green(salida.String())
+	return salida.String(), nil, nil
+}
+
+// exportarCarpeta escribe las entradas de una carpeta; relativa es la ruta dentro del destino
+func (exp *exportador) exportarCarpeta(inodo *structures.TablaInodo, relativa string) {
+	entradas, err := utils.ListarCarpeta(exp.file, inodo)
+	if err != nil {
+		exp.errores = append(exp.errores, fmt.Sprintf("%s: %v", relativa, err))
+		return
+	}
+
+	for _, entrada := range entradas {
+		if exp.visitados[entrada.PosInodo] {
+			continue // evita ciclos en carpetas dañadas
+		}
+		exp.visitados[entrada.PosInodo] = true
+
+		hijo, err := utils.LeerInodoPorPosicion(exp.file, entrada.PosInodo)
+		if err != nil {
+			exp.errores = append(exp.errores, fmt.Sprintf("%s: %v", path.Join(relativa, entrada.Nombre), err))
+			continue
+		}
+
+		rutaHijo := path.Join(relativa, entrada.Nombre)
+		if !utils.TienePermisoLectura(&hijo, exp.sesion, entrada.Nombre) {
+			exp.sinLeer = append(exp.sinLeer, rutaHijo)
+			continue
+		}
+		if hijo.I_type[0] == '0' {
+			if err := exp.escribirCarpeta(&hijo, rutaHijo); err != nil {
+				exp.errores = append(exp.errores, fmt.Sprintf("%s: %v", rutaHijo, err))
+				continue
+			}
+			exp.carpetas++
+			exp.exportarCarpeta(&hijo, rutaHijo)
+			if err := exp.fijarFechas(&hijo, rutaHijo); err != nil {
+				exp.errores = append(exp.errores, fmt.Sprintf("%s: %v", rutaHijo, err))
+			}
+		} else {
+			exp.exportarArchivo(&hijo, rutaHijo)
+		}
+	}
+}
+
+func (exp *exportador) exportarArchivo(inodo *structures.TablaInodo, relativa string) {
+	contenido, err := utils.LeerContenidoArchivo(exp.file, exp.sb, inodo)
+	if err != nil {
+		exp.errores = append(exp.errores, fmt.Sprintf("%s: %v", relativa, err))
+		return
+	}
+
+	if exp.tw != nil {
+		header := exp.crearHeader(inodo, relativa, tar.TypeReg)
+		header.Size = int64(len(contenido))
+		if err := exp.tw.WriteHeader(header); err != nil {
+			exp.errores = append(exp.errores, fmt.Sprintf("%s: %v", relativa, err))
+			return
+		}
+		if _, err := exp.tw.Write([]byte(contenido)); err != nil {
+			exp.errores = append(exp.errores, fmt.Sprintf("%s: %v", relativa, err))
+			return
+		}
+	} else {
+		destino := filepath.Join(exp.destino, filepath.FromSlash(relativa))
+		if err := os.WriteFile(destino, []byte(contenido), os.FileMode(utils.PermisoOctal(inodo))); err != nil {
+			exp.errores = append(exp.errores, fmt.Sprintf("%s: %v", relativa, err))
+			return
+		}
+		if err := exp.fijarFechas(inodo, relativa); err != nil {
+			exp.errores = append(exp.errores, fmt.Sprintf("%s: %v", relativa, err))
+		}
+	}
+
+	exp.archivos++
+	exp.bytes += len(contenido)
+}
+
+func (exp *exportador) escribirCarpeta(inodo *structures.TablaInodo, relativa string) error {
+	if exp.tw != nil {
+		return exp.tw.WriteHeader(exp.crearHeader(inodo, relativa+"/", tar.TypeDir))
+	}
+	// Las carpetas necesitan permiso de ejecución en el host para poder recorrerse
+	modo := os.FileMode(utils.PermisoOctal(inodo)) | 0700
+	return os.MkdirAll(filepath.Join(exp.destino, filepath.FromSlash(relativa)), modo)
+}
+
+// crearHeader traslada dueño, grupo, I_perm y fechas del inodo al header tar
+func (exp *exportador) crearHeader(inodo *structures.TablaInodo, nombre string, tipo byte) *tar.Header {
+	return &tar.Header{
+		Typeflag:   tipo,
+		Name:       nombre,
+		Mode:       utils.PermisoOctal(inodo),
+		Uid:        int(inodo.I_uid),
+		Gid:        int(inodo.I_gid),
+		Uname:      exp.usuarios[inodo.I_uid],
+		Gname:      exp.grupos[inodo.I_gid],
+		ModTime:    time.Unix(int64(inodo.I_mtime), 0),
+		AccessTime: time.Unix(int64(inodo.I_atime), 0),
+		ChangeTime: time.Unix(int64(inodo.I_ctime), 0),
+		Format:     tar.FormatPAX,
+	}
+}
+
+func (exp *exportador) fijarFechas(inodo *structures.TablaInodo, relativa string) error {
+	destino := filepath.Join(exp.destino, filepath.FromSlash(relativa))
+	return os.Chtimes(destino, time.Unix(int64(inodo.I_atime), 0), time.Unix(int64(inodo.I_mtime), 0))
+}
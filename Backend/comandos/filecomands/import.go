@@ -0,0 +1,284 @@
+// filecomands/import.go
+package filecomands
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/utils"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// resumenImport acumula lo importado y lo que no se pudo importar
+type resumenImport struct {
+	carpetas   int
+	archivos   int
+	bytes      int
+	omitidos   []string
+	noCupieron []string
+	fallidos   []string
+	codigo     errores.Codigo // código del primer fallo que no es falta de espacio
+}
+
+func (r *resumenImport) omitir(ruta string, motivo string) {
+	r.omitidos = append(r.omitidos, fmt.Sprintf("%s: %s", ruta, motivo))
+}
+
+func (r *resumenImport) noCupo(ruta string, motivo string) {
+	r.noCupieron = append(r.noCupieron, fmt.Sprintf("%s: %s", ruta, motivo))
+}
+
+// fallo registra un error al crear un elemento conservando su código: solo
+// ERR_NO_SPACE cuenta como "no cupo"
+func (r *resumenImport) fallo(ruta string, err error) {
+	if errores.Es(err, errores.SinEspacio) {
+		r.noCupo(ruta, err.Error())
+		return
+	}
+	codigo := errores.CodigoDe(err)
+	if r.codigo == "" {
+		r.codigo = codigo
+	}
+	r.fallidos = append(r.fallidos, fmt.Sprintf("%s: [%s] %v", ruta, codigo, err))
+}
+
+// ImportExecute maneja el comando import
+func ImportExecute(comando string, parametros map[string]string) (string, interface{}, error) {
+	// Verificar sesión activa
+	if global.SesionActiva == nil {
+		return "", nil, errores.Nuevo(errores.SinSesion, "[IMPORT]: No hay sesión activa. Use LOGIN primero")
+	}
+
+	src := strings.TrimSpace(parametros["src"])
+	if src == "" {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[IMPORT]: Parámetro -src es obligatorio")
+	}
+
+	dest := strings.TrimSpace(parametros["dest"])
+	if dest == "" {
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[IMPORT]: Parámetro -dest es obligatorio")
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[IMPORT]: No se pudo acceder a '%s': %w", src, err)
+	}
+	if !info.IsDir() {
+		return "", nil, errores.Nuevof(errores.ParametroInvalido, "[IMPORT]: '%s' no es un directorio", src)
+	}
+
+	return importarDirectorioHost(src, dest, global.SesionActiva)
+}
+
+func importarDirectorioHost(src string, dest string, sesion *global.SesionUsuario) (string, interface{}, error) {
+	// Abrir el disco
+	disco, err := almacenamiento.Abrir(sesion.PathDisco, true)
+	if err != nil {
+		return "", nil, errores.Nuevo(errores.Interno, "[IMPORT]: Error al abrir el disco")
+	}
+
+	// Las estructuras se leen y modifican en memoria; Close las escribe al disco
+	file := utils.NuevaCache(disco)
+	defer file.Close()
+
+	// Leer SuperBloque
+	sb, errSB := utils.LeerSuperBloque(file, sesion.Particion.Part_start)
+	if errSB != nil {
+		return "", nil, errores.Nuevo(errores.Interno, "[IMPORT]: Error al leer SuperBloque")
+	}
+
+	// Los bitmaps se cargan una vez y se escriben juntos al terminar el comando
+	asignador, errAsig := utils.IniciarAsignacion(file, &sb, sesion.Particion.Part_fit)
+	if errAsig != nil {
+		return "", nil, errores.Nuevo(errores.Interno, "[IMPORT]: Error al leer los bitmaps")
+	}
+	defer asignador.Terminar()
+
+	ruta := strings.TrimSpace(dest)
+	if !strings.HasPrefix(ruta, "/") {
+		ruta = "/" + ruta
+	}
+	ruta = path.Clean(ruta)
+
+	// Si el destino no existe se crea igual que mkdir -p
+	if _, _, errDest := utils.LeerInodoDesdeRuta(file, &sb, ruta); errDest != nil {
+		partes := strings.Split(strings.Trim(ruta, "/"), "/")
+		if errRec := utils.CrearDirectoriosRecursivos(file, &sb, partes, 0, sb.S_inode_start, sesion); errRec != nil {
+			return "", nil, errores.Nuevof(errores.Interno, "[IMPORT]: No se pudo crear el destino '%s': %w", ruta, errRec)
+		}
+	}
+
+	inodoDestino, posDestino, errDest := utils.LeerInodoDesdeRuta(file, &sb, ruta)
+	if errDest != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[IMPORT]: Error al acceder al destino '%s': %w", ruta, errDest)
+	}
+	if inodoDestino.I_type[0] != '0' {
+		return "", nil, errores.Nuevof(errores.ParametroInvalido, "[IMPORT]: El destino '%s' no es una carpeta", ruta)
+	}
+
+	resumen := &resumenImport{}
+	importarCarpeta(file, &sb, src, posDestino, ruta, sesion, resumen)
+
+	// Escribir SuperBloque actualizado
+	if err := utils.EscribirEstructura(file, sesion.Particion.Part_start, &sb); err != nil {
+		return "", nil, errores.Nuevo(errores.Interno, "[IMPORT]: Error al escribir SuperBloque actualizado")
+	}
+	if err := utils.TerminarEscritura(file, asignador); err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[IMPORT]: Error al guardar los cambios en el disco: %w", err)
+	}
+
+	detalles := fmt.Sprintf(`  Origen:         %s
+  Destino:        %s
+  Carpetas:       %d
+  Archivos:       %d (%d bytes)
+  Omitidos:       %d
+  No cupieron:    %d
+  Con errores:    %d`,
+		src, ruta, resumen.carpetas, resumen.archivos, resumen.bytes, len(resumen.omitidos), len(resumen.noCupieron), len(resumen.fallidos))
+
+	var salida strings.Builder
+	salida.WriteString(utils.SuccessBanner("IMPORTACIÓN FINALIZADA", detalles))
+	if len(resumen.noCupieron) > 0 {
+		salida.WriteString("\nElementos que no cupieron en la partición:\n")
+		for _, item := range resumen.noCupieron {
+			salida.WriteString("  • " + item + "\n")
+		}
+	}
+	if len(resumen.omitidos) > 0 {
+		salida.WriteString("\nElementos omitidos:\n")
+		for _, item := range resumen.omitidos {
+			salida.WriteString("  • " + item + "\n")
+		}
+	}
+	if len(resumen.fallidos) > 0 {
+		salida.WriteString("\nElementos que fallaron:\n")
+		for _, item := range resumen.fallidos {
+			salida.WriteString("  • " + item + "\n")
+		}
+
+		// Lo importado ya quedó guardado, pero el comando no terminó bien: se informa con
+		// el código del primer fallo
+		msg := fmt.Sprintf("[IMPORT]: %d elementos no se pudieron importar\n%s", len(resumen.fallidos), strings.TrimRight(salida.String(), "\n"))
+		color.Red(msg)
+		return "", nil, errores.Nuevo(resumen.codigo, msg)
+	}
+
+	color.Green(salida.String())
+	return strings.TrimRight(salida.String(), "\n"), nil, nil
+}
+
+// importarCarpeta recorre un directorio del host y replica su contenido bajo posCarpeta
+func importarCarpeta(file almacenamiento.Disco, sb *structures.SuperBloque, dirHost string, posCarpeta int32, rutaCarpeta string, sesion *global.SesionUsuario, resumen *resumenImport) {
+	entradas, err := os.ReadDir(dirHost)
+	if err != nil {
+		resumen.omitir(rutaCarpeta, fmt.Sprintf("no se pudo leer '%s': %v", dirHost, err))
+		return
+	}
+
+	maxNombre := len(structures.Content{}.B_name)
+	maxContenido := utils.MaxContenidoArchivo(sb)
+
+	for _, entrada := range entradas {
+		nombre := entrada.Name()
+		pathHost := filepath.Join(dirHost, nombre)
+		rutaHijo := path.Join(rutaCarpeta, nombre)
+
+		info, err := os.Lstat(pathHost)
+		if err != nil {
+			resumen.omitir(rutaHijo, err.Error())
+			continue
+		}
+
+		modo := info.Mode()
+		if modo&os.ModeSymlink != 0 {
+			resumen.omitir(rutaHijo, "enlace simbólico")
+			continue
+		}
+		if !modo.IsDir() && !modo.IsRegular() {
+			resumen.omitir(rutaHijo, "archivo especial")
+			continue
+		}
+
+		// Un nombre que no cabe en B_name se omite sin detener la importación del resto
+		if len(nombre) > maxNombre {
+			resumen.omitir(rutaHijo, fmt.Sprintf("el nombre tiene %d bytes y B_name admite %d", len(nombre), maxNombre))
+			continue
+		}
+
+		// Releer el padre en cada vuelta: crear entradas modifica sus bloques
+		inodoPadre, err := utils.LeerInodoPorPosicion(file, posCarpeta)
+		if err != nil {
+			resumen.omitir(rutaHijo, fmt.Sprintf("error al leer carpeta padre: %v", err))
+			return
+		}
+
+		if !utils.TienePermisoEscritura(&inodoPadre, sesion, "") {
+			resumen.omitir(rutaHijo, fmt.Sprintf("sin permisos de escritura en '%s'", rutaCarpeta))
+			continue
+		}
+
+		posExistente, existe, err := utils.BuscarEnCarpeta(file, sb, &inodoPadre, nombre)
+		if err != nil {
+			resumen.omitir(rutaHijo, err.Error())
+			continue
+		}
+
+		if modo.IsDir() {
+			if existe {
+				inodoExistente, err := utils.LeerInodoPorPosicion(file, posExistente)
+				if err != nil || inodoExistente.I_type[0] != '0' {
+					resumen.omitir(rutaHijo, "ya existe un archivo con ese nombre")
+					continue
+				}
+				importarCarpeta(file, sb, pathHost, posExistente, rutaHijo, sesion, resumen)
+				continue
+			}
+
+			if err := utils.CrearDirectorio(file, sb, &inodoPadre, posCarpeta, nombre, sesion); err != nil {
+				resumen.fallo(rutaHijo, err)
+				continue
+			}
+
+			posNueva, encontrado, err := utils.BuscarEnCarpeta(file, sb, &inodoPadre, nombre)
+			if err != nil || !encontrado {
+				resumen.omitir(rutaHijo, "no se encontró la carpeta recién creada")
+				continue
+			}
+
+			resumen.carpetas++
+			importarCarpeta(file, sb, pathHost, posNueva, rutaHijo, sesion, resumen)
+			continue
+		}
+
+		if existe {
+			resumen.omitir(rutaHijo, "ya existe en la partición")
+			continue
+		}
+
+		if info.Size() > maxContenido {
+			resumen.noCupo(rutaHijo, fmt.Sprintf("ocupa %d bytes y un archivo admite %d", info.Size(), maxContenido))
+			continue
+		}
+
+		contenido, err := os.ReadFile(pathHost)
+		if err != nil {
+			resumen.omitir(rutaHijo, err.Error())
+			continue
+		}
+
+		if err := utils.CrearArchivo(file, sb, &inodoPadre, posCarpeta, nombre, string(contenido), sesion); err != nil {
+			resumen.fallo(rutaHijo, err)
+			continue
+		}
+
+		resumen.archivos++
+		resumen.bytes += len(contenido)
+	}
+}
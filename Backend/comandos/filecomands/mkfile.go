@@ -1,28 +1,26 @@
 package filecomands
 
 import (
+	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/errores"
 	"Proyecto/comandos/global"
 	"Proyecto/comandos/utils"
-	"encoding/binary"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
-
-	"github.com/fatih/color"
 )
 
 // MkfileExecute maneja el comando mkfile
-func MkfileExecute(comando string, parametros map[string]string) (string, bool) {
+func MkfileExecute(comando string, parametros map[string]string) (string, interface{}, error) {
 	// Verificar sesión activa
 	if global.SesionActiva == nil {
-		return "[MKFILE]: No hay sesión activa. Use LOGIN primero", true
+		return "", nil, errores.Nuevo(errores.SinSesion, "[MKFILE]: No hay sesión activa. Use LOGIN primero")
 	}
 
 	// Validar parámetros obligatorios
 	path := strings.TrimSpace(parametros["path"])
 	if path == "" {
-		return "[MKFILE]: Parámetro -path es obligatorio", true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKFILE]: Parámetro -path es obligatorio")
 	}
 
 	// Parámetros opcionales
@@ -35,10 +33,10 @@ func MkfileExecute(comando string, parametros map[string]string) (string, bool)
 		// Intentar convertir el tamaño a int32
 		sizeInt, err := strconv.Atoi(sizeParam)
 		if err != nil {
-			return "[MKFILE]: Parámetro -size debe ser un número entero", true
+			return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKFILE]: Parámetro -size debe ser un número entero")
 		}
 		if sizeInt < 0 {
-			return "[MKFILE]: Parámetro -size no puede ser negativo", true
+			return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKFILE]: Parámetro -size no puede ser negativo")
 		}
 		sizeValue = int32(sizeInt)
 		sizeProvided = true
@@ -46,98 +44,31 @@ func MkfileExecute(comando string, parametros map[string]string) (string, bool)
 
 	// Verificar que no se proporcionen cont y size simultáneamente
 	if content != "" && sizeProvided {
-		return "[MKFILE]: No se puede especificar -cont y -size al mismo tiempo", true
-	}
-
-	return crearArchivo(path, content, sizeValue, sizeProvided)
-}
-
-func crearArchivo(path string, content string, sizeValue int32, sizeProvided bool) (string, bool) {
-	// Abrir el disco
-	file, err := os.OpenFile(global.SesionActiva.PathDisco, os.O_RDWR, 0666)
-	if err != nil {
-		return "[MKFILE]: Error al abrir el disco", true
-	}
-	defer file.Close()
-
-	// Leer SuperBloque
-	sb, errSB := utils.LeerSuperBloque(file, global.SesionActiva.Particion.Part_start)
-	if errSB != nil {
-		return "[MKFILE]: Error al leer SuperBloque", true
-	}
-
-	// Dividir la ruta para obtener el nombre del archivo y la ruta del directorio padre
-	ruta := strings.TrimSpace(path)
-	if !strings.HasPrefix(ruta, "/") {
-		ruta = "/" + ruta
-	}
-	partes := strings.Split(strings.Trim(ruta, "/"), "/")
-	if len(partes) == 0 || (len(partes) == 1 && partes[0] == "") {
-		return "[MKFILE]: Ruta inválida para archivo", true
-	}
-
-	nombreArchivo := partes[len(partes)-1]
-	rutaDirectorioPadre := "/" + strings.Join(partes[:len(partes)-1], "/")
-
-	if rutaDirectorioPadre == "/" {
-		rutaDirectorioPadre = "/" // Asegurar que sea la raíz
-	}
-
-	// Obtener el inodo del directorio padre
-	inodoPadre, _, errDir := utils.LeerInodoDesdeRuta(file, &sb, rutaDirectorioPadre)
-	if errDir != nil {
-		return fmt.Sprintf("[MKFILE]: Error al acceder al directorio padre '%s': %v", rutaDirectorioPadre, errDir), true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKFILE]: No se puede especificar -cont y -size al mismo tiempo")
 	}
 
-	// Verificar permisos de escritura en el directorio padre
-	if !utils.TienePermisoEscritura(&inodoPadre, global.SesionActiva, "") { // Nombre del archivo no es relevante para permiso de escritura en el padre
-		return fmt.Sprintf("[MKFILE]: No tiene permisos de escritura en el directorio '%s'", rutaDirectorioPadre), true
-	}
+	// Con -r se crean las carpetas padre que falten
+	crearPadres := strings.TrimSpace(parametros["r"]) != ""
 
-	// Verificar si el archivo ya existe en el directorio padre
-	_, existe, errBusqueda := utils.BuscarEnCarpeta(file, &sb, &inodoPadre, nombreArchivo)
-	if errBusqueda != nil {
-		return fmt.Sprintf("[MKFILE]: Error buscando archivo en directorio '%s': %v", rutaDirectorioPadre, errBusqueda), true
-	}
-	if existe {
-		return fmt.Sprintf("[MKFILE]: El archivo '%s' ya existe en '%s'", nombreArchivo, rutaDirectorioPadre), true
-	}
+	return crearArchivo(path, content, sizeValue, sizeProvided, crearPadres)
+}
 
-	// Determinar el contenido del archivo
-	var contenidoFinal string
-	if content != "" {
-		contenidoFinal = content
-	} else if sizeProvided {
-		// Generar contenido basado en size
+func crearArchivo(path string, content string, sizeValue int32, sizeProvided bool, crearPadres bool) (string, interface{}, error) {
+	// Determinar el contenido del archivo; sin -cont ni -size se crea vacío
+	contenidoFinal := content
+	if content == "" && sizeProvided {
 		contenidoFinal = generarContenido(sizeValue)
-	} else {
-		// Si no se proporciona ni cont ni size, crear archivo vacío
-		contenidoFinal = ""
-	}
-
-	// Crear el nuevo archivo
-	errCrear := utils.CrearArchivo(file, &sb, &inodoPadre, nombreArchivo, contenidoFinal)
-	if errCrear != nil {
-		return fmt.Sprintf("[MKFILE]: Error al crear archivo '%s': %v", nombreArchivo, errCrear), true
 	}
 
-	// Actualizar SuperBloque (ya se actualiza dentro de CrearArchivo)
-	// Escribir SuperBloque actualizado
-	if _, err := file.Seek(int64(global.SesionActiva.Particion.Part_start), 0); err != nil {
-		return "[MKFILE]: Error al posicionar puntero para escribir SuperBloque", true
-	}
-	if err := binary.Write(file, binary.LittleEndian, &sb); err != nil {
-		return "[MKFILE]: Error al escribir SuperBloque actualizado", true
+	res, err := admonDisk.MotorComandos(global.SesionActiva).EscribirArchivo(path, []byte(contenidoFinal), false, crearPadres)
+	if err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[MKFILE]: %w", err)
 	}
 
-	color.Green("===========================================================")
-	color.Green(" ARCHIVO CREADO EXITOSAMENTE")
-	color.Green("===========================================================")
-	color.Cyan("  Ruta:           %s", ruta)
-	color.Cyan("  Tamaño:         %d bytes", len(contenidoFinal))
-	color.Green("===========================================================")
+	salida := utils.SuccessBanner("ARCHIVO CREADO EXITOSAMENTE",
+		fmt.Sprintf("  Ruta:           %s\n  Tamaño:         %d bytes", res.Ruta, res.Tamanio))
 
-	return "", false
+	return salida, map[string]interface{}{"ruta": res.Ruta, "tamanio": res.Tamanio}, nil
 }
 
 // generarContenido genera contenido basado en el tamaño especificado
@@ -2,63 +2,74 @@
 package filecomands
 
 import (
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
 	"Proyecto/comandos/global"
 	"Proyecto/comandos/utils"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
 )
 
-func MkgrpExecute(comando string, parametros map[string]string) (string, bool) {
+func MkgrpExecute(comando string, parametros map[string]string) (string, interface{}, error) {
 	// Verificar sesión activa
 	if global.SesionActiva == nil {
-		return "[MKGRP]: No hay sesión activa. Use LOGIN primero", true
+		return "", nil, errores.Nuevo(errores.SinSesion, "[MKGRP]: No hay sesión activa. Use LOGIN primero")
 	}
 
 	// Solo root puede crear grupos
 	if global.SesionActiva.UsuarioActual != "root" {
-		return "[MKGRP]: Solo el usuario root puede crear grupos", true
+		return "", nil, errores.Nuevo(errores.SinPermiso, "[MKGRP]: Solo el usuario root puede crear grupos")
 	}
 
 	// Validar parámetro name
 	nombreGrupo := strings.TrimSpace(parametros["name"])
 	if nombreGrupo == "" {
-		return "[MKGRP]: Parámetro -name es obligatorio", true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKGRP]: Parámetro -name es obligatorio")
 	}
 
 	if len(nombreGrupo) > 10 {
-		return "[MKGRP]: El nombre del grupo no puede exceder 10 caracteres", true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKGRP]: El nombre del grupo no puede exceder 10 caracteres")
 	}
 
 	return crearGrupo(nombreGrupo)
 }
 
-func crearGrupo(nombreGrupo string) (string, bool) {
+func crearGrupo(nombreGrupo string) (string, interface{}, error) {
 	// Abrir el disco
-	file, err := os.OpenFile(global.SesionActiva.PathDisco, os.O_RDWR, 0666)
+	disco, err := almacenamiento.Abrir(global.SesionActiva.PathDisco, true)
 	if err != nil {
-		return "[MKGRP]: Error al abrir el disco", true
+		return "", nil, errores.Nuevo(errores.Interno, "[MKGRP]: Error al abrir el disco")
 	}
+
+	// Las estructuras se leen y modifican en memoria; Close las escribe al disco
+	file := utils.NuevaCache(disco)
 	defer file.Close()
 
 	// Leer SuperBloque
 	sb, errSB := utils.LeerSuperBloque(file, global.SesionActiva.Particion.Part_start)
 	if errSB != nil {
-		return "[MKGRP]: Error al leer SuperBloque", true
+		return "", nil, errores.Nuevo(errores.Interno, "[MKGRP]: Error al leer SuperBloque")
+	}
+
+	// Los bitmaps se cargan una vez y se escriben juntos al terminar el comando
+	asignador, errAsig := utils.IniciarAsignacion(file, &sb, global.SesionActiva.Particion.Part_fit)
+	if errAsig != nil {
+		return "", nil, errores.Nuevo(errores.Interno, "[MKGRP]: Error al leer los bitmaps")
 	}
+	defer asignador.Terminar()
 
 	// Leer contenido actual de users.txt
-	contenidoActual, errRead := utils.LeerArchivoDesdeRuta(file, &sb, "/users.txt")
+	contenidoActual, errRead := utils.LeerArchivoDesdeRuta(file, &sb, "/users.txt", global.SesionActiva)
 	if errRead != nil {
-		return "[MKGRP]: Error al leer users.txt", true
+		return "", nil, errores.Nuevo(errores.Interno, "[MKGRP]: Error al leer users.txt")
 	}
 
 	// Verificar que el grupo no exista
 	if ExisteGrupo(contenidoActual, nombreGrupo) {
-		return fmt.Sprintf("[MKGRP]: El grupo '%s' ya existe", nombreGrupo), true
+		return "", nil, errores.Nuevof(errores.YaExiste, "[MKGRP]: El grupo '%s' ya existe", nombreGrupo)
 	}
 
 	// Calcular nuevo GID
@@ -70,7 +81,10 @@ func crearGrupo(nombreGrupo string) (string, bool) {
 
 	// Escribir el nuevo contenido usando la función de utils
 	if err := utils.EscribirArchivoUsersText(file, &sb, nuevoContenido); err != nil {
-		return "[MKGRP]: Error al escribir en users.txt: " + err.Error(), true
+		return "", nil, errores.Nuevof(errores.Interno, "[MKGRP]: Error al escribir en users.txt: %w", err)
+	}
+	if err := utils.TerminarEscritura(file, asignador); err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[MKGRP]: Error al guardar los cambios en el disco: %w", err)
 	}
 
 	detalles := fmt.Sprintf(`  Nombre:         %s
@@ -84,7 +98,7 @@ func crearGrupo(nombreGrupo string) (string, bool) {
 	color.Cyan("  GID:            %d", nuevoGID)
 	color.Green("===========================================================")
 
-	return salida, false
+	return salida, nil, nil
 }
 
 // ExisteGrupo verifica si un grupo ya existe
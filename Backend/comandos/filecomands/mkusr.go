@@ -2,10 +2,11 @@
 package filecomands
 
 import (
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
 	"Proyecto/comandos/global"
 	"Proyecto/comandos/utils"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 
@@ -13,77 +14,87 @@ import (
 )
 
 // MkusrExecute maneja el comando mkusr
-func MkusrExecute(comando string, parametros map[string]string) (string, bool) {
+func MkusrExecute(comando string, parametros map[string]string) (string, interface{}, error) {
 	// Verificar sesión activa
 	if global.SesionActiva == nil {
-		return "[MKUSR]: No hay sesión activa. Use LOGIN primero", true
+		return "", nil, errores.Nuevo(errores.SinSesion, "[MKUSR]: No hay sesión activa. Use LOGIN primero")
 	}
 
 	// Solo root puede crear usuarios
 	if global.SesionActiva.UsuarioActual != "root" {
-		return "[MKUSR]: Solo el usuario root puede crear usuarios", true
+		return "", nil, errores.Nuevo(errores.SinPermiso, "[MKUSR]: Solo el usuario root puede crear usuarios")
 	}
 
 	// Validar parámetros
 	nombreUsuario := strings.TrimSpace(parametros["user"])
 	if nombreUsuario == "" {
-		return "[MKUSR]: Parámetro -user es obligatorio", true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKUSR]: Parámetro -user es obligatorio")
 	}
 
 	password := strings.TrimSpace(parametros["pass"])
 	if password == "" {
-		return "[MKUSR]: Parámetro -pass es obligatorio", true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKUSR]: Parámetro -pass es obligatorio")
 	}
 
 	grupo := strings.TrimSpace(parametros["grp"])
 	if grupo == "" {
-		return "[MKUSR]: Parámetro -grp es obligatorio", true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKUSR]: Parámetro -grp es obligatorio")
 	}
 
 	// Validar longitudes
 	if len(nombreUsuario) > 10 {
-		return "[MKUSR]: El nombre de usuario no puede exceder 10 caracteres", true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKUSR]: El nombre de usuario no puede exceder 10 caracteres")
 	}
 
 	if len(password) > 10 {
-		return "[MKUSR]: La contraseña no puede exceder 10 caracteres", true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKUSR]: La contraseña no puede exceder 10 caracteres")
 	}
 
 	if len(grupo) > 10 {
-		return "[MKUSR]: El nombre del grupo no puede exceder 10 caracteres", true
+		return "", nil, errores.Nuevo(errores.ParametroInvalido, "[MKUSR]: El nombre del grupo no puede exceder 10 caracteres")
 	}
 
 	return crearUsuario(nombreUsuario, password, grupo)
 }
 
-func crearUsuario(nombreUsuario string, password string, grupo string) (string, bool) {
+func crearUsuario(nombreUsuario string, password string, grupo string) (string, interface{}, error) {
 	// Abrir el disco
-	file, err := os.OpenFile(global.SesionActiva.PathDisco, os.O_RDWR, 0666)
+	disco, err := almacenamiento.Abrir(global.SesionActiva.PathDisco, true)
 	if err != nil {
-		return "[MKUSR]: Error al abrir el disco", true
+		return "", nil, errores.Nuevo(errores.Interno, "[MKUSR]: Error al abrir el disco")
 	}
+
+	// Las estructuras se leen y modifican en memoria; Close las escribe al disco
+	file := utils.NuevaCache(disco)
 	defer file.Close()
 
 	// Leer SuperBloque
 	sb, errSB := utils.LeerSuperBloque(file, global.SesionActiva.Particion.Part_start)
 	if errSB != nil {
-		return "[MKUSR]: Error al leer SuperBloque", true
+		return "", nil, errores.Nuevo(errores.Interno, "[MKUSR]: Error al leer SuperBloque")
+	}
+
+	// Los bitmaps se cargan una vez y se escriben juntos al terminar el comando
+	asignador, errAsig := utils.IniciarAsignacion(file, &sb, global.SesionActiva.Particion.Part_fit)
+	if errAsig != nil {
+		return "", nil, errores.Nuevo(errores.Interno, "[MKUSR]: Error al leer los bitmaps")
 	}
+	defer asignador.Terminar()
 
 	// Leer contenido actual de users.txt
-	contenidoActual, errRead := utils.LeerArchivoDesdeRuta(file, &sb, "/users.txt")
+	contenidoActual, errRead := utils.LeerArchivoDesdeRuta(file, &sb, "/users.txt", global.SesionActiva)
 	if errRead != nil {
-		return "[MKUSR]: Error al leer users.txt", true
+		return "", nil, errores.Nuevo(errores.Interno, "[MKUSR]: Error al leer users.txt")
 	}
 
 	// Verificar que el usuario no exista
 	if ExisteUsuario(contenidoActual, nombreUsuario) {
-		return fmt.Sprintf("[MKUSR]: El usuario '%s' ya existe", nombreUsuario), true
+		return "", nil, errores.Nuevof(errores.YaExiste, "[MKUSR]: El usuario '%s' ya existe", nombreUsuario)
 	}
 
 	// Verificar que el grupo exista
 	if !ExisteGrupo(contenidoActual, grupo) {
-		return fmt.Sprintf("[MKUSR]: El grupo '%s' no existe", grupo), true
+		return "", nil, errores.Nuevof(errores.NoEncontrado, "[MKUSR]: El grupo '%s' no existe", grupo)
 	}
 
 	// Calcular nuevo UID
@@ -95,7 +106,10 @@ func crearUsuario(nombreUsuario string, password string, grupo string) (string,
 
 	// Escribir el nuevo contenido
 	if err := utils.EscribirArchivoUsersText(file, &sb, nuevoContenido); err != nil {
-		return "[MKUSR]: Error al escribir en users.txt: " + err.Error(), true
+		return "", nil, errores.Nuevof(errores.Interno, "[MKUSR]: Error al escribir en users.txt: %w", err)
+	}
+	if err := utils.TerminarEscritura(file, asignador); err != nil {
+		return "", nil, errores.Nuevof(errores.Interno, "[MKUSR]: Error al guardar los cambios en el disco: %w", err)
 	}
 
 	detalles := fmt.Sprintf(`  Usuario:        %s
@@ -113,7 +127,7 @@ func crearUsuario(nombreUsuario string, password string, grupo string) (string,
 	color.Cyan("  Password:       %s", password)
 	color.Green("===========================================================")
 
-	return salida, false
+	return salida, nil, nil
 }
 
 // ExisteUsuario verifica si un usuario ya existe
@@ -2,48 +2,19 @@
 package general
 
 import (
-	"Proyecto/Reportes"
 	"Proyecto/comandos"
-	"Proyecto/comandos/admonUsers"
-	"Proyecto/comandos/filecomands"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/sintaxis"
+	"Proyecto/comandos/utils"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
-)
-
-var commandGroups = map[string][]string{
-	"disk":    {"mkdisk", "fdisk", "rmdisk", "mount", "mounted", "mkfs"},
-	"reports": {"rep"},
-	"files":   {"mkfile", "mkdir"},
-	"cat":     {"cat"},
-	"users":   {"login", "logout"},
-	"groups":  {"mkgrp", "mkusr"},
-}
+	"time"
 
-func DetectGroup(cmd string) (string, string, bool, string) {
-	parts := strings.Fields(cmd)
-	if len(parts) == 0 {
-		return "", "", true, "Comando vacío"
-	}
-
-	// Extraer el nombre del comando
-	commandOnly := parts[0]
-	// Eliminar el prefijo "-" si existe (esto es para manejar casos raros o errores de parsing)
-	// Pero normalmente no debería ser necesario aquí si se parsea bien el comando
-	// if strings.HasPrefix(commandOnly, "-") {
-	// 	commandOnly = commandOnly[1:]
-	// }
-	cmdLower := strings.ToLower(strings.TrimSpace(commandOnly))
-
-	for group, cmds := range commandGroups {
-		for _, exactCmd := range cmds {
-			if cmdLower == exactCmd {
-				return group, cmdLower, false, ""
-			}
-		}
-	}
-
-	return "", "", true, "Comando no reconocido: " + cmdLower
-}
+	"github.com/fatih/color"
+)
 
 func ParseParamList(raw []string) map[string]string {
 	params := make(map[string]string)
@@ -60,13 +31,119 @@ func ParseParamList(raw []string) map[string]string {
 	return params
 }
 
-func GlobalCom(lista []string) ([]string, []string, int) {
-	var errores []string
-	var salidas []string
-	var contErrores = 0
+func GlobalCom(lista []string) ([]FalloComando, []string, int) {
+	fallos, salidas, contErrores, _ := GlobalComTransaccional(lista, false)
+	return fallos, salidas, contErrores
+}
+
+// GlobalComTransaccional ejecuta la lista de comandos. Si transaccional es true
+// (o el script trae la directiva #!transaccion) el primer error detiene la ejecución
+// y se revierten los cambios hechos a los discos. Cada comando que falla queda en la
+// primera lista con su línea y su código de error.
+func GlobalComTransaccional(lista []string, transaccional bool) ([]FalloComando, []string, int, *ResultadoTransaccion) {
+	e := EjecutarComandos(lista, transaccional)
+	return e.Fallos, e.Salidas, e.ContErrores, e.Transaccion
+}
+
+// maxScriptsAnidados limita cuántos exec pueden estar abiertos a la vez
+const maxScriptsAnidados = 16
+
+// Ejecucion reúne lo que produjo una lista de comandos
+type Ejecucion struct {
+	Resultados  []ResultadoComando // una entrada por cada comando ejecutado
+	Salidas     []string           // texto plano por línea, con comentarios y líneas vacías
+	Fallos      []FalloComando
+	ContErrores int
+	Transaccion *ResultadoTransaccion
+
+	tx      *transaccion
+	scripts []string // rutas de los scripts exec en curso, para detectar ciclos
+}
+
+// registrar agrega el resultado de un comando y su texto a la ejecución
+func (e *Ejecucion) registrar(r ResultadoComando) {
+	e.Resultados = append(e.Resultados, r)
+	e.agregarSalida(r.Script, r.Linea, r.Mensaje)
+	if !r.Exito {
+		e.Fallos = append(e.Fallos, FalloComando{Script: r.Script, Linea: r.Linea, Comando: r.Texto, Codigo: r.Codigo, Mensaje: r.Mensaje})
+		e.ContErrores++
+	}
+}
+
+// agregarSalida agrega el texto plano de una línea; dentro de un exec cada renglón
+// lleva delante el script y la línea (ej. "[discos.smia:4] ...") para poder rastrearlo
+func (e *Ejecucion) agregarSalida(script string, linea int, texto string) {
+	if script == "" {
+		e.Salidas = append(e.Salidas, texto)
+		return
+	}
+	prefijo := fmt.Sprintf("[%s:%d] ", script, linea)
+	renglones := strings.Split(texto, "\n")
+	for i := range renglones {
+		renglones[i] = prefijo + renglones[i]
+	}
+	e.Salidas = append(e.Salidas, strings.Join(renglones, "\n"))
+}
+
+// abortar revierte la transacción por el comando que falló
+func (e *Ejecucion) abortar(r ResultadoComando) {
+	comando := r.Texto
+	if r.Script != "" {
+		comando = r.Script + ": " + r.Texto
+	}
+	e.Salidas = e.tx.abortar(e.Salidas, r.Linea, comando, r.Codigo)
+	e.Transaccion = e.tx.resultado
+}
+
+// EjecutarComandos ejecuta la lista de comandos igual que GlobalComTransaccional y
+// devuelve además el resultado estructurado de cada línea
+func EjecutarComandos(lista []string, transaccional bool) *Ejecucion {
+	e := &Ejecucion{}
 
 	for _, comm := range lista {
-		comm = strings.TrimSpace(comm)
+		if EsDirectivaTransaccion(comm) {
+			transaccional = true
+			break
+		}
+	}
+
+	if transaccional {
+		tx, errTx := iniciarTransaccion()
+		if errTx != nil {
+			msg := "[TRANSACCIÓN]: No se pudo iniciar la transacción: " + errTx.Error()
+			e.Fallos = []FalloComando{{Codigo: errores.Interno, Mensaje: msg}}
+			e.Salidas = []string{msg}
+			e.ContErrores = 1
+			e.Transaccion = &ResultadoTransaccion{Codigo: errores.Interno, Mensaje: msg}
+			return e
+		}
+		defer tx.finalizar()
+		e.tx = tx
+	}
+
+	// Los exec de la consola no dependen del directorio de trabajo del servidor
+	if !e.ejecutarLineas(lista, "", utils.DirectorioScripts) {
+		return e
+	}
+
+	if e.tx != nil {
+		e.Transaccion = &ResultadoTransaccion{Mensaje: "[TRANSACCIÓN]: Todos los comandos se ejecutaron correctamente"}
+	}
+	return e
+}
+
+// SepararLineas divide un script en líneas sin descartar las vacías: el índice de cada
+// línea es el número que se reporta en los resultados
+func SepararLineas(texto string) []string {
+	return strings.Split(strings.ReplaceAll(texto, "\r\n", "\n"), "\n")
+}
+
+// ejecutarLineas ejecuta las líneas de un script. script es el nombre que se antepone a
+// las salidas ("" para los comandos enviados en la petición) y dir la carpeta contra la
+// que se resuelven los exec relativos. Devuelve false si la transacción se revirtió.
+func (e *Ejecucion) ejecutarLineas(lista []string, script string, dir string) bool {
+	for i, original := range lista {
+		comm := strings.TrimSpace(original)
 		// --- MODIFICACIÓN: NO IGNORAR COMENTARIOS NI LINEAS VACIAS ---
 		// if comm == "" || strings.HasPrefix(comm, "#") {
 		// 	salidas = append(salidas, "Línea vacía o comentario ignorado")
@@ -75,70 +152,182 @@ func GlobalCom(lista []string) ([]string, []string, int) {
 
 		// Agregar comentario o línea vacía directamente a salidas
 		if comm == "" {
-			salidas = append(salidas, "") // Añadir línea vacía
+			if script == "" {
+				e.Salidas = append(e.Salidas, "") // Añadir línea vacía
+			}
 			continue
 		}
 		if strings.HasPrefix(comm, "#") {
-			salidas = append(salidas, comm) // Añadir comentario tal cual
+			e.agregarSalida(script, i+1, comm) // Añadir comentario tal cual
 			continue
 		}
 		// --- FIN MODIFICACIÓN ---
 
 		fmt.Printf("Procesando comando: [%s]\n", comm)
-		parts := strings.Fields(comm)
-		fmt.Printf("Partes: %+v\n", parts)
-		if len(parts) == 0 {
-			// Este caso debería ser raro ahora, pero por si acaso
-			salidas = append(salidas, "Comando vacío")
+		resultado := ResultadoComando{Script: script, Linea: i + 1, Texto: comm}
+
+		// Se analiza la línea sin recortar para que la columna corresponda al texto enviado
+		inst, errSintaxis := sintaxis.Analizar(original, i+1)
+		if errSintaxis != nil {
+			if campos := strings.Fields(comm); len(campos) > 0 {
+				resultado.Comando = strings.ToLower(campos[0])
+			}
+			var detalle *sintaxis.ErrorSintaxis
+			if errors.As(errSintaxis, &detalle) {
+				resultado.Columna = detalle.Columna
+			}
+			resultado.Codigo = errores.Sintaxis
+			resultado.Mensaje = "[SINTAXIS]: " + errSintaxis.Error()
+			e.registrar(resultado)
+			if e.tx != nil {
+				e.abortar(resultado)
+				return false
+			}
 			continue
 		}
+		command := inst.Comando
+		resultado.Comando = command
+		resultado.Parametros = inst.Valores
 
-		_, command, blnError, strError := DetectGroup(comm)
-		if blnError {
-			msg := "Error: " + strError
-			errores = append(errores, msg)
-			salidas = append(salidas, msg)
-			contErrores++
+		def, existe := comandos.Buscar(command)
+		if !existe {
+			resultado.Codigo = errores.ComandoDesconocido
+			resultado.Mensaje = "Error: Comando no reconocido: " + command
+			e.registrar(resultado)
+			if e.tx != nil {
+				e.abortar(resultado)
+				return false
+			}
 			continue
 		}
 
-		resto := strings.Join(parts[1:], " ")
-		fmt.Printf("Resto (para parámetros): [%s]\n", resto)
-		paramsMap := ObtenerParametros(resto)
-		fmt.Printf("Parámetros parseados: %+v\n", paramsMap)
-
 		var salida string
-		var err bool
-
-		switch command { // ← usa 'command', no 'group'
-		case "login":
-			salida, err = admonUsers.LoginExecute(comm, paramsMap)
-		case "logout":
-			salida, err = admonUsers.LogoutExecute(comm, paramsMap)
-		case "mkdisk", "fdisk", "rmdisk", "mount", "mounted", "mkfs": // Añadido "rep" si lo manejas en comandos.DiskExecuteWithOutput
-			salida, err = comandos.DiskExecuteWithOutput(command, paramsMap)
-		case "mkgrp":
-			salida, err = filecomands.MkgrpExecute(comm, paramsMap)
-		case "mkusr":
-			salida, err = filecomands.MkusrExecute(comm, paramsMap)
-		case "cat":
-			salida, err = filecomands.CatExecute(comm, paramsMap)
-		case "mkdir":
-			salida, err = filecomands.MkdirExecute(comm, paramsMap)
-		case "mkfile":
-			salida, err = filecomands.MkfileExecute(comm, paramsMap)
-		case "rep":
-			salida, err = Reportes.RepExecute(comm, paramsMap)
-		default:
-			// Si el comando no está en ninguno de los casos anteriores
-			salida, err = "Comando no implementado: "+command, true
+		var datos interface{}
+		inicio := time.Now()
+
+		// Los parámetros se validan contra la declaración del comando en el registro
+		props, err := def.Validar(command, inst.Valores, inst.Banderas)
+		if err == nil {
+			resultado.Parametros = props
+		}
+
+		if err == nil && e.tx != nil {
+			if errRes := e.tx.respaldar(command, props); errRes != nil {
+				resultado.Codigo = errores.Interno
+				resultado.Mensaje = "[TRANSACCIÓN]: " + errRes.Error()
+				e.registrar(resultado)
+				e.abortar(resultado)
+				return false
+			}
+		}
+
+		if err == nil {
+			if command == "exec" {
+				// exec no pasa por Run: el script comparte la transacción y la pila de scripts
+				var revertida bool
+				salida, datos, revertida, err = e.ejecutarScript(props, dir)
+				if revertida {
+					return false
+				}
+			} else {
+				salida, datos, err = def.Run(command, props)
+			}
+		}
+
+		resultado.DuracionMs = float64(time.Since(inicio).Microseconds()) / 1000
+		if err != nil {
+			// El mensaje del error es lo que se muestra como salida del comando
+			resultado.Codigo = errores.CodigoDe(err)
+			resultado.Mensaje = err.Error()
+		} else {
+			resultado.Exito = true
+			resultado.Mensaje = salida
+			resultado.Datos = datos
+		}
+		e.registrar(resultado)
+
+		if err != nil && e.tx != nil {
+			e.abortar(resultado)
+			return false
+		}
+	}
+	return true
+}
+
+// ejecutarScript lee un archivo .smia del host y ejecuta sus líneas en la misma
+// ejecución. -path relativo se resuelve contra -base, y -base relativo (o ausente)
+// contra la carpeta del script que hace el exec; en el nivel superior esa carpeta es
+// utils.DirectorioScripts.
+func (e *Ejecucion) ejecutarScript(props map[string]string, dir string) (string, interface{}, bool, error) {
+	base := strings.TrimSpace(props["base"])
+	if base == "" {
+		base = dir
+	} else if !filepath.IsAbs(base) {
+		base = filepath.Join(dir, base)
+	}
+
+	ruta := strings.TrimSpace(props["path"])
+	if !filepath.IsAbs(ruta) {
+		ruta = filepath.Join(base, ruta)
+	}
+	ruta, err := filepath.Abs(ruta)
+	if err != nil {
+		return "", nil, false, errores.Nuevof(errores.Interno, "[EXEC]: No se pudo resolver la ruta '%s': %v", props["path"], err)
+	}
+	if !strings.EqualFold(filepath.Ext(ruta), ".smia") {
+		return "", nil, false, errores.Nuevof(errores.ParametroInvalido, "[EXEC]: El script '%s' debe tener extensión .smia", filepath.Base(ruta))
+	}
+
+	contenido, err := os.ReadFile(ruta)
+	if os.IsNotExist(err) {
+		return "", nil, false, errores.Nuevof(errores.NoEncontrado, "[EXEC]: Script no encontrado: %s", ruta)
+	}
+	if err != nil {
+		return "", nil, false, errores.Nuevof(errores.Interno, "[EXEC]: No se pudo leer el script '%s': %v", ruta, err)
+	}
+	if real, err := filepath.EvalSymlinks(ruta); err == nil {
+		ruta = real
+	}
+
+	nombre := filepath.Base(ruta)
+	for i, abierto := range e.scripts {
+		if abierto == ruta {
+			cadena := make([]string, 0, len(e.scripts)-i+1)
+			for _, s := range e.scripts[i:] {
+				cadena = append(cadena, filepath.Base(s))
+			}
+			cadena = append(cadena, nombre)
+			return "", nil, false, errores.Nuevof(errores.ParametroInvalido, "[EXEC]: Ciclo de scripts detectado: %s", strings.Join(cadena, " -> "))
 		}
-		if err {
-			errores = append(errores, salida)
-			contErrores++
+	}
+	if len(e.scripts) >= maxScriptsAnidados {
+		return "", nil, false, errores.Nuevof(errores.ParametroInvalido, "[EXEC]: Se superó el máximo de %d scripts anidados", maxScriptsAnidados)
+	}
+
+	e.scripts = append(e.scripts, ruta)
+	defer func() { e.scripts = e.scripts[:len(e.scripts)-1] }()
+
+	lineas := SepararLineas(string(contenido))
+
+	// La transacción se decide antes de ejecutar la primera línea: un script llamado con
+	// exec solo puede pedirla si la ejecución que lo llama ya es transaccional
+	if e.tx == nil {
+		for _, linea := range lineas {
+			if EsDirectivaTransaccion(linea) {
+				return "", nil, false, errores.Nuevof(errores.ParametroInvalido, "[EXEC]: El script '%s' pide %s, pero la ejecución que lo llama no es transaccional; ponga la directiva en el script principal", nombre, DirectivaTransaccion)
+			}
 		}
-		salidas = append(salidas, salida)
 	}
 
-	return errores, salidas, contErrores
+	color.Cyan("→ Ejecutando script %s", ruta)
+	resultadosAntes, erroresAntes := len(e.Resultados), e.ContErrores
+	if !e.ejecutarLineas(lineas, nombre, filepath.Dir(ruta)) {
+		return "", nil, true, nil
+	}
+
+	ejecutados := len(e.Resultados) - resultadosAntes
+	fallidos := e.ContErrores - erroresAntes
+	salida := fmt.Sprintf("[EXEC]: Script '%s' ejecutado: %d comando(s), %d con error", nombre, ejecutados, fallidos)
+	datos := map[string]interface{}{"script": ruta, "comandos": ejecutados, "errores": fallidos}
+	return salida, datos, false, nil
 }
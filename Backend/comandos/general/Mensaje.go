@@ -1,5 +1,7 @@
 package general
 
+import "Proyecto/comandos/errores"
+
 type Resultado struct {
 	StrMensajeError string
 	BlnError        bool
@@ -10,10 +12,38 @@ type SalidaComandoEjecutado struct {
 	LstComandos []string
 }
 
+// FalloComando describe un comando que terminó con error
+type FalloComando struct {
+	Script  string         `json:"script,omitempty"`
+	Linea   int            `json:"line"`
+	Comando string         `json:"command"`
+	Codigo  errores.Codigo `json:"code"`
+	Mensaje string         `json:"message"`
+}
+
+// ResultadoComando es el resultado de una línea ejecutada
+type ResultadoComando struct {
+	Script     string            `json:"script,omitempty"` // script .smia de la línea cuando viene de un exec
+	Linea      int               `json:"line"`
+	Texto      string            `json:"text"` // línea original
+	Comando    string            `json:"command"`
+	Columna    int               `json:"column,omitempty"` // solo en errores de sintaxis
+	Parametros map[string]string `json:"params"`
+	Exito      bool              `json:"success"`
+	Codigo     errores.Codigo    `json:"code,omitempty"`
+	Mensaje    string            `json:"message"`
+	DuracionMs float64           `json:"duration_ms"`
+	Datos      interface{}       `json:"data,omitempty"` // p. ej. el ID de mount o el disco creado
+}
+
 type ResultadoAPI struct {
 	Error   bool        `json:"error"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
+	// Code y Errors permiten al frontend distinguir los errores sin leer el mensaje
+	Code    errores.Codigo     `json:"code,omitempty"`
+	Errors  []FalloComando     `json:"errors,omitempty"`
+	Results []ResultadoComando `json:"results,omitempty"`
 }
 
 func ResultadoSalida(message string, isError bool, data interface{}) ResultadoAPI {
@@ -23,3 +53,10 @@ func ResultadoSalida(message string, isError bool, data interface{}) ResultadoAP
 		Data:    data,
 	}
 }
+
+// ResultadoFallido arma una respuesta de error con su código
+func ResultadoFallido(message string, codigo errores.Codigo, data interface{}) ResultadoAPI {
+	resultado := ResultadoSalida(message, true, data)
+	resultado.Code = codigo
+	return resultado
+}
@@ -0,0 +1,242 @@
+package general
+
+import (
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/global"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// linea es lo que se espera de un comando del script
+type linea struct {
+	codigo   errores.Codigo // vacío si el comando debe terminar bien
+	contiene string
+}
+
+// ejecutarEnMemoria corre el script con los discos en memoria y sin sesión previa
+func ejecutarEnMemoria(t *testing.T, script string) *Ejecucion {
+	t.Helper()
+	anterior := almacenamiento.Actual()
+	almacenamiento.Usar(almacenamiento.NuevaMemoria())
+	global.SesionActiva = nil
+	t.Cleanup(func() {
+		almacenamiento.Usar(anterior)
+		global.SesionActiva = nil
+	})
+	return EjecutarComandos(strings.Split(script, "\n"), false)
+}
+
+const discoMontado = `mkdisk -size=1 -unit=M
+fdisk -size=400 -unit=K -diskname=VDIC-A.mia -name=Part1
+mount -diskname=VDIC-A.mia -name=Part1`
+
+func TestScriptsEnMemoria(t *testing.T) {
+	casos := []struct {
+		nombre string
+		script string
+		lineas []linea
+	}{
+		{"discos y particiones", discoMontado + `
+fdisk -size=100 -unit=K -diskname=VDIC-A.mia -name=Part1
+fdisk -size=100 -unit=K -diskname=VDIC-Z.mia -name=Part2
+mount -diskname=VDIC-A.mia -name=Nada
+mounted`, []linea{
+			{"", "VDIC-A"},
+			{"", "Part1"},
+			{"", "191A"},
+			{errores.YaExiste, ""},
+			{errores.NoEncontrado, ""},
+			{errores.NoEncontrado, ""},
+			{"", "191A"},
+		}},
+		{"archivos con bitmaps ASCII", discoMontado + `
+mkfs -id=191A
+login -user=root -pass=123 -id=191A
+mkdir -p -path=/home/docs
+mkfile -path=/home/docs/a.txt -cont="hola mundo"
+cat -file1=/home/docs/a.txt
+mkfile -r -path=/x/y/b.txt -size=12
+cat -file1=/x/y/b.txt
+logout`, []linea{
+			{"", ""}, {"", ""}, {"", ""},
+			{"", "1 (bitmaps ASCII)"},
+			{"", "root"},
+			{"", "/home/docs"},
+			{"", "/home/docs/a.txt"},
+			{"", "hola mundo"},
+			{"", "12 bytes"},
+			{"", "012345678901"},
+			{"", "root"},
+		}},
+		{"archivos con bitmaps empaquetados", discoMontado + `
+mkfs -id=191A -version=2
+login -user=root -pass=123 -id=191A
+mkfile -path=/users2.txt -cont="copia"
+cat -file1=/users.txt -file2=/users2.txt`, []linea{
+			{"", ""}, {"", ""}, {"", ""},
+			{"", "2 (bitmaps empaquetados)"},
+			{"", ""},
+			{"", ""},
+			{"", "copia"},
+		}},
+		{"snapshots con particiones lógicas", discoMontado + `
+fdisk -size=300 -unit=K -diskname=VDIC-A.mia -name=Ext1 -type=E
+fdisk -size=100 -unit=K -diskname=VDIC-A.mia -name=Log1 -type=L
+snapshot -diskname=../VDIC-A.mia -name=s1
+snapshot -diskname=VDIC-A -name=s1
+restore -diskname=VDIC-A.mia -name=s1 -partition=Log1
+restore -diskname=..\\VDIC-A.mia -name=s1`, []linea{
+			{"", ""}, {"", ""}, {"", ""},
+			{"", "Ext1"},
+			{"", "Log1"},
+			{errores.ParametroInvalido, ""},
+			{"", "VDIC-A.mia"},
+			{"", "Log1"},
+			{errores.ParametroInvalido, ""},
+		}},
+		{"errores", discoMontado + `
+mkdir -path=/home
+mkfs -id=999Z
+mkfs -id=191A
+login -user=root -pass=mal -id=191A
+mkdisk -size=
+formatear -id=191A
+mkdisk -size=1 -unit=G`, []linea{
+			{"", ""}, {"", ""}, {"", ""},
+			{errores.SinSesion, ""},
+			{errores.NoMontada, ""},
+			{"", ""},
+			{errores.SinPermiso, ""},
+			{errores.Sintaxis, "columna"},
+			{errores.ComandoDesconocido, ""},
+			{errores.ParametroInvalido, ""},
+		}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			e := ejecutarEnMemoria(t, c.script)
+			if len(e.Resultados) != len(c.lineas) {
+				t.Fatalf("%d resultados, se esperaban %d", len(e.Resultados), len(c.lineas))
+			}
+			for i, esperado := range c.lineas {
+				r := e.Resultados[i]
+				if r.Exito != (esperado.codigo == "") || r.Codigo != esperado.codigo {
+					t.Errorf("%q: éxito %v código %q, se esperaba código %q\n%s", r.Texto, r.Exito, r.Codigo, esperado.codigo, r.Mensaje)
+					continue
+				}
+				if !strings.Contains(r.Mensaje, esperado.contiene) {
+					t.Errorf("%q: la salida no contiene %q:\n%s", r.Texto, esperado.contiene, r.Mensaje)
+				}
+			}
+		})
+	}
+}
+
+func TestImportOmiteNombresLargos(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("uno"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "nombre_demasiado_largo.txt"), []byte("dos"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "z.txt"), []byte("tres"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := ejecutarEnMemoria(t, discoMontado+`
+mkfs -id=191A
+login -user=root -pass=123 -id=191A
+import -src=`+dir+` -dest=/host
+cat -file1=/host/a.txt -file2=/host/z.txt`)
+
+	importacion := e.Resultados[5]
+	if !importacion.Exito {
+		t.Fatalf("import falló: %s", importacion.Mensaje)
+	}
+	for _, esperado := range []string{"Archivos:       2", "Omitidos:       1", "/host/nombre_demasiado_largo.txt"} {
+		if !strings.Contains(importacion.Mensaje, esperado) {
+			t.Errorf("la salida no contiene %q:\n%s", esperado, importacion.Mensaje)
+		}
+	}
+	if cat := e.Resultados[6]; !cat.Exito || !strings.Contains(cat.Mensaje, "uno") || !strings.Contains(cat.Mensaje, "tres") {
+		t.Errorf("cat: %s", cat.Mensaje)
+	}
+}
+
+func TestArchivosConBloquesIndirectos(t *testing.T) {
+	// 2000 bytes llegan al apuntador doble y users.txt crece más allá de los 12 directos
+	script := discoMontado + `
+mkfs -id=191A
+login -user=root -pass=123 -id=191A
+mkfile -path=/grande.txt -size=2000`
+	for i := 0; i < 40; i++ {
+		script += fmt.Sprintf("\nmkusr -user=usuario%02d -pass=123 -grp=root", i)
+	}
+	script += `
+cat -file1=/grande.txt
+cat -file1=/users.txt
+fsck -id=191A`
+
+	e := ejecutarEnMemoria(t, script)
+	for _, r := range e.Resultados {
+		if !r.Exito {
+			t.Fatalf("%q: %s", r.Texto, r.Mensaje)
+		}
+	}
+
+	n := len(e.Resultados)
+	if grande := e.Resultados[n-3].Mensaje; !strings.Contains(grande, strings.Repeat("0123456789", 200)) {
+		t.Errorf("cat de /grande.txt no devuelve los 2000 bytes:\n%s", grande)
+	}
+	if users := e.Resultados[n-2].Mensaje; !strings.Contains(users, "usuario00") || !strings.Contains(users, "usuario39") {
+		t.Errorf("users.txt perdió usuarios:\n%s", users)
+	}
+}
+
+func TestDefragConservaContenido(t *testing.T) {
+	// users.txt crece entre archivos nuevos, así sus bloques (directos e indirectos)
+	// quedan intercalados con los de /a.txt y /b.txt
+	script := discoMontado + `
+mkfs -id=191A
+login -user=root -pass=123 -id=191A
+mkfile -path=/a.txt -size=100`
+	for i := 0; i < 40; i++ {
+		script += fmt.Sprintf("\nmkusr -user=usuario%02d -pass=123 -grp=root", i)
+		if i == 10 {
+			script += "\nmkfile -path=/b.txt -size=300"
+		}
+	}
+	script += `
+mkfile -path=/c.txt -size=2000
+cat -file1=/users.txt -file2=/a.txt -file3=/b.txt -file4=/c.txt
+fsck -id=191A
+defrag -id=191A
+cat -file1=/users.txt -file2=/a.txt -file3=/b.txt -file4=/c.txt
+fsck -id=191A
+defrag -id=191A`
+
+	e := ejecutarEnMemoria(t, script)
+	for _, r := range e.Resultados {
+		if !r.Exito {
+			t.Fatalf("%q: %s", r.Texto, r.Mensaje)
+		}
+	}
+
+	n := len(e.Resultados)
+	antes, defrag, despues, repetido := e.Resultados[n-6], e.Resultados[n-4], e.Resultados[n-3], e.Resultados[n-1]
+	if strings.Contains(defrag.Mensaje, "ya está desfragmentada") || !strings.Contains(defrag.Mensaje, "→ 0.0%") {
+		t.Errorf("defrag no compactó la partición:\n%s", defrag.Mensaje)
+	}
+	if antes.Mensaje != despues.Mensaje {
+		t.Errorf("el contenido cambió al desfragmentar:\nantes:\n%s\ndespués:\n%s", antes.Mensaje, despues.Mensaje)
+	}
+	if !strings.Contains(repetido.Mensaje, "ya está desfragmentada") {
+		t.Errorf("un segundo defrag no debería mover bloques:\n%s", repetido.Mensaje)
+	}
+}
@@ -13,25 +13,6 @@ var NamePath = "VDIC-MIA"
 var ReportPath = "VDIC-MIA/Rep"
 var DiskPath = "VDIC-MIA/Disks"
 
-func ObtenerParametros(comando string) map[string]string {
-	parametros := make(map[string]string)
-	re := regexp.MustCompile(`-([a-zA-Z]\w*)=("[^"]*"|\S+)`)
-	matches := re.FindAllStringSubmatch(comando, -1)
-
-	for _, match := range matches {
-		nombre := strings.ToLower(match[1])
-		valor := match[2]
-
-		if len(valor) >= 2 && valor[0] == '"' && valor[len(valor)-1] == '"' {
-			valor = valor[1 : len(valor)-1]
-		}
-
-		parametros[nombre] = valor
-	}
-
-	return parametros
-}
-
 func CrearCarpeta() {
 	// nombre := "VDIC-MIA"
 	// reportes := "VDIC-MIA/Rep"
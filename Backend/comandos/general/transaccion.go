@@ -0,0 +1,163 @@
+// general/transaccion.go
+package general
+
+import (
+	"Proyecto/comandos/admonDisk"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/utils"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// DirectivaTransaccion activa el modo transaccional desde el propio script
+const DirectivaTransaccion = "#!transaccion"
+
+// ResultadoTransaccion describe cómo terminó una ejecución transaccional
+type ResultadoTransaccion struct {
+	Revertida bool
+	Linea     int
+	Comando   string
+	Codigo    errores.Codigo // código del error que provocó la reversión
+	Mensaje   string
+}
+
+// transaccion guarda una copia de cada disco antes de que un comando lo modifique. Las
+// copias se leen del backend de almacenamiento en uso y se guardan en memoria, así que
+// una transacción sobre discos en memoria no toca el sistema de archivos del host.
+type transaccion struct {
+	respaldos       map[string][]byte // ruta del disco -> contenido antes del primer cambio
+	discosIniciales map[string]bool
+	sesionInicial   *global.SesionUsuario
+	resultado       *ResultadoTransaccion
+}
+
+// EsDirectivaTransaccion indica si la línea es la directiva #!transaccion
+func EsDirectivaTransaccion(linea string) bool {
+	return strings.ToLower(strings.TrimSpace(linea)) == DirectivaTransaccion
+}
+
+func iniciarTransaccion() (*transaccion, error) {
+	tx := &transaccion{
+		respaldos:       make(map[string][]byte),
+		discosIniciales: make(map[string]bool),
+	}
+
+	discos, err := almacenamiento.Listar(filepath.Join(utils.DirectorioDisco, "*.mia"))
+	if err != nil {
+		return nil, err
+	}
+	for _, disco := range discos {
+		tx.discosIniciales[filepath.Clean(disco)] = true
+	}
+
+	if global.SesionActiva != nil {
+		copia := *global.SesionActiva
+		tx.sesionInicial = &copia
+	}
+
+	return tx, nil
+}
+
+// respaldar copia los discos que el comando puede modificar (solo la primera vez)
+func (tx *transaccion) respaldar(command string, params map[string]string) error {
+	for _, disco := range discosAfectados(command, params) {
+		disco = filepath.Clean(disco)
+		if _, ok := tx.respaldos[disco]; ok {
+			continue
+		}
+		if !almacenamiento.Existe(disco) {
+			continue
+		}
+
+		copia, err := almacenamiento.LeerTodo(disco)
+		if err != nil {
+			return fmt.Errorf("no se pudo respaldar '%s': %v", filepath.Base(disco), err)
+		}
+		tx.respaldos[disco] = copia
+	}
+	return nil
+}
+
+// revertir restaura los discos respaldados, elimina los creados y recupera la sesión
+func (tx *transaccion) revertir() (int, error) {
+	restaurados := 0
+	for disco, copia := range tx.respaldos {
+		if err := almacenamiento.EscribirTodo(disco, copia); err != nil {
+			return restaurados, fmt.Errorf("no se pudo restaurar '%s': %v", filepath.Base(disco), err)
+		}
+		restaurados++
+	}
+
+	discos, _ := almacenamiento.Listar(filepath.Join(utils.DirectorioDisco, "*.mia"))
+	for _, disco := range discos {
+		if !tx.discosIniciales[filepath.Clean(disco)] {
+			if err := almacenamiento.Eliminar(disco); err != nil {
+				return restaurados, fmt.Errorf("no se pudo eliminar '%s': %v", filepath.Base(disco), err)
+			}
+			restaurados++
+		}
+	}
+
+	global.SesionActiva = tx.sesionInicial
+	return restaurados, nil
+}
+
+// finalizar suelta las copias de los discos al terminar la ejecución
+func (tx *transaccion) finalizar() {
+	tx.respaldos = nil
+}
+
+// discosAfectados devuelve las rutas de los discos que un comando puede escribir.
+// Los comandos de solo lectura no devuelven nada y los desconocidos devuelven todos.
+func discosAfectados(command string, params map[string]string) []string {
+	switch command {
+	case "mkdisk", "login", "logout", "mounted", "cat", "rep", "snapshot", "export", "exec":
+		// mkdisk solo crea discos nuevos, que se eliminan al revertir; exec respalda comando por comando
+		return nil
+	case "rmdisk", "fdisk", "mount", "restore":
+		nombre := strings.TrimSpace(params["diskname"])
+		if nombre == "" {
+			return nil
+		}
+		if !strings.Contains(nombre, ".") {
+			nombre += ".mia"
+		}
+		return []string{utils.DirectorioDisco + nombre}
+	case "mkfs", "defrag":
+		particion, err := admonDisk.GetMountedPartitionByID(strings.TrimSpace(params["id"]))
+		if err != nil {
+			return nil
+		}
+		return []string{particion.DiskPath}
+	case "mkdir", "mkfile", "mkgrp", "mkusr", "import":
+		if global.SesionActiva == nil {
+			return nil
+		}
+		return []string{global.SesionActiva.PathDisco}
+	default:
+		discos, _ := almacenamiento.Listar(filepath.Join(utils.DirectorioDisco, "*.mia"))
+		return discos
+	}
+}
+
+// abortar revierte la transacción y agrega a las salidas el comando que la provocó
+func (tx *transaccion) abortar(salidas []string, linea int, comando string, codigo errores.Codigo) []string {
+	restaurados, errRevertir := tx.revertir()
+
+	var msg string
+	if errRevertir != nil {
+		msg = fmt.Sprintf("[TRANSACCIÓN]: Falló el comando de la línea %d (%s) y la reversión quedó incompleta: %v", linea, comando, errRevertir)
+		color.Red(msg)
+	} else {
+		msg = fmt.Sprintf("[TRANSACCIÓN]: Falló el comando de la línea %d (%s). Se revirtieron los cambios en %d disco(s)", linea, comando, restaurados)
+		color.Yellow(msg)
+	}
+
+	tx.resultado = &ResultadoTransaccion{Revertida: true, Linea: linea, Comando: comando, Codigo: codigo, Mensaje: msg}
+	return append(salidas, msg)
+}
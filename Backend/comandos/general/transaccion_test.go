@@ -0,0 +1,63 @@
+package general
+
+import (
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/utils"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransaccionRevierteDiscosYSesion(t *testing.T) {
+	anterior := almacenamiento.Actual()
+	memoria := almacenamiento.NuevaMemoria()
+	almacenamiento.Usar(memoria)
+	global.SesionActiva = nil
+	t.Cleanup(func() {
+		almacenamiento.Usar(anterior)
+		global.SesionActiva = nil
+	})
+
+	preparacion := EjecutarComandos(strings.Split(discoMontado+`
+mkfs -id=191A
+login -user=root -pass=123 -id=191A`, "\n"), false)
+	if preparacion.ContErrores != 0 {
+		t.Fatalf("la preparación falló: %v", preparacion.Fallos)
+	}
+	ruta := filepath.Join(utils.DirectorioDisco, "VDIC-A.mia")
+	antes, err := almacenamiento.LeerTodo(ruta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := EjecutarComandos(strings.Split(`#!transaccion
+mkdir -path=/nuevo
+mkfile -path=/nuevo/a.txt -cont="hola"
+mkdisk -size=1 -unit=M
+logout
+mkdir -path=/sin/padre`, "\n"), false)
+
+	if e.Transaccion == nil || !e.Transaccion.Revertida {
+		t.Fatalf("la transacción no se revirtió: %+v", e.Transaccion)
+	}
+	if e.Transaccion.Linea != 6 || e.Transaccion.Codigo != errores.SinSesion {
+		t.Errorf("revertida por la línea %d (%s), se esperaba la 6 por falta de sesión", e.Transaccion.Linea, e.Transaccion.Codigo)
+	}
+
+	despues, err := almacenamiento.LeerTodo(ruta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(antes, despues) {
+		t.Error("el disco no volvió a su contenido anterior")
+	}
+	if discos, _ := memoria.Listar(filepath.Join(utils.DirectorioDisco, "*.mia")); len(discos) != 1 {
+		t.Errorf("quedaron los discos %v, el creado en la transacción debía eliminarse", discos)
+	}
+	if global.SesionActiva == nil || global.SesionActiva.UsuarioActual != "root" {
+		t.Errorf("la sesión no se recuperó: %+v", global.SesionActiva)
+	}
+}
@@ -0,0 +1,6 @@
+package global
+
+import "sync"
+
+// BloqueoDiscos serializa el acceso a los discos entre los distintos endpoints HTTP
+var BloqueoDiscos sync.Mutex
@@ -0,0 +1,320 @@
+package motor
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/global"
+	"Proyecto/comandos/utils"
+	"strings"
+)
+
+// ResultadoLogin describe la sesión iniciada
+type ResultadoLogin struct {
+	Usuario   string
+	UID       int32
+	GID       int32
+	ID        string
+	Particion string
+	Disco     string
+}
+
+// Login valida el usuario contra /users.txt de la partición y deja la sesión en el motor
+func (m *Motor) Login(usuario string, password string, idParticion string) (*ResultadoLogin, error) {
+	if m.Sesion() != nil {
+		return nil, errores.Nuevo(errores.YaExiste, "Ya hay una sesión activa. Use LOGOUT primero")
+	}
+
+	particionMontada, err := m.ParticionMontadaPorID(idParticion)
+	if err != nil {
+		return nil, errores.Nuevof(errores.NoMontada, "Partición con ID '%s' no encontrada o no montada", idParticion)
+	}
+
+	file, err := m.AbrirDisco(particionMontada.DiskPath, false)
+	if err != nil {
+		return nil, errores.Nuevo(errores.Interno, "Error al abrir el disco")
+	}
+	defer file.Close()
+
+	var mbr structures.MBR
+	if err := utils.LeerEstructura(file, 0, &mbr); err != nil {
+		return nil, errores.Nuevo(errores.Interno, "Error en la lectura del MBR")
+	}
+
+	particion := buscarParticionMBR(&mbr, particionMontada.PartName)
+	if particion == nil {
+		return nil, errores.Nuevo(errores.NoEncontrado, "Partición no encontrada en el MBR")
+	}
+
+	sb, err := utils.LeerSuperBloque(file, particion.Part_start)
+	if err != nil {
+		return nil, errores.Nuevo(errores.Interno, "Partición no formateada o error al leer SuperBloque")
+	}
+
+	// users.txt se lee sin sesión: todavía no hay usuario para revisar permisos
+	contenidoUsers, err := utils.LeerArchivoDesdeRuta(file, &sb, "/users.txt", nil)
+	if err != nil {
+		return nil, errores.Nuevof(errores.Interno, "Error al leer archivo users.txt: %w", err)
+	}
+
+	uid, gid, encontrado := utils.ValidarCredenciales(contenidoUsers, usuario, password)
+	if !encontrado {
+		return nil, errores.Nuevo(errores.SinPermiso, "Usuario o contraseña incorrectos")
+	}
+
+	m.UsarSesion(&Sesion{
+		Usuario:   usuario,
+		UID:       uid,
+		GID:       gid,
+		ID:        idParticion,
+		Disco:     particionMontada.DiskPath,
+		Particion: *particion,
+	})
+
+	return &ResultadoLogin{
+		Usuario:   usuario,
+		UID:       uid,
+		GID:       gid,
+		ID:        idParticion,
+		Particion: particionMontada.PartName,
+		Disco:     particionMontada.DiskName,
+	}, nil
+}
+
+// operacionFS agrupa el disco abierto de la sesión durante una operación de archivos.
+// file es la caché propia de la operación: todo lo que se lee o escribe pasa por ella.
+type operacionFS struct {
+	file      *utils.CacheDisco
+	sb        structures.SuperBloque
+	sesion    *global.SesionUsuario
+	asignador *utils.Asignador // solo en operaciones de escritura
+}
+
+// abrirSesion abre la partición de la sesión con una caché propia; en escritura también
+// carga los bitmaps para asignar
+func (m *Motor) abrirSesion(escritura bool) (*operacionFS, error) {
+	sesion := m.Sesion()
+	if sesion == nil {
+		return nil, ErrSinSesion
+	}
+
+	file, err := m.AbrirDiscoConCache(sesion.Disco, escritura)
+	if err != nil {
+		return nil, errores.Nuevo(errores.Interno, "Error al abrir el disco")
+	}
+	op := &operacionFS{file: file, sesion: sesion.Credenciales()}
+
+	op.sb, err = utils.LeerSuperBloque(file, sesion.Particion.Part_start)
+	if err != nil {
+		op.cerrar()
+		return nil, errores.Nuevo(errores.Interno, "Error al leer SuperBloque")
+	}
+
+	if escritura {
+		op.asignador, err = utils.IniciarAsignacion(file, &op.sb, sesion.Particion.Part_fit)
+		if err != nil {
+			op.cerrar()
+			return nil, errores.Nuevo(errores.Interno, "Error al leer los bitmaps")
+		}
+	}
+	return op, nil
+}
+
+// terminar baja al disco los bitmaps y las páginas modificadas; las operaciones de
+// escritura la llaman antes de devolver su resultado
+func (op *operacionFS) terminar() error {
+	if err := utils.TerminarEscritura(op.file, op.asignador); err != nil {
+		return errores.Nuevof(errores.Interno, "Error al guardar los cambios en el disco: %v", err)
+	}
+	return nil
+}
+
+// cerrar libera la operación; en los caminos de error baja lo que haya quedado pendiente
+func (op *operacionFS) cerrar() {
+	op.file.Close()
+}
+
+func (op *operacionFS) guardarSuperBloque() error {
+	if err := utils.EscribirEstructura(op.file, op.sesion.Particion.Part_start, &op.sb); err != nil {
+		return errores.Nuevo(errores.Interno, "Error al escribir SuperBloque actualizado")
+	}
+	return nil
+}
+
+// dividirRuta separa una ruta absoluta en sus componentes; la raíz no tiene componentes
+func dividirRuta(ruta string) (string, []string) {
+	ruta = strings.TrimSpace(ruta)
+	if !strings.HasPrefix(ruta, "/") {
+		ruta = "/" + ruta
+	}
+	partes := strings.Split(strings.Trim(ruta, "/"), "/")
+	if len(partes) == 1 && partes[0] == "" {
+		return ruta, nil
+	}
+	return ruta, partes
+}
+
+// ResultadoMkdir describe la carpeta pedida
+type ResultadoMkdir struct {
+	Ruta   string
+	Creado bool // false si con padres=true la carpeta ya existía
+}
+
+// Mkdir crea una carpeta en la partición de la sesión. Con padres=true crea también las
+// carpetas intermedias que falten.
+func (m *Motor) Mkdir(ruta string, padres bool) (*ResultadoMkdir, error) {
+	op, err := m.abrirSesion(true)
+	if err != nil {
+		return nil, err
+	}
+	defer op.cerrar()
+
+	ruta, partes := dividirRuta(ruta)
+	if len(partes) == 0 {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Ruta inválida para directorio")
+	}
+
+	nombreDirectorio := partes[len(partes)-1]
+	rutaDirectorioPadre := "/" + strings.Join(partes[:len(partes)-1], "/")
+
+	_, _, errPadre := utils.LeerInodoDesdeRuta(op.file, &op.sb, rutaDirectorioPadre)
+	if errPadre != nil {
+		if !padres {
+			return nil, errores.Nuevof(errores.NoEncontrado, "Directorio padre '%s' no existe", rutaDirectorioPadre)
+		}
+		// Crear los directorios intermedios desde la raíz
+		if err := utils.CrearDirectoriosRecursivos(op.file, &op.sb, partes, 0, op.sb.S_inode_start, op.sesion); err != nil {
+			return nil, errores.Nuevof(errores.Interno, "Error al crear directorios recursivamente: %w", err)
+		}
+	} else {
+		inodoPadre, posInodoPadre, err := utils.LeerInodoDesdeRuta(op.file, &op.sb, rutaDirectorioPadre)
+		if err != nil {
+			return nil, errores.Nuevof(errores.Interno, "Error al acceder al directorio padre '%s': %w", rutaDirectorioPadre, err)
+		}
+
+		if !utils.TienePermisoEscritura(&inodoPadre, op.sesion, "") {
+			return nil, errores.Nuevof(errores.SinPermiso, "No tiene permisos de escritura en el directorio '%s'", rutaDirectorioPadre)
+		}
+
+		posExistente, existe, err := utils.BuscarEnCarpeta(op.file, &op.sb, &inodoPadre, nombreDirectorio)
+		if err != nil {
+			return nil, errores.Nuevof(errores.Interno, "Error buscando directorio en padre '%s': %w", rutaDirectorioPadre, err)
+		}
+		if existe {
+			// mkdir -p sobre una carpeta que ya existe no hace nada; un archivo con ese
+			// nombre sigue siendo un error
+			if padres {
+				existente, err := utils.LeerInodoPorPosicion(op.file, posExistente)
+				if err != nil {
+					return nil, errores.Nuevof(errores.Interno, "Error al leer '%s': %w", ruta, err)
+				}
+				if existente.I_type[0] == '0' {
+					return &ResultadoMkdir{Ruta: ruta}, nil
+				}
+			}
+			return nil, errores.Nuevof(errores.YaExiste, "El directorio '%s' ya existe en '%s'", nombreDirectorio, rutaDirectorioPadre)
+		}
+
+		if err := utils.CrearDirectorioComo(op.file, &op.sb, &inodoPadre, posInodoPadre, nombreDirectorio, op.sesion.UID, op.sesion.GID); err != nil {
+			return nil, errores.Nuevof(errores.Interno, "Error al crear directorio '%s': %w", nombreDirectorio, err)
+		}
+	}
+
+	if err := op.guardarSuperBloque(); err != nil {
+		return nil, err
+	}
+	if err := op.terminar(); err != nil {
+		return nil, err
+	}
+	return &ResultadoMkdir{Ruta: ruta, Creado: true}, nil
+}
+
+// ResultadoArchivo describe el archivo escrito
+type ResultadoArchivo struct {
+	Ruta    string
+	Tamanio int32
+}
+
+// EscribirArchivo crea un archivo en la partición de la sesión. Si ya existe solo se
+// reemplaza su contenido cuando sobrescribir es true. Con padres=true crea también las
+// carpetas intermedias que falten, igual que Mkdir.
+func (m *Motor) EscribirArchivo(ruta string, contenido []byte, sobrescribir bool, padres bool) (*ResultadoArchivo, error) {
+	op, err := m.abrirSesion(true)
+	if err != nil {
+		return nil, err
+	}
+	defer op.cerrar()
+
+	ruta, partes := dividirRuta(ruta)
+	if len(partes) == 0 {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Ruta inválida para archivo")
+	}
+
+	nombreArchivo := partes[len(partes)-1]
+	rutaDirectorioPadre := "/" + strings.Join(partes[:len(partes)-1], "/")
+
+	inodoPadre, posInodoPadre, err := utils.LeerInodoDesdeRuta(op.file, &op.sb, rutaDirectorioPadre)
+	if err != nil && padres && errores.Es(err, errores.NoEncontrado) {
+		if err := utils.CrearDirectoriosRecursivos(op.file, &op.sb, partes[:len(partes)-1], 0, op.sb.S_inode_start, op.sesion); err != nil {
+			return nil, errores.Nuevof(errores.Interno, "Error al crear directorios recursivamente: %w", err)
+		}
+		inodoPadre, posInodoPadre, err = utils.LeerInodoDesdeRuta(op.file, &op.sb, rutaDirectorioPadre)
+	}
+	if err != nil {
+		return nil, errores.Nuevof(errores.Interno, "Error al acceder al directorio padre '%s': %w", rutaDirectorioPadre, err)
+	}
+
+	if !utils.TienePermisoEscritura(&inodoPadre, op.sesion, "") {
+		return nil, errores.Nuevof(errores.SinPermiso, "No tiene permisos de escritura en el directorio '%s'", rutaDirectorioPadre)
+	}
+
+	posInodo, existe, err := utils.BuscarEnCarpeta(op.file, &op.sb, &inodoPadre, nombreArchivo)
+	if err != nil {
+		return nil, errores.Nuevof(errores.Interno, "Error buscando archivo en directorio '%s': %w", rutaDirectorioPadre, err)
+	}
+
+	if existe {
+		if !sobrescribir {
+			return nil, errores.Nuevof(errores.YaExiste, "El archivo '%s' ya existe en '%s'", nombreArchivo, rutaDirectorioPadre)
+		}
+		var inodo structures.TablaInodo
+		if err := utils.LeerEstructura(op.file, posInodo, &inodo); err != nil {
+			return nil, errores.Nuevof(errores.Interno, "Error al leer el inodo de '%s'", ruta)
+		}
+		if inodo.I_type[0] == '0' {
+			return nil, errores.Nuevof(errores.ParametroInvalido, "'%s' es una carpeta", ruta)
+		}
+		if !utils.TienePermisoEscritura(&inodo, op.sesion, "") {
+			return nil, errores.Nuevof(errores.SinPermiso, "No tiene permisos de escritura en '%s'", ruta)
+		}
+		if err := utils.SobrescribirArchivo(op.file, &op.sb, &inodo, posInodo, string(contenido)); err != nil {
+			return nil, errores.Nuevof(errores.Interno, "Error al escribir archivo '%s': %w", nombreArchivo, err)
+		}
+	} else {
+		if err := utils.CrearArchivoComo(op.file, &op.sb, &inodoPadre, posInodoPadre, nombreArchivo, string(contenido), op.sesion.UID, op.sesion.GID); err != nil {
+			return nil, errores.Nuevof(errores.Interno, "Error al crear archivo '%s': %w", nombreArchivo, err)
+		}
+	}
+
+	if err := op.guardarSuperBloque(); err != nil {
+		return nil, err
+	}
+	if err := op.terminar(); err != nil {
+		return nil, err
+	}
+	return &ResultadoArchivo{Ruta: ruta, Tamanio: int32(len(contenido))}, nil
+}
+
+// LeerArchivo devuelve el contenido de un archivo de la partición de la sesión
+func (m *Motor) LeerArchivo(ruta string) ([]byte, error) {
+	op, err := m.abrirSesion(false)
+	if err != nil {
+		return nil, err
+	}
+	defer op.cerrar()
+
+	contenido, err := utils.LeerArchivoDesdeRuta(op.file, &op.sb, ruta, op.sesion)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(contenido), nil
+}
@@ -0,0 +1,633 @@
+package motor
+
+import (
+	"Proyecto/Estructuras/size"
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/utils"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ResultadoMkdisk describe el disco creado
+type ResultadoMkdisk struct {
+	Nombre  string // VDIC-X.mia
+	Ruta    string
+	Tamanio int32 // en bytes
+	Fit     byte
+}
+
+// Mkdisk crea el siguiente disco libre (VDIC-A.mia a VDIC-Z.mia) con su MBR.
+// unidad es 'K' o 'M' y fit 'B', 'F' o 'W'.
+func (m *Motor) Mkdisk(tamanio int32, unidad byte, fit byte) (*ResultadoMkdisk, error) {
+	if tamanio <= 0 {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Valor entero menor o igual a 0")
+	}
+	if unidad != 'K' && unidad != 'M' {
+		return nil, errores.Nuevof(errores.ParametroInvalido, "unidad invalida: %c", unidad)
+	}
+	if !fitValido(fit) {
+		return nil, errores.Nuevof(errores.ParametroInvalido, "Fit invalido: %c", fit)
+	}
+
+	backend := m.Almacenamiento()
+	for i := 0; i < 26; i++ {
+		nombreDisco := fmt.Sprintf("VDIC-%c.mia", 'A'+i)
+		archivo := filepath.Join(m.discos, nombreDisco)
+		if backend.Existe(archivo) {
+			continue
+		}
+
+		tamanioDisco := utils.ObtenerTamanioDisco(tamanio, unidad)
+		if err := crearDisco(backend, archivo, tamanioDisco, fit); err != nil {
+			return nil, err
+		}
+		return &ResultadoMkdisk{Nombre: nombreDisco, Ruta: archivo, Tamanio: tamanioDisco, Fit: fit}, nil
+	}
+
+	return nil, errores.Nuevo(errores.SinEspacio, "No hay más nombres disponibles para discos (límite: VDIC-A.mia a VDIC-Z.mia)")
+}
+
+func crearDisco(backend almacenamiento.Backend, archivo string, tamanioDisco int32, fit byte) error {
+	// el disco se crea ya lleno de ceros
+	file, err := backend.Crear(archivo, int64(tamanioDisco))
+	if err != nil {
+		return errores.Nuevo(errores.Interno, "Error al crear el archivo")
+	}
+	defer file.Close()
+
+	var estructura structures.MBR
+	estructura.Mbr_tamano = tamanioDisco
+	estructura.Mbr_fecha_creacion = utils.ObFechaInt()
+	estructura.Mbr_disk_signature = utils.ObtenerDiskSignature()
+	estructura.Dsk_fit = fit
+	for i := 0; i < len(estructura.Mbr_partitions); i++ {
+		estructura.Mbr_partitions[i] = utils.NuevaPartitionVacia()
+	}
+
+	if err := utils.EscribirEstructura(file, 0, &estructura); err != nil {
+		return errores.Nuevo(errores.Interno, "Error al escribir datos del MBR")
+	}
+	return nil
+}
+
+func fitValido(fit byte) bool {
+	return fit == 'B' || fit == 'F' || fit == 'W'
+}
+
+// OpcionesFdisk describe la partición a crear
+type OpcionesFdisk struct {
+	Disco   string // nombre del disco, con o sin .mia
+	Nombre  string
+	Tamanio int32
+	Unidad  byte // 'B', 'K' o 'M'
+	Tipo    byte // 'P', 'E' o 'L'
+	Fit     byte // 'B', 'F' o 'W'
+}
+
+// ResultadoFdisk describe la partición creada
+type ResultadoFdisk struct {
+	Nombre  string
+	Tipo    byte
+	Inicio  int32 // byte donde empiezan los datos (después del EBR en las lógicas)
+	Tamanio int32
+	Fit     byte
+	Slot    int // posición en el MBR; -1 en las lógicas
+}
+
+// EspacioLibre es un hueco del disco o de la partición extendida
+type EspacioLibre struct {
+	Inicio  int32
+	Tamanio int32
+}
+
+// Fdisk crea una partición primaria, extendida o lógica
+func (m *Motor) Fdisk(op OpcionesFdisk) (*ResultadoFdisk, error) {
+	if op.Nombre == "" {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Valor invalido para el Name")
+	}
+	if op.Tamanio <= 0 {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Valor entero menor o igual a 0")
+	}
+	if !fitValido(op.Fit) {
+		return nil, errores.Nuevof(errores.ParametroInvalido, "Fit invalido: %c", op.Fit)
+	}
+	ruta, err := m.RutaDisco(op.Disco)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Tipo {
+	case 'P', 'E':
+		return m.particionPrimaria(ruta, op)
+	case 'L':
+		return m.particionLogica(ruta, op)
+	default:
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Tipo de partición desconocido. Use P, E o L")
+	}
+}
+
+// abrirDiscoParticiones abre el disco para escribir y revisa que el nombre no esté en uso
+func (m *Motor) abrirDiscoParticiones(ruta string, nombre string) (almacenamiento.Disco, structures.MBR, error) {
+	var mbr structures.MBR
+	if !m.Almacenamiento().Existe(ruta) {
+		return nil, mbr, errores.Nuevof(errores.NoEncontrado, "Disco no encontrado: %s", ruta)
+	}
+
+	file, err := m.AbrirDisco(ruta, true)
+	if err != nil {
+		return nil, mbr, errores.Nuevo(errores.Interno, "Error al abrir el archivo para escritura")
+	}
+	if err := utils.LeerEstructura(file, 0, &mbr); err != nil {
+		file.Close()
+		return nil, mbr, errores.Nuevo(errores.Interno, "Error en la lectura del MBR")
+	}
+	if existe, msg := utils.ExisteNombreParticionEnDisco(file, &mbr, nombre); existe {
+		file.Close()
+		return nil, mbr, errores.Nuevo(errores.YaExiste, msg)
+	}
+	return file, mbr, nil
+}
+
+// particionPrimaria crea una partición primaria o extendida en un slot libre del MBR
+func (m *Motor) particionPrimaria(ruta string, op OpcionesFdisk) (*ResultadoFdisk, error) {
+	file, mbr, err := m.abrirDiscoParticiones(ruta, op.Nombre)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Verificar que no exista ya una partición extendida
+	if op.Tipo == 'E' {
+		for i := range mbr.Mbr_partitions {
+			if mbr.Mbr_partitions[i].Part_type == 'E' && mbr.Mbr_partitions[i].Part_s > 0 {
+				return nil, errores.Nuevo(errores.YaExiste, "Ya existe una partición extendida en el disco")
+			}
+		}
+	}
+
+	countParticiones := 0
+	for i := range mbr.Mbr_partitions {
+		if mbr.Mbr_partitions[i].Part_s > 0 {
+			countParticiones++
+		}
+	}
+
+	if countParticiones >= 4 {
+		return nil, errores.Nuevo(errores.SinEspacio, "No se pueden crear más particiones (máximo 4 primarias/extendidas)")
+	}
+
+	tamanioBytes := utils.ObtenerTamanioDisco(op.Tamanio, op.Unidad)
+	if int64(tamanioBytes) <= 0 {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Tamaño de partición inválido")
+	}
+
+	espaciosLibres := encontrarEspaciosLibres(&mbr)
+	espacioSeleccionado := aplicarAlgoritmoAjuste(espaciosLibres, tamanioBytes, op.Fit)
+	if espacioSeleccionado == nil {
+		return nil, errores.Nuevof(errores.SinEspacio, "No hay espacio suficiente para crear partición de %d bytes", tamanioBytes)
+	}
+
+	posSlot := -1
+	for i := range mbr.Mbr_partitions {
+		if mbr.Mbr_partitions[i].Part_s <= 0 {
+			posSlot = i
+			break
+		}
+	}
+
+	if posSlot == -1 {
+		return nil, errores.Nuevo(errores.SinEspacio, "No hay slots disponibles en el MBR")
+	}
+
+	particion := utils.NuevaPartitionVacia()
+	particion.Part_type = op.Tipo
+	particion.Part_fit = op.Fit
+	copy(particion.Part_name[:], []byte(op.Nombre))
+	particion.Part_s = tamanioBytes
+	particion.Part_start = espacioSeleccionado.Inicio
+	particion.Part_correlative = -1
+	particion.Part_status = int8(-1)
+
+	mbr.Mbr_partitions[posSlot] = particion
+
+	if err := utils.EscribirEstructura(file, 0, &mbr); err != nil {
+		return nil, errores.Nuevo(errores.Interno, "Error al escribir MBR")
+	}
+
+	if op.Tipo == 'E' {
+		// Crear el primer EBR vacío al inicio de la partición extendida
+		primerEBR := crearEBRVacio()
+		if err := utils.EscribirEstructura(file, espacioSeleccionado.Inicio, &primerEBR); err != nil {
+			return nil, errores.Nuevo(errores.Interno, "Error al escribir EBR inicial")
+		}
+	} else {
+		llenarParticionConCeros(file, espacioSeleccionado.Inicio, tamanioBytes)
+	}
+
+	return &ResultadoFdisk{
+		Nombre:  op.Nombre,
+		Tipo:    op.Tipo,
+		Inicio:  espacioSeleccionado.Inicio,
+		Tamanio: tamanioBytes,
+		Fit:     op.Fit,
+		Slot:    posSlot,
+	}, nil
+}
+
+// particionLogica crea una partición lógica con su EBR dentro de la extendida
+func (m *Motor) particionLogica(ruta string, op OpcionesFdisk) (*ResultadoFdisk, error) {
+	file, mbr, err := m.abrirDiscoParticiones(ruta, op.Nombre)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var particionExtendida *structures.Partition
+	for i := range mbr.Mbr_partitions {
+		if mbr.Mbr_partitions[i].Part_type == 'E' && mbr.Mbr_partitions[i].Part_s > 0 {
+			particionExtendida = &mbr.Mbr_partitions[i]
+			break
+		}
+	}
+
+	if particionExtendida == nil {
+		return nil, errores.Nuevo(errores.NoEncontrado, "No existe partición extendida. Créela primero con -type=E")
+	}
+
+	tamanioBytes := utils.ObtenerTamanioDisco(op.Tamanio, op.Unidad)
+	if int64(tamanioBytes) <= 0 {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Tamaño de partición inválido")
+	}
+
+	// Encontrar espacio libre dentro de la extendida
+	espaciosLibres := encontrarEspaciosLibresEnExtendida(file, particionExtendida)
+	espacioSeleccionado := aplicarAlgoritmoAjuste(espaciosLibres, tamanioBytes+size.SizeEBR(), op.Fit)
+
+	if espacioSeleccionado == nil {
+		return nil, errores.Nuevo(errores.SinEspacio, "No hay espacio suficiente en la partición extendida")
+	}
+
+	// Crear nuevo EBR
+	nuevoEBR := structures.EBR{}
+	nuevoEBR.Part_mount = int8(0)
+	nuevoEBR.Part_fit = op.Fit
+	nuevoEBR.Part_start = espacioSeleccionado.Inicio + size.SizeEBR()
+	nuevoEBR.Part_s = tamanioBytes
+	nuevoEBR.Part_next = -1
+	copy(nuevoEBR.Name[:], []byte(op.Nombre))
+
+	// Escribir el nuevo EBR
+	if err := utils.EscribirEstructura(file, espacioSeleccionado.Inicio, &nuevoEBR); err != nil {
+		return nil, errores.Nuevo(errores.Interno, "Error al escribir EBR")
+	}
+
+	// Si no es el primer EBR, actualizar el anterior
+	if espacioSeleccionado.Inicio != particionExtendida.Part_start {
+		actualizarEBRAnterior(file, particionExtendida, espacioSeleccionado.Inicio)
+	}
+
+	return &ResultadoFdisk{
+		Nombre:  op.Nombre,
+		Tipo:    'L',
+		Inicio:  nuevoEBR.Part_start,
+		Tamanio: tamanioBytes,
+		Fit:     op.Fit,
+		Slot:    -1,
+	}, nil
+}
+
+func crearEBRVacio() structures.EBR {
+	var ebr structures.EBR
+	ebr.Part_mount = int8(0)
+	ebr.Part_fit = 'W'
+	ebr.Part_start = -1
+	ebr.Part_s = 0
+	ebr.Part_next = -1
+	return ebr
+}
+
+func encontrarEspaciosLibresEnExtendida(file almacenamiento.Disco, extendida *structures.Partition) []EspacioLibre {
+	espacios := []EspacioLibre{}
+	inicioExtendida := extendida.Part_start
+	finExtendida := extendida.Part_start + extendida.Part_s
+
+	// Leer primer EBR
+	var ebr structures.EBR
+	if err := utils.LeerEstructura(file, inicioExtendida, &ebr); err != nil {
+		return espacios
+	}
+
+	// Si el primer EBR está vacío, toda la extendida está libre
+	if ebr.Part_s == 0 {
+		espacios = append(espacios, EspacioLibre{
+			Inicio:  inicioExtendida,
+			Tamanio: extendida.Part_s,
+		})
+		return espacios
+	}
+
+	// Recolectar todas las particiones lógicas
+	var logicas []structures.EBR
+	logicas = append(logicas, ebr)
+
+	for ebr.Part_next != -1 {
+		if err := utils.LeerEstructura(file, ebr.Part_next, &ebr); err != nil {
+			break
+		}
+		logicas = append(logicas, ebr)
+	}
+
+	// Calcular espacios libres
+	ultimoFin := inicioExtendida
+	for _, logica := range logicas {
+		inicioLogica := logica.Part_start - size.SizeEBR()
+		if inicioLogica > ultimoFin {
+			espacios = append(espacios, EspacioLibre{
+				Inicio:  ultimoFin,
+				Tamanio: inicioLogica - ultimoFin,
+			})
+		}
+		ultimoFin = logica.Part_start + logica.Part_s
+	}
+
+	// Espacio después de la última lógica
+	if ultimoFin < finExtendida {
+		espacios = append(espacios, EspacioLibre{
+			Inicio:  ultimoFin,
+			Tamanio: finExtendida - ultimoFin,
+		})
+	}
+
+	return espacios
+}
+
+func actualizarEBRAnterior(file almacenamiento.Disco, extendida *structures.Partition, nuevaPosicion int32) {
+	var ebr structures.EBR
+	posicionActual := extendida.Part_start
+
+	if err := utils.LeerEstructura(file, posicionActual, &ebr); err != nil {
+		return
+	}
+
+	// Navegar hasta el último EBR
+	for ebr.Part_next != -1 {
+		posicionActual = ebr.Part_next
+		if err := utils.LeerEstructura(file, posicionActual, &ebr); err != nil {
+			return
+		}
+	}
+
+	// Actualizar Part_next del último EBR
+	ebr.Part_next = nuevaPosicion
+	utils.EscribirEstructura(file, posicionActual, &ebr)
+}
+
+func encontrarEspaciosLibres(mbr *structures.MBR) []EspacioLibre {
+	espacios := []EspacioLibre{}
+	var particionesOrdenadas []structures.Partition
+
+	for i := 0; i < 4; i++ {
+		if mbr.Mbr_partitions[i].Part_s > 0 {
+			particionesOrdenadas = append(particionesOrdenadas, mbr.Mbr_partitions[i])
+		}
+	}
+
+	for i := 0; i < len(particionesOrdenadas)-1; i++ {
+		for j := 0; j < len(particionesOrdenadas)-i-1; j++ {
+			if particionesOrdenadas[j].Part_start > particionesOrdenadas[j+1].Part_start {
+				particionesOrdenadas[j], particionesOrdenadas[j+1] = particionesOrdenadas[j+1], particionesOrdenadas[j]
+			}
+		}
+	}
+
+	mbrSize := size.SizeMBR()
+	if len(particionesOrdenadas) == 0 {
+		espacios = append(espacios, EspacioLibre{
+			Inicio:  mbrSize,
+			Tamanio: mbr.Mbr_tamano - mbrSize,
+		})
+		return espacios
+	}
+
+	if particionesOrdenadas[0].Part_start > mbrSize {
+		espacios = append(espacios, EspacioLibre{
+			Inicio:  mbrSize,
+			Tamanio: particionesOrdenadas[0].Part_start - mbrSize,
+		})
+	}
+
+	for i := 0; i < len(particionesOrdenadas)-1; i++ {
+		finActual := particionesOrdenadas[i].Part_start + particionesOrdenadas[i].Part_s
+		inicioSiguiente := particionesOrdenadas[i+1].Part_start
+
+		if inicioSiguiente > finActual {
+			espacios = append(espacios, EspacioLibre{
+				Inicio:  finActual,
+				Tamanio: inicioSiguiente - finActual,
+			})
+		}
+	}
+
+	ultimaParticion := particionesOrdenadas[len(particionesOrdenadas)-1]
+	finUltima := ultimaParticion.Part_start + ultimaParticion.Part_s
+	if finUltima < mbr.Mbr_tamano {
+		espacios = append(espacios, EspacioLibre{
+			Inicio:  finUltima,
+			Tamanio: mbr.Mbr_tamano - finUltima,
+		})
+	}
+
+	return espacios
+}
+
+func aplicarAlgoritmoAjuste(espacios []EspacioLibre, tamanioRequerido int32, tipoFit byte) *EspacioLibre {
+	switch tipoFit {
+	case 'F':
+		return firstFit(espacios, tamanioRequerido)
+	case 'B':
+		return bestFit(espacios, tamanioRequerido)
+	default:
+		return worstFit(espacios, tamanioRequerido)
+	}
+}
+
+func firstFit(espacios []EspacioLibre, tamanio int32) *EspacioLibre {
+	for i := range espacios {
+		if espacios[i].Tamanio >= tamanio {
+			return &espacios[i]
+		}
+	}
+	return nil
+}
+
+func bestFit(espacios []EspacioLibre, tamanio int32) *EspacioLibre {
+	var mejor *EspacioLibre
+	menorDiferencia := int32(0x7FFFFFFF)
+
+	for i := range espacios {
+		if espacios[i].Tamanio >= tamanio {
+			diferencia := espacios[i].Tamanio - tamanio
+			if diferencia < menorDiferencia {
+				menorDiferencia = diferencia
+				mejor = &espacios[i]
+			}
+		}
+	}
+	return mejor
+}
+
+func worstFit(espacios []EspacioLibre, tamanio int32) *EspacioLibre {
+	var peor *EspacioLibre
+	mayorTamanio := int32(0)
+
+	for i := range espacios {
+		if espacios[i].Tamanio >= tamanio && espacios[i].Tamanio > mayorTamanio {
+			mayorTamanio = espacios[i].Tamanio
+			peor = &espacios[i]
+		}
+	}
+	return peor
+}
+
+func llenarParticionConCeros(file almacenamiento.Disco, inicio int32, tamanio int32) error {
+	buffer := make([]byte, 1024)
+	restante := tamanio
+	pos := int64(inicio)
+
+	for restante > 0 {
+		escribir := int32(1024)
+		if restante < escribir {
+			escribir = restante
+		}
+		_, err := file.WriteAt(buffer[:escribir], pos)
+		if err != nil {
+			return err
+		}
+		pos += int64(escribir)
+		restante -= escribir
+	}
+	return nil
+}
+
+// ResultadoMount describe la partición montada
+type ResultadoMount struct {
+	ID          string
+	Disco       string
+	Particion   string
+	Letra       byte
+	Correlativo int32
+	YaMontada   bool // la partición ya estaba montada; ID y Correlativo son los que tenía
+}
+
+// Mount monta una partición primaria y le asigna su ID
+func (m *Motor) Mount(disco string, nombreParticion string) (*ResultadoMount, error) {
+	if nombreParticion == "" {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Valor invalido para el Name")
+	}
+	path, err := m.RutaDisco(disco)
+	if err != nil {
+		return nil, err
+	}
+	nombreCompleto := filepath.Base(path)
+
+	if !m.Almacenamiento().Existe(path) {
+		return nil, errores.Nuevof(errores.NoEncontrado, "Disco no encontrado: %s", path)
+	}
+
+	file, err := m.AbrirDisco(path, true)
+	if err != nil {
+		return nil, errores.Nuevo(errores.Interno, "No se pudo abrir el disco para escritura")
+	}
+	defer file.Close()
+
+	var mbr structures.MBR
+	if err := utils.LeerEstructura(file, 0, &mbr); err != nil {
+		return nil, errores.Nuevo(errores.Interno, "Error en la lectura del MBR")
+	}
+
+	partIndex := -1
+	for i := 0; i < 4; i++ {
+		partName := utils.ConvertirByteAString(mbr.Mbr_partitions[i].Part_name[:])
+		if partName == nombreParticion && mbr.Mbr_partitions[i].Part_s > 0 {
+			partIndex = i
+			break
+		}
+	}
+
+	if partIndex == -1 {
+		return nil, errores.Nuevof(errores.NoEncontrado, "Partición '%s' no encontrada en el disco", nombreParticion)
+	}
+
+	particion := &mbr.Mbr_partitions[partIndex]
+	if particion.Part_type != 'P' {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Solo se pueden montar particiones primarias")
+	}
+
+	if particion.Part_status == 1 {
+		return &ResultadoMount{
+			ID:          utils.ConvertirByteAString(particion.Part_id[:]),
+			Disco:       nombreCompleto,
+			Particion:   nombreParticion,
+			Letra:       obtenerLetraDisco(nombreCompleto),
+			Correlativo: particion.Part_correlative,
+			YaMontada:   true,
+		}, nil
+	}
+
+	letra := obtenerLetraDisco(nombreCompleto)
+	correlativo := calcularCorrelativo(&mbr)
+	idParticion := fmt.Sprintf("%s%d%c", obtenerCarnetHex(), correlativo, letra)
+
+	particion.Part_status = 1
+	particion.Part_correlative = correlativo
+	copy(particion.Part_id[:], idParticion)
+
+	if err := utils.EscribirMBR(file, &mbr); err != nil {
+		return nil, errores.Nuevo(errores.Interno, "No se pudo actualizar el MBR")
+	}
+
+	return &ResultadoMount{
+		ID:          idParticion,
+		Disco:       nombreCompleto,
+		Particion:   nombreParticion,
+		Letra:       letra,
+		Correlativo: correlativo,
+	}, nil
+}
+
+func calcularCorrelativo(mbr *structures.MBR) int32 {
+	count := int32(0)
+	for i := 0; i < 4; i++ {
+		if mbr.Mbr_partitions[i].Part_status == 1 {
+			count++
+		}
+	}
+	return count + 1
+}
+
+func obtenerLetraDisco(nombreDisco string) byte {
+	if strings.HasPrefix(nombreDisco, "VDIC-") && len(nombreDisco) >= 7 {
+		letra := nombreDisco[5]
+		if letra >= 'A' && letra <= 'Z' {
+			return letra
+		}
+		if letra >= 'a' && letra <= 'z' {
+			return letra - 32
+		}
+	}
+	return 'A'
+}
+
+func obtenerCarnetHex() string {
+	carnet := "202308425"
+	if len(carnet) < 2 {
+		return "00"
+	}
+	ultimosDos := carnet[len(carnet)-2:]
+	var num int
+	fmt.Sscanf(ultimosDos, "%d", &num)
+	return fmt.Sprintf("%02X", num)
+}
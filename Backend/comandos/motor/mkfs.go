@@ -0,0 +1,315 @@
+package motor
+
+import (
+	"Proyecto/Estructuras/size"
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/utils"
+	"strings"
+)
+
+// OpcionesMkfs describe el formateo de una partición montada
+type OpcionesMkfs struct {
+	ID      string
+	Tipo    string // solo FULL; vacío usa FULL
+	Sistema string // solo 2fs; vacío usa 2fs
+	Version int32  // structures.FormatoBitmapASCII o FormatoBitmapBits; 0 usa bitmaps ASCII
+}
+
+// ResultadoMkfs describe la partición formateada
+type ResultadoMkfs struct {
+	ID            string
+	Particion     string
+	Tipo          string
+	Version       int32
+	Inodos        int32
+	Bloques       int32
+	InodosLibres  int32
+	BloquesLibres int32
+}
+
+// Mkfs formatea como EXT2 una partición primaria montada y crea /users.txt
+func (m *Motor) Mkfs(op OpcionesMkfs) (*ResultadoMkfs, error) {
+	tipo := strings.ToUpper(strings.TrimSpace(op.Tipo))
+	if tipo == "" {
+		tipo = "FULL"
+	}
+	if tipo != "FULL" {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Solo se soporta formateo FULL")
+	}
+	sistema := strings.ToLower(strings.TrimSpace(op.Sistema))
+	if sistema == "" {
+		sistema = "2fs"
+	}
+	if sistema != "2fs" {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Solo se soporta sistema de archivos 2fs")
+	}
+	version := op.Version
+	if version == 0 {
+		version = structures.FormatoBitmapASCII
+	}
+	if version != structures.FormatoBitmapBits && version != structures.FormatoBitmapASCII {
+		return nil, errores.Nuevof(errores.ParametroInvalido, "Versión de formato inválida '%d' (1 = bitmaps ASCII, 2 = bitmaps empaquetados)", version)
+	}
+
+	// Buscar la partición montada por ID
+	particionMontada, err := m.ParticionMontadaPorID(op.ID)
+	if err != nil {
+		return nil, errores.Nuevof(errores.NoMontada, "Partición con ID '%s' no encontrada o no montada", op.ID)
+	}
+
+	file, err := m.AbrirDisco(particionMontada.DiskPath, true)
+	if err != nil {
+		return nil, errores.Nuevo(errores.Interno, "Error al abrir el disco")
+	}
+	defer file.Close()
+
+	var mbr structures.MBR
+	if err := utils.LeerEstructura(file, 0, &mbr); err != nil {
+		return nil, errores.Nuevo(errores.Interno, "Error en la lectura del MBR")
+	}
+
+	particion := buscarParticionMBR(&mbr, particionMontada.PartName)
+	if particion == nil {
+		return nil, errores.Nuevo(errores.NoEncontrado, "Partición no encontrada en el MBR")
+	}
+
+	// Verificar que sea partición primaria
+	if particion.Part_type != 'P' {
+		return nil, errores.Nuevo(errores.ParametroInvalido, "Solo se pueden formatear particiones primarias")
+	}
+
+	numeroInodos, numeroBloques, err := formatearEXT2(file, particion, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultadoMkfs{
+		ID:            op.ID,
+		Particion:     particionMontada.PartName,
+		Tipo:          tipo,
+		Version:       version,
+		Inodos:        numeroInodos,
+		Bloques:       numeroBloques,
+		InodosLibres:  numeroInodos - 2,
+		BloquesLibres: numeroBloques - 2,
+	}, nil
+}
+
+// formatearEXT2 escribe las estructuras del sistema de archivos y devuelve cuántos inodos
+// y bloques quedaron
+func formatearEXT2(file almacenamiento.Disco, particion *structures.Partition, version int32) (int32, int32, error) {
+	tamanioParticion := particion.Part_s
+	inicioParticion := particion.Part_start
+
+	if tamanioParticion <= size.SizeSuperBloque() {
+		return 0, 0, errores.Nuevo(errores.SinEspacio, "Partición demasiado pequeña para formatear")
+	}
+	// === CÁLCULO REALISTA DE ESTRUCTURAS ===
+	// Tamaño disponible después del SuperBloque
+	tamanioDisponible := int64(tamanioParticion - size.SizeSuperBloque())
+
+	// Tamaños de las estructuras
+	sizeInodo := int64(size.SizeTablaInodo())
+	sizeBloque := int64(size.SizeBloqueArchivo())
+	sizeSuperBloque := int64(size.SizeSuperBloque())
+
+	// Asumir proporción: 1 inodo por cada 10 bloques (razonable para pruebas)
+	// Tamaño por "unidad" = 1 inodo + 10 bloques + overhead de bitmaps
+	// (11 bits empaquetados ≈ 2 bytes, o 11 bytes en ASCII)
+	overheadBitmaps := int64(2)
+	if version == structures.FormatoBitmapASCII {
+		overheadBitmaps = 11
+	}
+	unidadSize := sizeInodo + 10*sizeBloque + overheadBitmaps
+
+	if unidadSize <= 0 {
+		return 0, 0, errores.Nuevo(errores.Interno, "Error en el cálculo de tamaños de estructuras")
+	}
+
+	numeroUnidades := tamanioDisponible / unidadSize
+	if numeroUnidades < 1 {
+		numeroUnidades = 1
+	}
+
+	numeroInodos := int32(numeroUnidades)
+	numeroBloques := numeroInodos * 10
+
+	// Verificar que todo realmente quepa (los bitmaps ASCII usan un byte por elemento)
+	formato := structures.SuperBloque{S_filesistem_type: utils.ConVersionFormato(2, version)}
+	bitmapInodosBytes := utils.TamanioBitmap(&formato, numeroInodos)
+	bitmapBloquesBytes := utils.TamanioBitmap(&formato, numeroBloques)
+	tablaInodosBytes := numeroInodos * int32(sizeInodo)
+	bloquesBytes := numeroBloques * int32(sizeBloque)
+
+	tamanioTotal := sizeSuperBloque +
+		int64(bitmapInodosBytes) +
+		int64(bitmapBloquesBytes) +
+		int64(tablaInodosBytes) +
+		int64(bloquesBytes)
+
+	if tamanioTotal > int64(tamanioParticion) {
+		// Si no cabe, reducir drásticamente
+		numeroInodos = 10
+		numeroBloques = 50
+	}
+
+	if err := utils.LimpiarParticion(file, inicioParticion, tamanioParticion); err != nil {
+		return 0, 0, errores.Nuevo(errores.Interno, "Error al limpiar partición")
+	}
+
+	sb := crearSuperBloque(numeroInodos, numeroBloques, inicioParticion, version)
+	if err := utils.EscribirEstructura(file, inicioParticion, &sb); err != nil {
+		return 0, 0, errores.Nuevo(errores.Interno, "Error al escribir SuperBloque")
+	}
+
+	if err := inicializarBitmaps(file, &sb); err != nil {
+		return 0, 0, errores.Nuevo(errores.Interno, "Error al inicializar bitmaps")
+	}
+
+	inodoRaiz := crearInodoRaiz(&sb)
+	if err := utils.EscribirEstructura(file, sb.S_inode_start, &inodoRaiz); err != nil {
+		return 0, 0, errores.Nuevo(errores.Interno, "Error al escribir inodo raíz")
+	}
+
+	bloqueCarpetaRaiz := crearBloqueCarpetaRaiz(&sb)
+	if err := utils.EscribirEstructura(file, sb.S_block_start, &bloqueCarpetaRaiz); err != nil {
+		return 0, 0, errores.Nuevo(errores.Interno, "Error al escribir bloque carpeta raíz")
+	}
+
+	if err := crearArchivoUsers(file, &sb); err != nil {
+		return 0, 0, errores.Nuevo(errores.Interno, "Error al crear users.txt")
+	}
+
+	return numeroInodos, numeroBloques, nil
+}
+
+func crearSuperBloque(numeroInodos int32, numeroBloques int32, inicioParticion int32, version int32) structures.SuperBloque {
+	var sb structures.SuperBloque
+
+	sb.S_filesistem_type = utils.ConVersionFormato(2, version) // EXT2
+	sb.S_inodes_count = numeroInodos
+	sb.S_blocks_count = numeroBloques
+	sb.S_free_blocks_count = numeroBloques - 2 // -2 por carpeta raíz y users.txt
+	sb.S_free_inodes_count = numeroInodos - 2  // -2 por inodo raíz y users.txt
+	sb.S_mtime = utils.ObFechaInt()
+	sb.S_umtime = 0
+	sb.S_mnt_count = 1
+	sb.S_magic = 0xEF53
+	sb.S_inode_s = size.SizeTablaInodo()
+	sb.S_block_s = size.SizeBloqueArchivo()
+	sb.S_first_ino = 2 // Primer inodo libre (0 y 1 están usados)
+	sb.S_first_blo = 2 // Primer bloque libre (0 y 1 están usados)
+
+	// Calcular posiciones de las estructuras
+	sb.S_bm_inode_start = inicioParticion + size.SizeSuperBloque()
+	sb.S_bm_block_start = sb.S_bm_inode_start + utils.TamanioBitmap(&sb, numeroInodos)
+	sb.S_inode_start = sb.S_bm_block_start + utils.TamanioBitmap(&sb, numeroBloques)
+	sb.S_block_start = sb.S_inode_start + (numeroInodos * size.SizeTablaInodo())
+
+	return sb
+}
+
+// inicializarBitmaps inicializa los bitmaps de inodos y bloques en el formato de la partición
+func inicializarBitmaps(file almacenamiento.Disco, sb *structures.SuperBloque) error {
+	// Los primeros 2 inodos y bloques quedan usados (raíz y users.txt)
+	inodos := []byte(strings.Repeat("0", int(sb.S_inodes_count)))
+	bloques := []byte(strings.Repeat("0", int(sb.S_blocks_count)))
+	inodos[0], inodos[1] = '1', '1'
+	bloques[0], bloques[1] = '1', '1'
+
+	if err := utils.EscribirBitmap(file, sb, sb.S_bm_inode_start, inodos, 0, sb.S_inodes_count); err != nil {
+		return err
+	}
+	return utils.EscribirBitmap(file, sb, sb.S_bm_block_start, bloques, 0, sb.S_blocks_count)
+}
+
+// crearInodoRaiz crea el inodo de la carpeta raíz
+func crearInodoRaiz(sb *structures.SuperBloque) structures.TablaInodo {
+	var inodo structures.TablaInodo
+
+	inodo.I_uid = 1 // Usuario root
+	inodo.I_gid = 1 // Grupo root
+	inodo.I_s = 0   // Tamaño 0 para carpetas
+	inodo.I_atime = utils.ObFechaInt()
+	inodo.I_ctime = utils.ObFechaInt()
+	inodo.I_mtime = utils.ObFechaInt()
+
+	// Inicializar bloques en -1
+	for i := range inodo.I_block {
+		inodo.I_block[i] = -1
+	}
+
+	inodo.I_block[0] = sb.S_block_start // Primer bloque apunta al bloque carpeta
+	inodo.I_type[0] = '0'               // '0' = Carpeta
+	inodo.I_perm[0] = '6'               // Permisos 664
+	inodo.I_perm[1] = '6'
+	inodo.I_perm[2] = '4'
+
+	return inodo
+}
+
+// crearBloqueCarpetaRaiz crea el bloque de carpeta raíz
+func crearBloqueCarpetaRaiz(sb *structures.SuperBloque) structures.BloqueCarpeta {
+	var bloque structures.BloqueCarpeta
+
+	// Entrada 0: . (punto - referencia a sí mismo)
+	copy(bloque.B_content[0].B_name[:], ".")
+	bloque.B_content[0].B_inodo = sb.S_inode_start
+
+	// Entrada 1: .. (punto punto - referencia al padre, en este caso sí mismo)
+	copy(bloque.B_content[1].B_name[:], "..")
+	bloque.B_content[1].B_inodo = sb.S_inode_start
+
+	// Entrada 2: users.txt
+	copy(bloque.B_content[2].B_name[:], "users.txt")
+	bloque.B_content[2].B_inodo = sb.S_inode_start + size.SizeTablaInodo()
+
+	// Entrada 3: vacía
+	bloque.B_content[3].B_inodo = -1
+
+	return bloque
+}
+
+// crearArchivoUsers crea el archivo users.txt con el contenido inicial
+func crearArchivoUsers(file almacenamiento.Disco, sb *structures.SuperBloque) error {
+	// Contenido inicial del archivo users.txt
+	contenido := "1,G,root\n1,U,root,root,123\n"
+
+	// Crear inodo para users.txt
+	var inodoUsers structures.TablaInodo
+	inodoUsers.I_uid = 1
+	inodoUsers.I_gid = 1
+	inodoUsers.I_s = int32(len(contenido))
+	inodoUsers.I_atime = utils.ObFechaInt()
+	inodoUsers.I_ctime = utils.ObFechaInt()
+	inodoUsers.I_mtime = utils.ObFechaInt()
+
+	// Inicializar bloques en -1
+	for i := range inodoUsers.I_block {
+		inodoUsers.I_block[i] = -1
+	}
+
+	inodoUsers.I_block[0] = sb.S_block_start + size.SizeBloqueCarpeta()
+	inodoUsers.I_type[0] = '1' // '1' = Archivo
+	inodoUsers.I_perm[0] = '6' // Permisos 664
+	inodoUsers.I_perm[1] = '6'
+	inodoUsers.I_perm[2] = '4'
+
+	// Escribir inodo de users.txt
+	if err := utils.EscribirEstructura(file, sb.S_inode_start+size.SizeTablaInodo(), &inodoUsers); err != nil {
+		return err
+	}
+
+	// Crear bloque de archivo con el contenido
+	var bloqueArchivo structures.BloqueArchivo
+	copy(bloqueArchivo.B_content[:], contenido)
+
+	// Escribir bloque de archivo
+	if err := utils.EscribirEstructura(file, sb.S_block_start+size.SizeBloqueCarpeta(), &bloqueArchivo); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+// Package motor expone las operaciones de discos y del sistema de archivos como una
+// biblioteca. Cada Motor tiene su propia carpeta raíz, su backend de almacenamiento, su
+// generador de reportes y su sesión, y cada operación usa su propia caché del disco; no
+// lee variables globales ni imprime en consola, devuelve resultados y errores.
+// Los comandos del servidor son adaptadores que traducen parámetros y mensajes.
+package motor
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/utils"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Opciones configura un Motor nuevo
+type Opciones struct {
+	// Raiz es la carpeta base; los discos van en Raiz/Disks y los reportes en Raiz/Rep.
+	// Vacía usa "VDIC-MIA", la misma del servidor.
+	Raiz string
+	// Discos y Reportes reemplazan las carpetas que se derivan de Raiz
+	Discos   string
+	Reportes string
+	// Almacenamiento es el backend de los discos; nil usa archivos del host
+	Almacenamiento almacenamiento.Backend
+	// GeneradorReportes genera los reportes de Motor.Reporte; el paquete Reportes ofrece
+	// Reportes.Generar. Nil deja al motor sin reportes.
+	GeneradorReportes GeneradorReportes
+}
+
+// Motor ejecuta operaciones sobre los discos de una carpeta raíz
+type Motor struct {
+	discos    string
+	reportes  string
+	backend   almacenamiento.Backend
+	generador GeneradorReportes
+
+	bloqueo sync.Mutex
+	sesion  *Sesion
+}
+
+// ParticionMontada representa una partición montada en el sistema
+type ParticionMontada struct {
+	ID          string
+	DiskName    string
+	PartName    string
+	Correlative int32
+	DiskPath    string
+	Partition   structures.Partition
+}
+
+// ErrSinSesion se devuelve en las operaciones que necesitan un usuario con sesión
+var ErrSinSesion = errores.Nuevo(errores.SinSesion, "No hay sesión activa. Use LOGIN primero")
+
+// Nuevo crea un motor con las opciones indicadas
+func Nuevo(op Opciones) *Motor {
+	raiz := op.Raiz
+	if raiz == "" {
+		raiz = "VDIC-MIA"
+	}
+	m := &Motor{
+		discos:    op.Discos,
+		reportes:  op.Reportes,
+		backend:   op.Almacenamiento,
+		generador: op.GeneradorReportes,
+	}
+	if m.discos == "" {
+		m.discos = filepath.Join(raiz, "Disks")
+	}
+	if m.reportes == "" {
+		m.reportes = filepath.Join(raiz, "Rep")
+	}
+	if m.backend == nil {
+		m.backend = almacenamiento.Archivos{}
+	}
+	return m
+}
+
+// DirectorioDiscos devuelve la carpeta donde viven los discos .mia
+func (m *Motor) DirectorioDiscos() string {
+	return m.discos
+}
+
+// DirectorioReportes devuelve la carpeta donde se guardan los reportes
+func (m *Motor) DirectorioReportes() string {
+	return m.reportes
+}
+
+// Almacenamiento devuelve el backend con el que el motor abre los discos
+func (m *Motor) Almacenamiento() almacenamiento.Backend {
+	return m.backend
+}
+
+// AbrirDisco abre un disco del backend del motor
+func (m *Motor) AbrirDisco(ruta string, escritura bool) (almacenamiento.Disco, error) {
+	return m.Almacenamiento().Abrir(ruta, escritura)
+}
+
+// AbrirDiscoConCache abre un disco del backend del motor envuelto en una caché propia de
+// la llamada; Close baja lo pendiente y cierra el disco
+func (m *Motor) AbrirDiscoConCache(ruta string, escritura bool) (*utils.CacheDisco, error) {
+	file, err := m.AbrirDisco(ruta, escritura)
+	if err != nil {
+		return nil, err
+	}
+	return utils.NuevaCache(file), nil
+}
+
+// RutaDisco devuelve la ruta del disco a partir de su nombre; la extensión .mia es opcional.
+// El nombre no puede llevar separadores para que no salga de la carpeta de discos.
+func (m *Motor) RutaDisco(nombre string) (string, error) {
+	nombre = strings.TrimSpace(nombre)
+	if nombre == "" || strings.ContainsAny(nombre, `/\`) {
+		return "", errores.Nuevo(errores.ParametroInvalido, "Valor invalido para el DiskName")
+	}
+	base, extension, tieneExtension := strings.Cut(nombre, ".")
+	if base == "" {
+		return "", errores.Nuevo(errores.ParametroInvalido, "Valor invalido para el DiskName")
+	}
+	if tieneExtension && strings.ToLower(strings.Split(extension, ".")[0]) != "mia" {
+		return "", errores.Nuevo(errores.ParametroInvalido, "Extensión del archivo no válida. Debe ser .mia")
+	}
+	return filepath.Join(m.discos, base+".mia"), nil
+}
+
+// ParticionesMontadas lee todos los discos y devuelve sus particiones montadas
+func (m *Motor) ParticionesMontadas() ([]ParticionMontada, error) {
+	var particiones []ParticionMontada
+
+	discos, err := m.Almacenamiento().Listar(filepath.Join(m.discos, "*.mia"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rutaDisco := range discos {
+		mbr, err := m.LeerMBR(rutaDisco)
+		if err != nil {
+			continue
+		}
+
+		for i := 0; i < 4; i++ {
+			part := mbr.Mbr_partitions[i]
+			if part.Part_status == 1 && part.Part_s > 0 {
+				particiones = append(particiones, ParticionMontada{
+					ID:          strings.TrimSpace(utils.ConvertirByteAString(part.Part_id[:])),
+					DiskName:    filepath.Base(rutaDisco),
+					PartName:    utils.ConvertirByteAString(part.Part_name[:]),
+					Correlative: part.Part_correlative,
+					DiskPath:    rutaDisco,
+					Partition:   part,
+				})
+			}
+		}
+	}
+
+	return particiones, nil
+}
+
+// ParticionMontadaPorID busca una partición montada por su ID en todos los discos
+func (m *Motor) ParticionMontadaPorID(id string) (*ParticionMontada, error) {
+	particiones, err := m.ParticionesMontadas()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, part := range particiones {
+		if part.ID == id {
+			return &part, nil
+		}
+	}
+
+	return nil, errores.Nuevof(errores.NoMontada, "partición con ID '%s' no encontrada o no está montada", id)
+}
+
+// LeerMBR lee el MBR de un disco del motor
+func (m *Motor) LeerMBR(rutaDisco string) (structures.MBR, error) {
+	var mbr structures.MBR
+	file, err := m.AbrirDisco(rutaDisco, false)
+	if err != nil {
+		return mbr, errores.Nuevo(errores.Interno, "Error en la lectura del disco")
+	}
+	defer file.Close()
+	if err := utils.LeerEstructura(file, 0, &mbr); err != nil {
+		return mbr, errores.Nuevo(errores.Interno, "Error en la lectura del MBR")
+	}
+	return mbr, nil
+}
+
+// buscarParticionMBR devuelve la partición del MBR con ese nombre
+func buscarParticionMBR(mbr *structures.MBR, nombre string) *structures.Partition {
+	for i := 0; i < 4; i++ {
+		if utils.ConvertirByteAString(mbr.Mbr_partitions[i].Part_name[:]) == nombre {
+			return &mbr.Mbr_partitions[i]
+		}
+	}
+	return nil
+}
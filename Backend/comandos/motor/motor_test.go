@@ -0,0 +1,158 @@
+package motor
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"strings"
+	"testing"
+)
+
+// motorMontado crea un motor sobre un backend en memoria propio, con un disco de 1 MB y
+// su primera partición montada y formateada con la versión indicada
+func motorMontado(t *testing.T, version int32) (*Motor, string) {
+	t.Helper()
+	m := Nuevo(Opciones{Raiz: "raiz", Almacenamiento: almacenamiento.NuevaMemoria()})
+	disco, err := m.Mkdisk(1, 'M', 'F')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Fdisk(OpcionesFdisk{Disco: disco.Nombre, Nombre: "Part1", Tamanio: 500, Unidad: 'K', Tipo: 'P', Fit: 'F'}); err != nil {
+		t.Fatal(err)
+	}
+	montada, err := m.Mount(disco.Nombre, "Part1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Mkfs(OpcionesMkfs{ID: montada.ID, Version: version}); err != nil {
+		t.Fatal(err)
+	}
+	return m, montada.ID
+}
+
+func TestMotorArchivos(t *testing.T) {
+	for _, version := range []int32{structures.FormatoBitmapASCII, structures.FormatoBitmapBits} {
+		m, id := motorMontado(t, version)
+		if _, err := m.Mkdir("/home", false); !errores.Es(err, errores.SinSesion) {
+			t.Errorf("v%d: mkdir sin sesión devolvió %v", version, err)
+		}
+		if _, err := m.Login("root", "123", id); err != nil {
+			t.Fatalf("v%d: %v", version, err)
+		}
+
+		casos := []struct {
+			ruta      string
+			contenido string
+			padres    bool
+			codigo    errores.Codigo
+		}{
+			{"/a.txt", "uno", false, ""},
+			{"/docs/b.txt", "dos", false, errores.NoEncontrado},
+			{"/docs/b.txt", "dos", true, ""},
+			{"/docs/b.txt", "otra vez", false, errores.YaExiste},
+			{"/grande.txt", strings.Repeat("0123456789", 70), false, ""}, // 11 bloques
+		}
+		for _, c := range casos {
+			_, err := m.EscribirArchivo(c.ruta, []byte(c.contenido), false, c.padres)
+			if c.codigo != "" {
+				if !errores.Es(err, c.codigo) {
+					t.Errorf("v%d %s: error %v, se esperaba %s", version, c.ruta, err, c.codigo)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("v%d %s: %v", version, c.ruta, err)
+				continue
+			}
+			leido, err := m.LeerArchivo(c.ruta)
+			if err != nil || string(leido) != c.contenido {
+				t.Errorf("v%d %s: leído %d bytes (%v), se esperaban %d", version, c.ruta, len(leido), err, len(c.contenido))
+			}
+		}
+	}
+}
+
+func TestMotorMkdirPadres(t *testing.T) {
+	m, id := motorMontado(t, 0)
+	if _, err := m.Login("root", "123", id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.EscribirArchivo("/home/docs/nota.txt", []byte("hola"), false, true); err != nil {
+		t.Fatal(err)
+	}
+
+	casos := []struct {
+		ruta   string
+		padres bool
+		creado bool
+		codigo errores.Codigo
+	}{
+		{"/home/docs/2024", true, true, ""},
+		{"/home/docs/2024", true, false, ""}, // -p sobre una carpeta anidada que ya existe
+		{"/home/docs", true, false, ""},
+		{"/home/docs/2024", false, false, errores.YaExiste},
+		{"/home/docs/nota.txt", true, false, errores.YaExiste}, // es un archivo
+	}
+	for _, c := range casos {
+		res, err := m.Mkdir(c.ruta, c.padres)
+		if c.codigo != "" {
+			if !errores.Es(err, c.codigo) {
+				t.Errorf("%s (-p=%v): error %v, se esperaba %s", c.ruta, c.padres, err, c.codigo)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s (-p=%v): %v", c.ruta, c.padres, err)
+			continue
+		}
+		if res.Creado != c.creado {
+			t.Errorf("%s (-p=%v): creado=%v, se esperaba %v", c.ruta, c.padres, res.Creado, c.creado)
+		}
+	}
+}
+
+// Dos motores no comparten discos ni sesión
+func TestMotoresIndependientes(t *testing.T) {
+	a, id := motorMontado(t, 0)
+	b := Nuevo(Opciones{Raiz: "raiz", Almacenamiento: almacenamiento.NuevaMemoria()})
+
+	if _, err := a.Login("root", "123", id); err != nil {
+		t.Fatal(err)
+	}
+	if b.Sesion() != nil {
+		t.Error("el segundo motor ve la sesión del primero")
+	}
+	if _, err := b.ParticionMontadaPorID(id); !errores.Es(err, errores.NoMontada) {
+		t.Errorf("el segundo motor ve la partición del primero: %v", err)
+	}
+
+	copia := a.Sesion()
+	copia.Usuario = "otro"
+	if a.Sesion().Usuario != "root" {
+		t.Error("cambiar la copia de la sesión cambió la del motor")
+	}
+	if _, err := a.Logout(); err != nil || a.Sesion() != nil {
+		t.Errorf("logout: %v", err)
+	}
+}
+
+func TestMotorReporte(t *testing.T) {
+	op := OpcionesReporte{Nombre: "mbr", ID: "191A", Ruta: "mbr"}
+	sinGenerador := Nuevo(Opciones{Almacenamiento: almacenamiento.NuevaMemoria()})
+	if _, err := sinGenerador.Reporte(op); !errores.Es(err, errores.Interno) {
+		t.Errorf("reporte sin generador devolvió %v", err)
+	}
+
+	var recibido *Motor
+	m := Nuevo(Opciones{
+		Almacenamiento: almacenamiento.NuevaMemoria(),
+		GeneradorReportes: func(m *Motor, op OpcionesReporte) (*ResultadoReporte, error) {
+			recibido = m
+			return &ResultadoReporte{Nombre: op.Nombre}, nil
+		},
+	})
+	res, err := m.Reporte(op)
+	if err != nil || res.Nombre != "mbr" || recibido != m {
+		t.Errorf("el generador no recibió el motor: %v %v", res, err)
+	}
+}
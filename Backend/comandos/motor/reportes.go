@@ -0,0 +1,34 @@
+package motor
+
+import "Proyecto/comandos/errores"
+
+// OpcionesReporte describe el reporte a generar
+type OpcionesReporte struct {
+	Nombre      string // mbr, disk, tree, inode, block, sb, bm_inode, bm_bloc, file, ls, frag o raw
+	ID          string // partición montada; raw usa Disco
+	Ruta        string // ruta del reporte dentro de DirectorioReportes
+	Formato     string // vacío usa el formato predeterminado del reporte
+	RutaArchivo string // archivo o carpeta de la partición para file y ls
+	Disco       string // disco para raw
+	Offset      string // rango de bytes para raw
+	Largo       string
+}
+
+// ResultadoReporte describe el reporte generado
+type ResultadoReporte struct {
+	Nombre  string
+	Formato string
+	Ruta    string // archivo escrito
+	Salida  string // mensaje para mostrar al usuario
+}
+
+// GeneradorReportes genera un reporte con los discos y la carpeta de reportes del motor
+type GeneradorReportes func(m *Motor, op OpcionesReporte) (*ResultadoReporte, error)
+
+// Reporte genera un reporte de una partición montada o de un disco
+func (m *Motor) Reporte(op OpcionesReporte) (*ResultadoReporte, error) {
+	if m.generador == nil {
+		return nil, errores.Nuevo(errores.Interno, "el motor no tiene generador de reportes; indíquelo en Opciones.GeneradorReportes")
+	}
+	return m.generador(m, op)
+}
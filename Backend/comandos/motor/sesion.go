@@ -0,0 +1,70 @@
+package motor
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/errores"
+	"Proyecto/comandos/global"
+)
+
+// Sesion es el usuario que inició sesión en un Motor. El motor guarda su propia copia:
+// cambiar la que devuelve Sesion no cambia la del motor.
+type Sesion struct {
+	Usuario   string
+	UID       int32
+	GID       int32
+	ID        string // partición montada donde se inició la sesión
+	Disco     string // ruta del disco de esa partición
+	Particion structures.Partition
+}
+
+// Credenciales devuelve la sesión con el tipo que usan las revisiones de permisos de
+// utils; con una sesión nil devuelve nil
+func (s *Sesion) Credenciales() *global.SesionUsuario {
+	if s == nil {
+		return nil
+	}
+	particion := s.Particion
+	return &global.SesionUsuario{
+		UsuarioActual: s.Usuario,
+		UID:           s.UID,
+		GID:           s.GID,
+		IDParticion:   s.ID,
+		PathDisco:     s.Disco,
+		Particion:     &particion,
+	}
+}
+
+// Sesion devuelve una copia de la sesión activa del motor, o nil si no hay
+func (m *Motor) Sesion() *Sesion {
+	m.bloqueo.Lock()
+	defer m.bloqueo.Unlock()
+	if m.sesion == nil {
+		return nil
+	}
+	copia := *m.sesion
+	return &copia
+}
+
+// UsarSesion reemplaza la sesión del motor por una copia de la indicada (nil la cierra)
+func (m *Motor) UsarSesion(sesion *Sesion) {
+	m.bloqueo.Lock()
+	defer m.bloqueo.Unlock()
+	if sesion == nil {
+		m.sesion = nil
+		return
+	}
+	copia := *sesion
+	m.sesion = &copia
+}
+
+// Logout cierra la sesión activa y devuelve el usuario que la tenía
+func (m *Motor) Logout() (string, error) {
+	m.bloqueo.Lock()
+	defer m.bloqueo.Unlock()
+	if m.sesion == nil {
+		return "", errores.Nuevo(errores.SinSesion, "No hay sesión activa")
+	}
+	usuario := m.sesion.Usuario
+	m.sesion = nil
+	return usuario, nil
+}
@@ -0,0 +1,221 @@
+// Package sintaxis analiza las líneas de los scripts .smia:
+//
+//	linea      = [ comando { parametro } ] [ comentario ]
+//	comando    = nombre
+//	parametro  = "-" nombre [ "=" valor ]
+//	valor      = palabra | cadena
+//	cadena     = '"' { caracter | '\' escape } '"'    escapes: \" \\ \n \t; otro \x queda literal
+//	comentario = "#" { caracter }                     (al inicio o después de un espacio)
+//
+// Un parámetro sin valor (-p) es una bandera. Cualquier texto que no encaje en la
+// gramática produce un ErrorSintaxis con la línea y la columna donde empieza.
+package sintaxis
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Instruccion es una línea ya analizada
+type Instruccion struct {
+	Linea      int
+	Comando    string            // en minúsculas; vacío si la línea solo tiene un comentario
+	Valores    map[string]string // parámetros con valor, por nombre en minúsculas
+	Banderas   []string          // parámetros escritos sin valor
+	Comentario string
+}
+
+// ErrorSintaxis indica dónde la línea dejó de cumplir la gramática
+type ErrorSintaxis struct {
+	Linea   int
+	Columna int // en caracteres, empezando en 1
+	Mensaje string
+}
+
+func (e *ErrorSintaxis) Error() string {
+	return fmt.Sprintf("línea %d, columna %d: %s", e.Linea, e.Columna, e.Mensaje)
+}
+
+type analizador struct {
+	texto []rune
+	pos   int
+	linea int
+}
+
+// Analizar aplica la gramática a una línea del script; numLinea solo se usa en los errores
+func Analizar(texto string, numLinea int) (*Instruccion, error) {
+	a := &analizador{texto: []rune(texto), linea: numLinea}
+	inst := &Instruccion{Linea: numLinea, Valores: make(map[string]string)}
+
+	a.saltarEspacios()
+	if a.fin() {
+		return inst, nil
+	}
+	if a.actual() == '#' {
+		inst.Comentario = a.comentario()
+		return inst, nil
+	}
+
+	inicio := a.pos
+	nombre := a.nombre()
+	if nombre == "" {
+		return nil, a.errorEn(inicio, "se esperaba el nombre de un comando, se encontró '%c'", a.actual())
+	}
+	inst.Comando = strings.ToLower(nombre)
+	if !a.fin() && !unicode.IsSpace(a.actual()) {
+		return nil, a.errorEn(inicio, "nombre de comando inválido '%s'", a.palabraDesde(inicio))
+	}
+
+	vistos := make(map[string]bool)
+	for {
+		hayEspacio := a.saltarEspacios()
+		if a.fin() {
+			return inst, nil
+		}
+		if !hayEspacio {
+			return nil, a.errorEn(a.pos, "falta un espacio antes de '%c'", a.actual())
+		}
+		if a.actual() == '#' {
+			inst.Comentario = a.comentario()
+			return inst, nil
+		}
+
+		inicio := a.pos
+		if a.actual() != '-' {
+			return nil, a.errorEn(inicio, "texto inesperado '%s', se esperaba un parámetro -nombre", a.palabraDesde(inicio))
+		}
+		a.pos++
+
+		param := strings.ToLower(a.nombre())
+		if param == "" {
+			return nil, a.errorEn(inicio, "se esperaba el nombre del parámetro después de '-'")
+		}
+		if vistos[param] {
+			return nil, a.errorEn(inicio, "parámetro -%s repetido", param)
+		}
+		vistos[param] = true
+
+		if a.fin() || unicode.IsSpace(a.actual()) {
+			inst.Banderas = append(inst.Banderas, param)
+			continue
+		}
+		if a.actual() != '=' {
+			return nil, a.errorEn(a.pos, "carácter inesperado '%c' en el nombre del parámetro -%s", a.actual(), param)
+		}
+		a.pos++
+
+		valor, err := a.valor(param)
+		if err != nil {
+			return nil, err
+		}
+		inst.Valores[param] = valor
+	}
+}
+
+func (a *analizador) fin() bool { return a.pos >= len(a.texto) }
+
+func (a *analizador) actual() rune { return a.texto[a.pos] }
+
+// saltarEspacios avanza sobre los espacios e indica si había alguno
+func (a *analizador) saltarEspacios() bool {
+	inicio := a.pos
+	for !a.fin() && unicode.IsSpace(a.actual()) {
+		a.pos++
+	}
+	return a.pos > inicio
+}
+
+func (a *analizador) comentario() string {
+	resto := string(a.texto[a.pos:])
+	a.pos = len(a.texto)
+	return resto
+}
+
+// nombre lee letras, dígitos y '_' empezando por una letra
+func (a *analizador) nombre() string {
+	inicio := a.pos
+	for !a.fin() {
+		r := a.actual()
+		if unicode.IsLetter(r) || r == '_' || (a.pos > inicio && unicode.IsDigit(r)) {
+			a.pos++
+			continue
+		}
+		break
+	}
+	return string(a.texto[inicio:a.pos])
+}
+
+func (a *analizador) valor(param string) (string, error) {
+	if a.fin() || unicode.IsSpace(a.actual()) {
+		return "", a.errorEn(a.pos, "falta el valor de -%s", param)
+	}
+	if a.actual() == '"' {
+		valor, err := a.cadena()
+		if err != nil {
+			return "", err
+		}
+		if !a.fin() && !unicode.IsSpace(a.actual()) {
+			return "", a.errorEn(a.pos, "texto inesperado después de la cadena de -%s", param)
+		}
+		return valor, nil
+	}
+
+	inicio := a.pos
+	for !a.fin() && !unicode.IsSpace(a.actual()) {
+		if a.actual() == '"' {
+			return "", a.errorEn(a.pos, "comillas dentro de un valor sin comillas en -%s", param)
+		}
+		a.pos++
+	}
+	return string(a.texto[inicio:a.pos]), nil
+}
+
+// cadena lee un valor entre comillas dobles resolviendo los escapes
+func (a *analizador) cadena() (string, error) {
+	inicio := a.pos
+	a.pos++ // comilla de apertura
+
+	var sb strings.Builder
+	for !a.fin() {
+		r := a.actual()
+		switch r {
+		case '"':
+			a.pos++
+			return sb.String(), nil
+		case '\\':
+			if a.pos+1 >= len(a.texto) {
+				return "", a.errorEn(a.pos, "escape incompleto al final de la línea")
+			}
+			switch sig := a.texto[a.pos+1]; sig {
+			case '"', '\\':
+				sb.WriteRune(sig)
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			default:
+				// Rutas de Windows como "C:\Users\x": la barra se conserva tal cual
+				sb.WriteRune('\\')
+				sb.WriteRune(sig)
+			}
+			a.pos += 2
+		default:
+			sb.WriteRune(r)
+			a.pos++
+		}
+	}
+	return "", a.errorEn(inicio, "cadena sin cerrar")
+}
+
+func (a *analizador) palabraDesde(inicio int) string {
+	fin := inicio
+	for fin < len(a.texto) && !unicode.IsSpace(a.texto[fin]) {
+		fin++
+	}
+	return string(a.texto[inicio:fin])
+}
+
+func (a *analizador) errorEn(pos int, formato string, args ...interface{}) *ErrorSintaxis {
+	return &ErrorSintaxis{Linea: a.linea, Columna: pos + 1, Mensaje: fmt.Sprintf(formato, args...)}
+}
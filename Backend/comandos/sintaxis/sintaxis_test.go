@@ -0,0 +1,76 @@
+package sintaxis
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAnalizar(t *testing.T) {
+	casos := []struct {
+		linea      string
+		comando    string
+		valores    map[string]string
+		banderas   []string
+		comentario string
+	}{
+		{"", "", map[string]string{}, nil, ""},
+		{"   # solo comentario", "", map[string]string{}, nil, "# solo comentario"},
+		{"MKDISK -Size=5 -unit=M", "mkdisk", map[string]string{"size": "5", "unit": "M"}, nil, ""},
+		{"mkdir -p -path=/home/user", "mkdir", map[string]string{"path": "/home/user"}, []string{"p"}, ""},
+		{"mkfile -path=/a.txt -r", "mkfile", map[string]string{"path": "/a.txt"}, []string{"r"}, ""},
+		{`mkfile -path="/mis docs/a.txt" -cont="x"`, "mkfile", map[string]string{"path": "/mis docs/a.txt", "cont": "x"}, nil, ""},
+		{`mkfile -cont="dijo \"hola\"\n\tfin\\"`, "mkfile", map[string]string{"cont": "dijo \"hola\"\n\tfin\\"}, nil, ""},
+		{`exec -path="C:\Users\x\a.smia"`, "exec", map[string]string{"path": `C:\Users\x\a.smia`}, nil, ""},
+		{"mount -name=P1 # monta la primera", "mount", map[string]string{"name": "P1"}, nil, "# monta la primera"},
+		{"rep -name=ls -path_file_ls=/home#x", "rep", map[string]string{"name": "ls", "path_file_ls": "/home#x"}, nil, ""},
+		{"logout", "logout", map[string]string{}, nil, ""},
+	}
+	for _, c := range casos {
+		inst, err := Analizar(c.linea, 1)
+		if err != nil {
+			t.Errorf("%q: error inesperado %v", c.linea, err)
+			continue
+		}
+		if inst.Comando != c.comando || inst.Comentario != c.comentario {
+			t.Errorf("%q: comando %q comentario %q", c.linea, inst.Comando, inst.Comentario)
+		}
+		if !reflect.DeepEqual(inst.Valores, c.valores) {
+			t.Errorf("%q: valores %v, se esperaba %v", c.linea, inst.Valores, c.valores)
+		}
+		if !reflect.DeepEqual(inst.Banderas, c.banderas) {
+			t.Errorf("%q: banderas %v, se esperaba %v", c.linea, inst.Banderas, c.banderas)
+		}
+	}
+}
+
+func TestAnalizarErrores(t *testing.T) {
+	casos := []struct {
+		linea   string
+		columna int
+	}{
+		{"-size=5", 1},
+		{"mkdisk size=5", 8},
+		{"mkdisk -=5", 8},
+		{"mkdisk -size=", 14},
+		{"mkdisk -size=5 -size=6", 16},
+		{`mkfile -cont="sin cerrar`, 14},
+		{`mkfile -cont="a"b`, 17},
+		{`mkfile -cont=a"b"`, 15},
+		{`mkfile -cont="a\`, 16},
+		{"mkdisk -si.ze=5", 11},
+		{"mk.disk -size=5", 1},
+		{"  \tmkdisk -size=", 17},
+	}
+	for _, c := range casos {
+		_, err := Analizar(c.linea, 7)
+		var detalle *ErrorSintaxis
+		if !errors.As(err, &detalle) {
+			t.Errorf("%q: se esperaba un ErrorSintaxis, se obtuvo %v", c.linea, err)
+			continue
+		}
+		if detalle.Linea != 7 || detalle.Columna != c.columna {
+			t.Errorf("%q: línea %d columna %d, se esperaba columna %d (%s)", c.linea, detalle.Linea, detalle.Columna, c.columna, detalle.Mensaje)
+		}
+	}
+}
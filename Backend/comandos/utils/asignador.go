@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"fmt"
+)
+
+// Asignador guarda en memoria los bitmaps de inodos y bloques de una partición mientras
+// dura una operación, con un byte '0'/'1' por elemento aunque en disco estén empaquetados.
+// Las búsquedas recorren la copia en memoria y los elementos modificados se escriben de
+// una vez al llamar a Guardar o Terminar, que devuelven el error de escritura.
+type Asignador struct {
+	file         almacenamiento.Disco
+	sb           *structures.SuperBloque
+	fit          byte
+	inodos       []byte
+	bloques      []byte
+	sucioInodos  rangoSucio
+	sucioBloques rangoSucio
+}
+
+// rangoSucio son los índices [desde, hasta) del bitmap que faltan por escribir
+type rangoSucio struct {
+	desde int32
+	hasta int32
+}
+
+func (r *rangoSucio) agregar(indice int32) {
+	if r.desde >= r.hasta {
+		r.desde, r.hasta = indice, indice+1
+		return
+	}
+	if indice < r.desde {
+		r.desde = indice
+	}
+	if indice >= r.hasta {
+		r.hasta = indice + 1
+	}
+}
+
+// IniciarAsignacion carga los bitmaps y deja el asignador en la caché: las funciones de
+// búsqueda y marcado que reciben esa caché usan la copia en memoria hasta llamar a Terminar.
+// fit es el ajuste de la partición: 'F' (primer), 'B' (mejor) o 'W' (peor).
+func IniciarAsignacion(cache *CacheDisco, sb *structures.SuperBloque, fit byte) (*Asignador, error) {
+	a, err := cargarAsignador(cache, sb, fit)
+	if err != nil {
+		return nil, err
+	}
+	cache.asignador = a
+	return a, nil
+}
+
+func cargarAsignador(file almacenamiento.Disco, sb *structures.SuperBloque, fit byte) (*Asignador, error) {
+	a := &Asignador{file: file, sb: sb, fit: normalizarFit(fit)}
+	var err error
+	if a.inodos, err = LeerBitmapInodos(file, sb); err != nil {
+		return nil, fmt.Errorf("error al leer bitmap de inodos: %v", err)
+	}
+	if a.bloques, err = LeerBitmapBloques(file, sb); err != nil {
+		return nil, fmt.Errorf("error al leer bitmap de bloques: %v", err)
+	}
+	return a, nil
+}
+
+func normalizarFit(fit byte) byte {
+	switch fit {
+	case 'B', 'b':
+		return 'B'
+	case 'W', 'w':
+		return 'W'
+	default:
+		return 'F'
+	}
+}
+
+// asignadorDe devuelve el asignador de la operación en curso. Toda operación que asigna
+// o libera inodos y bloques debe llamar antes a IniciarAsignacion sobre la caché que
+// pasa como file; si no lo hizo es un error de programación y se detiene con panic en
+// lugar de devolver "sin espacio" o perder las marcas en silencio.
+func asignadorDe(file almacenamiento.Disco) *Asignador {
+	if c, ok := file.(*CacheDisco); ok && c.asignador != nil {
+		return c.asignador
+	}
+	panic("utils: se buscó o marcó en los bitmaps sin llamar antes a IniciarAsignacion")
+}
+
+// Guardar escribe los bytes modificados de cada bitmap con una sola escritura por bitmap
+func (a *Asignador) Guardar() error {
+	if r := a.sucioInodos; r.desde < r.hasta {
+		if err := EscribirBitmap(a.file, a.sb, a.sb.S_bm_inode_start, a.inodos, r.desde, r.hasta); err != nil {
+			return fmt.Errorf("error al escribir bitmap de inodos: %v", err)
+		}
+		a.sucioInodos = rangoSucio{}
+	}
+	if r := a.sucioBloques; r.desde < r.hasta {
+		if err := EscribirBitmap(a.file, a.sb, a.sb.S_bm_block_start, a.bloques, r.desde, r.hasta); err != nil {
+			return fmt.Errorf("error al escribir bitmap de bloques: %v", err)
+		}
+		a.sucioBloques = rangoSucio{}
+	}
+	return nil
+}
+
+// Terminar guarda los cambios pendientes y deja de usar la copia en memoria
+func (a *Asignador) Terminar() error {
+	if c, ok := a.file.(*CacheDisco); ok && c.asignador == a {
+		c.asignador = nil
+	}
+	return a.Guardar()
+}
+
+// BuscarInodo devuelve la posición del primer inodo libre o -1.
+// Los inodos son todos del mismo tamaño, así que el ajuste no cambia el resultado.
+func (a *Asignador) BuscarInodo() int32 {
+	for i, b := range a.inodos {
+		if b == '0' {
+			return a.sb.S_inode_start + int32(i)*a.sb.S_inode_s
+		}
+	}
+	return -1
+}
+
+// BuscarBloques devuelve las posiciones de n bloques libres sin marcarlos. Busca primero
+// una corrida contigua según el ajuste de la partición y, si ninguna alcanza, usa los
+// primeros bloques libres que encuentre. Devuelve nil si no hay n bloques libres.
+func (a *Asignador) BuscarBloques(n int) []int32 {
+	if n <= 0 {
+		return nil
+	}
+
+	elegido, largoElegido := -1, 0
+	inicio := -1
+	for i := 0; i <= len(a.bloques); i++ {
+		if i < len(a.bloques) && a.bloques[i] == '0' {
+			if inicio == -1 {
+				inicio = i
+			}
+			continue
+		}
+		if inicio == -1 {
+			continue
+		}
+		largo := i - inicio
+		if largo >= n && (elegido == -1 ||
+			(a.fit == 'B' && largo < largoElegido) ||
+			(a.fit == 'W' && largo > largoElegido)) {
+			elegido, largoElegido = inicio, largo
+			if a.fit == 'F' {
+				break
+			}
+		}
+		inicio = -1
+	}
+
+	var posiciones []int32
+	if elegido != -1 {
+		for i := elegido; i < elegido+n; i++ {
+			posiciones = append(posiciones, a.posicionBloque(int32(i)))
+		}
+		return posiciones
+	}
+
+	// Ninguna corrida alcanza: se reparten en los huecos libres
+	for i, b := range a.bloques {
+		if b != '0' {
+			continue
+		}
+		posiciones = append(posiciones, a.posicionBloque(int32(i)))
+		if len(posiciones) == n {
+			return posiciones
+		}
+	}
+	return nil
+}
+
+func (a *Asignador) posicionBloque(indice int32) int32 {
+	return a.sb.S_block_start + indice*a.sb.S_block_s
+}
+
+// MarcarInodo cambia el estado del inodo en la posición indicada ('0' libre, '1' usado)
+func (a *Asignador) MarcarInodo(posicion int32, estado byte) {
+	indice := (posicion - a.sb.S_inode_start) / a.sb.S_inode_s
+	a.marcar(a.inodos, &a.sucioInodos, indice, estado)
+}
+
+// MarcarBloque cambia el estado del bloque en la posición indicada ('0' libre, '1' usado)
+func (a *Asignador) MarcarBloque(posicion int32, estado byte) {
+	indice := (posicion - a.sb.S_block_start) / a.sb.S_block_s
+	a.marcar(a.bloques, &a.sucioBloques, indice, estado)
+}
+
+func (a *Asignador) marcar(bitmap []byte, sucio *rangoSucio, indice int32, estado byte) {
+	if indice < 0 || int(indice) >= len(bitmap) {
+		return
+	}
+	bitmap[indice] = estado
+	sucio.agregar(indice)
+}
+
+// BuscarInodoLIbre busca un inodo libre en el bitmap de inodos; -1 si no hay
+func BuscarInodoLIbre(file almacenamiento.Disco, sb *structures.SuperBloque) int32 {
+	return asignadorDe(file).BuscarInodo()
+}
+
+// MarcarInodoUsado marca un inodo como usado en el bitmap
+func MarcarInodoUsado(file almacenamiento.Disco, sb *structures.SuperBloque, posicionInodo int32) {
+	asignadorDe(file).MarcarInodo(posicionInodo, '1')
+}
+
+// marcarInodoLibre marca un inodo como libre en el bitmap
+func marcarInodoLibre(file almacenamiento.Disco, sb *structures.SuperBloque, posicionInodo int32) {
+	asignadorDe(file).MarcarInodo(posicionInodo, '0')
+}
+
+// BuscarBloqueLIbre busca un bloque libre en el bitmap de bloques; -1 si no hay
+func BuscarBloqueLIbre(file almacenamiento.Disco, sb *structures.SuperBloque) int32 {
+	posiciones := asignadorDe(file).BuscarBloques(1)
+	if posiciones == nil {
+		return -1
+	}
+	return posiciones[0]
+}
+
+// BuscarBloquesLibres busca n bloques libres, contiguos si el espacio lo permite; nil si no hay
+func BuscarBloquesLibres(file almacenamiento.Disco, sb *structures.SuperBloque, n int) []int32 {
+	return asignadorDe(file).BuscarBloques(n)
+}
+
+// MarcarBloqueUsado marca un bloque como usado en el bitmap
+func MarcarBloqueUsado(file almacenamiento.Disco, sb *structures.SuperBloque, posicionBloque int32) {
+	asignadorDe(file).MarcarBloque(posicionBloque, '1')
+}
+
+// marcarBloqueLibre marca un bloque como libre en el bitmap
+func marcarBloqueLibre(file almacenamiento.Disco, sb *structures.SuperBloque, posicionBloque int32) {
+	asignadorDe(file).MarcarBloque(posicionBloque, '0')
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"reflect"
+	"testing"
+)
+
+// Bloques libres: [0,2) [3,7) [8,11) [13,18); los demás ocupados
+const bitmapPrueba = "001000010001100000"
+
+func asignadorPrueba(t *testing.T, fit byte) (*Asignador, *almacenamiento.Memoria) {
+	t.Helper()
+	sb := &structures.SuperBloque{
+		S_filesistem_type: ConVersionFormato(2, structures.FormatoBitmapASCII),
+		S_inodes_count:    4,
+		S_blocks_count:    int32(len(bitmapPrueba)),
+		S_bm_inode_start:  0,
+		S_bm_block_start:  4,
+		S_inode_start:     500,
+		S_inode_s:         100,
+		S_block_start:     1000,
+		S_block_s:         64,
+	}
+	memoria := almacenamiento.NuevaMemoria()
+	disco, _ := memoria.Crear("d.mia", 64)
+	disco.WriteAt([]byte("1100"+bitmapPrueba), 0)
+
+	a, err := IniciarAsignacion(NuevaCache(disco), sb, fit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, memoria
+}
+
+func TestAsignadorAjustes(t *testing.T) {
+	casos := []struct {
+		fit     byte
+		n       int
+		indices []int32 // índices de bloque esperados; nil si no hay lugar
+	}{
+		{'F', 3, []int32{3, 4, 5}},
+		{'B', 3, []int32{8, 9, 10}},
+		{'W', 3, []int32{13, 14, 15}},
+		{'F', 2, []int32{0, 1}},
+		{'B', 2, []int32{0, 1}},
+		{'W', 5, []int32{13, 14, 15, 16, 17}},
+		{'b', 4, []int32{3, 4, 5, 6}},
+		// Ninguna corrida alcanza: se toman los primeros libres
+		{'F', 6, []int32{0, 1, 3, 4, 5, 6}},
+		{'W', 14, []int32{0, 1, 3, 4, 5, 6, 8, 9, 10, 13, 14, 15, 16, 17}},
+		{'F', 15, nil},
+		{'F', 0, nil},
+	}
+	for _, c := range casos {
+		a, _ := asignadorPrueba(t, c.fit)
+		var esperadas []int32
+		for _, i := range c.indices {
+			esperadas = append(esperadas, 1000+i*64)
+		}
+		if obtenidas := a.BuscarBloques(c.n); !reflect.DeepEqual(obtenidas, esperadas) {
+			t.Errorf("fit %c n=%d: %v, se esperaba %v", c.fit, c.n, obtenidas, esperadas)
+		}
+	}
+}
+
+func TestAsignadorMarcaYGuarda(t *testing.T) {
+	a, memoria := asignadorPrueba(t, 'F')
+	if pos := a.BuscarInodo(); pos != 700 {
+		t.Fatalf("primer inodo libre en %d, se esperaba 700", pos)
+	}
+
+	cache := a.file
+	MarcarInodoUsado(cache, a.sb, 700)
+	for _, pos := range a.BuscarBloques(2) {
+		MarcarBloqueUsado(cache, a.sb, pos)
+	}
+	if pos := a.BuscarInodo(); pos != 800 {
+		t.Errorf("después de marcar, primer inodo libre en %d, se esperaba 800", pos)
+	}
+
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+	disco, _ := memoria.Abrir("d.mia", false)
+	bitmaps := make([]byte, 4+len(bitmapPrueba))
+	disco.ReadAt(bitmaps, 0)
+	if esperado := "1110" + "11" + bitmapPrueba[2:]; string(bitmaps) != esperado {
+		t.Errorf("bitmaps en disco %s, se esperaba %s", bitmaps, esperado)
+	}
+}
+
+func TestSinAsignadorSeDetiene(t *testing.T) {
+	disco, _ := almacenamiento.NuevaMemoria().Crear("d.mia", 64)
+	sb := &structures.SuperBloque{S_block_start: 0, S_block_s: 64}
+
+	for nombre, operacion := range map[string]func(){
+		"buscar en el disco":        func() { BuscarBloqueLIbre(disco, sb) },
+		"buscar en una caché":       func() { BuscarInodoLIbre(NuevaCache(disco), sb) },
+		"marcar en una caché":       func() { MarcarBloqueUsado(NuevaCache(disco), sb, 0) },
+		"liberar en una caché":      func() { marcarInodoLibre(NuevaCache(disco), sb, 0) },
+		"buscar varios en el disco": func() { BuscarBloquesLibres(disco, sb, 2) },
+	} {
+		t.Run(nombre, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("se esperaba panic sin IniciarAsignacion")
+				}
+			}()
+			operacion()
+		})
+	}
+}
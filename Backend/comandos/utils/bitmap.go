@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+)
+
+// VersionFormato devuelve la versión del formato de la partición; las formateadas antes
+// de que existiera la versión usan bitmaps ASCII
+func VersionFormato(sb *structures.SuperBloque) int32 {
+	version := (sb.S_filesistem_type >> 8) & 0xFF
+	if version == 0 {
+		return structures.FormatoBitmapASCII
+	}
+	return version
+}
+
+// TipoSistemaArchivos devuelve el tipo de sistema de archivos sin la versión (2 = EXT2)
+func TipoSistemaArchivos(sb *structures.SuperBloque) int32 {
+	return sb.S_filesistem_type & 0xFF
+}
+
+// ConVersionFormato combina el tipo de sistema de archivos con la versión del formato
+func ConVersionFormato(tipo int32, version int32) int32 {
+	return tipo | version<<8
+}
+
+// BitmapsEmpaquetados indica si los bitmaps de la partición guardan un bit por elemento
+func BitmapsEmpaquetados(sb *structures.SuperBloque) bool {
+	return VersionFormato(sb) == structures.FormatoBitmapBits
+}
+
+// TamanioBitmap devuelve cuántos bytes ocupa en disco un bitmap de cantidad elementos
+func TamanioBitmap(sb *structures.SuperBloque, cantidad int32) int32 {
+	if BitmapsEmpaquetados(sb) {
+		return (cantidad + 7) / 8
+	}
+	return cantidad
+}
+
+// LeerBitmap lee un bitmap de cantidad elementos y lo devuelve con un byte '0'/'1' por
+// elemento, sin importar cómo esté guardado
+func LeerBitmap(file almacenamiento.Disco, sb *structures.SuperBloque, inicio int32, cantidad int32) ([]byte, error) {
+	datos := make([]byte, TamanioBitmap(sb, cantidad))
+	if err := LeerBytes(file, int64(inicio), datos); err != nil {
+		return nil, err
+	}
+	if !BitmapsEmpaquetados(sb) {
+		return datos, nil
+	}
+
+	bitmap := make([]byte, cantidad)
+	for i := range bitmap {
+		bitmap[i] = '0'
+		if datos[i/8]&(1<<(i%8)) != 0 {
+			bitmap[i] = '1'
+		}
+	}
+	return bitmap, nil
+}
+
+// LeerBitmapInodos lee el bitmap de inodos con un byte '0'/'1' por inodo
+func LeerBitmapInodos(file almacenamiento.Disco, sb *structures.SuperBloque) ([]byte, error) {
+	return LeerBitmap(file, sb, sb.S_bm_inode_start, sb.S_inodes_count)
+}
+
+// LeerBitmapBloques lee el bitmap de bloques con un byte '0'/'1' por bloque
+func LeerBitmapBloques(file almacenamiento.Disco, sb *structures.SuperBloque) ([]byte, error) {
+	return LeerBitmap(file, sb, sb.S_bm_block_start, sb.S_blocks_count)
+}
+
+// EscribirBitmap guarda los elementos [desde, hasta) de bitmap ('0'/'1' por elemento) en el
+// formato de la partición. En bitmaps empaquetados se reescriben los bytes completos que
+// los contienen, por eso se recibe el bitmap entero.
+func EscribirBitmap(file almacenamiento.Disco, sb *structures.SuperBloque, inicio int32, bitmap []byte, desde, hasta int32) error {
+	if desde >= hasta {
+		return nil
+	}
+	if !BitmapsEmpaquetados(sb) {
+		return EscribirBytes(file, int64(inicio+desde), bitmap[desde:hasta])
+	}
+
+	primerByte, ultimoByte := desde/8, (hasta-1)/8
+	datos := make([]byte, ultimoByte-primerByte+1)
+	for i := primerByte * 8; i < (ultimoByte+1)*8 && i < int32(len(bitmap)); i++ {
+		if bitmap[i] == '1' {
+			datos[i/8-primerByte] |= 1 << (i % 8)
+		}
+	}
+	return EscribirBytes(file, int64(inicio+primerByte), datos)
+}
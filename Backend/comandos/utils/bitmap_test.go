@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"bytes"
+	"testing"
+)
+
+func TestBitmapsPorVersion(t *testing.T) {
+	const inicio = 8
+	casos := []struct {
+		version int32
+		bitmap  string
+		enDisco []byte // bytes del bitmap tal como quedan en el disco
+	}{
+		{structures.FormatoBitmapASCII, "1011000001", []byte("1011000001")},
+		{structures.FormatoBitmapBits, "1011000001", []byte{0x0D, 0x02}},
+		{structures.FormatoBitmapBits, "00000000111", []byte{0x00, 0x07}},
+	}
+	for _, c := range casos {
+		sb := &structures.SuperBloque{S_filesistem_type: ConVersionFormato(2, c.version)}
+		cantidad := int32(len(c.bitmap))
+		if tam := TamanioBitmap(sb, cantidad); tam != int32(len(c.enDisco)) {
+			t.Errorf("v%d %s: TamanioBitmap %d, se esperaba %d", c.version, c.bitmap, tam, len(c.enDisco))
+			continue
+		}
+
+		disco, _ := almacenamiento.NuevaMemoria().Crear("d.mia", 64)
+		if err := EscribirBitmap(disco, sb, inicio, []byte(c.bitmap), 0, cantidad); err != nil {
+			t.Fatal(err)
+		}
+		crudo := make([]byte, len(c.enDisco))
+		disco.ReadAt(crudo, inicio)
+		if !bytes.Equal(crudo, c.enDisco) {
+			t.Errorf("v%d %s: en disco %v, se esperaba %v", c.version, c.bitmap, crudo, c.enDisco)
+		}
+
+		leido, err := LeerBitmap(disco, sb, inicio, cantidad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(leido) != c.bitmap {
+			t.Errorf("v%d: leído %s, se esperaba %s", c.version, leido, c.bitmap)
+		}
+	}
+}
+
+// Escribir un rango de un bitmap empaquetado reescribe los bytes completos sin perder
+// los bits vecinos que comparten byte
+func TestBitmapEmpaquetadoRangoParcial(t *testing.T) {
+	sb := &structures.SuperBloque{S_filesistem_type: ConVersionFormato(2, structures.FormatoBitmapBits)}
+	disco, _ := almacenamiento.NuevaMemoria().Crear("d.mia", 4)
+	bitmap := []byte("1100000000000011")
+	if err := EscribirBitmap(disco, sb, 0, bitmap, 0, 16); err != nil {
+		t.Fatal(err)
+	}
+
+	bitmap[5] = '1'
+	if err := EscribirBitmap(disco, sb, 0, bitmap, 5, 6); err != nil {
+		t.Fatal(err)
+	}
+	leido, _ := LeerBitmap(disco, sb, 0, 16)
+	if string(leido) != "1100010000000011" {
+		t.Errorf("bitmap %s después de marcar el 5", leido)
+	}
+}
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"Proyecto/comandos/almacenamiento"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// tamPaginaCache es el tamaño de cada página que la caché lee del disco de una vez
+const tamPaginaCache = 4096
+
+// CacheDisco guarda en memoria las páginas del disco que se leen durante una operación.
+// Las escrituras solo modifican la página en memoria; Flush las baja al archivo.
+// No se descartan páginas: una operación toca a lo sumo una partición.
+//
+// La caché es un almacenamiento.Disco: quien la crea con NuevaCache la pasa en lugar del
+// archivo a las funciones de lectura y escritura de estructuras.
+type CacheDisco struct {
+	file      almacenamiento.Disco
+	paginas   map[int64]*paginaCache
+	asignador *Asignador // asignador de la operación, si se inició sobre la caché
+}
+
+type paginaCache struct {
+	datos []byte
+	largo int // bytes válidos (menos de tamPaginaCache si la página pasa el fin del archivo)
+	sucia bool
+}
+
+// NuevaCache envuelve el disco en una caché propia. Las lecturas y escrituras sobre la
+// caché devuelta pasan por memoria y Close baja lo pendiente antes de cerrar el disco.
+func NuevaCache(file almacenamiento.Disco) *CacheDisco {
+	return &CacheDisco{file: file, paginas: make(map[int64]*paginaCache)}
+}
+
+// Flush escribe las páginas modificadas en orden de posición; las páginas contiguas
+// se escriben juntas
+func (c *CacheDisco) Flush() error {
+	var sucias []int64
+	for numero, p := range c.paginas {
+		if p.sucia {
+			sucias = append(sucias, numero)
+		}
+	}
+	sort.Slice(sucias, func(i, j int) bool { return sucias[i] < sucias[j] })
+
+	for i := 0; i < len(sucias); {
+		inicio := sucias[i]
+		datos := append([]byte(nil), c.paginas[inicio].datos[:c.paginas[inicio].largo]...)
+		j := i + 1
+		for j < len(sucias) && sucias[j] == sucias[j-1]+1 && c.paginas[sucias[j-1]].largo == tamPaginaCache {
+			p := c.paginas[sucias[j]]
+			datos = append(datos, p.datos[:p.largo]...)
+			j++
+		}
+		if _, err := c.file.WriteAt(datos, inicio*tamPaginaCache); err != nil {
+			return fmt.Errorf("error al escribir caché en %d: %v", inicio*tamPaginaCache, err)
+		}
+		for _, numero := range sucias[i:j] {
+			c.paginas[numero].sucia = false
+		}
+		i = j
+	}
+	return nil
+}
+
+// Descartar olvida las páginas modificadas y el asignador sin escribirlos, así una
+// operación que falló a medias deja el disco como estaba. La caché sigue sirviendo para
+// leer y Close ya no tiene nada que bajar.
+func (c *CacheDisco) Descartar() {
+	c.paginas = make(map[int64]*paginaCache)
+	c.asignador = nil
+}
+
+// TerminarEscritura baja al disco los bitmaps del asignador (si hay) y después las
+// páginas de la caché. Las operaciones que modifican el disco la llaman antes de armar
+// su resultado; el Close diferido queda solo para los caminos de error.
+func TerminarEscritura(cache *CacheDisco, asignador *Asignador) error {
+	if asignador != nil {
+		if err := asignador.Terminar(); err != nil {
+			return err
+		}
+	}
+	return cache.Flush()
+}
+
+func (c *CacheDisco) pagina(numero int64) (*paginaCache, error) {
+	if p, ok := c.paginas[numero]; ok {
+		return p, nil
+	}
+	p := &paginaCache{datos: make([]byte, tamPaginaCache)}
+	n, err := c.file.ReadAt(p.datos, numero*tamPaginaCache)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	p.largo = n
+	c.paginas[numero] = p
+	return p, nil
+}
+
+// ReadAt lee desde las páginas en memoria; devuelve io.EOF si pasa el fin del disco
+func (c *CacheDisco) ReadAt(buf []byte, pos int64) (int, error) {
+	hecho := 0
+	for hecho < len(buf) {
+		actual := pos + int64(hecho)
+		p, err := c.pagina(actual / tamPaginaCache)
+		if err != nil {
+			return hecho, err
+		}
+		desde := int(actual % tamPaginaCache)
+		if desde >= p.largo {
+			return hecho, io.EOF
+		}
+		hecho += copy(buf[hecho:], p.datos[desde:p.largo])
+	}
+	return hecho, nil
+}
+
+// WriteAt modifica las páginas en memoria; el disco cambia al llamar a Flush
+func (c *CacheDisco) WriteAt(datos []byte, pos int64) (int, error) {
+	hecho := 0
+	for hecho < len(datos) {
+		actual := pos + int64(hecho)
+		p, err := c.pagina(actual / tamPaginaCache)
+		if err != nil {
+			return hecho, err
+		}
+		desde := int(actual % tamPaginaCache)
+		n := copy(p.datos[desde:], datos[hecho:])
+		if desde+n > p.largo {
+			p.largo = desde + n
+		}
+		p.sucia = true
+		hecho += n
+	}
+	return hecho, nil
+}
+
+// Size devuelve el tamaño del disco contando lo escrito en memoria más allá del final
+func (c *CacheDisco) Size() (int64, error) {
+	tamanio, err := c.file.Size()
+	if err != nil {
+		return 0, err
+	}
+	for numero, p := range c.paginas {
+		if fin := numero*tamPaginaCache + int64(p.largo); fin > tamanio {
+			tamanio = fin
+		}
+	}
+	return tamanio, nil
+}
+
+// Sync baja lo pendiente y sincroniza el disco
+func (c *CacheDisco) Sync() error {
+	if err := c.Flush(); err != nil {
+		return err
+	}
+	return c.file.Sync()
+}
+
+// Close guarda el asignador y las páginas pendientes y cierra el disco
+func (c *CacheDisco) Close() error {
+	err := TerminarEscritura(c, c.asignador)
+	if errCerrar := c.file.Close(); err == nil {
+		err = errCerrar
+	}
+	return err
+}
+
+func (c *CacheDisco) leer(pos int64, buf []byte) error {
+	if _, err := c.ReadAt(buf, pos); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *CacheDisco) escribir(pos int64, datos []byte) error {
+	_, err := c.WriteAt(datos, pos)
+	return err
+}
+
+// LeerBytes llena buf con lo que hay en pos; si file es una caché lee de sus páginas
+func LeerBytes(file almacenamiento.Disco, pos int64, buf []byte) error {
+	if c, ok := file.(*CacheDisco); ok {
+		return c.leer(pos, buf)
+	}
+	_, err := file.ReadAt(buf, pos)
+	return err
+}
+
+// EscribirBytes escribe datos en pos; si file es una caché modifica sus páginas
+func EscribirBytes(file almacenamiento.Disco, pos int64, datos []byte) error {
+	if c, ok := file.(*CacheDisco); ok {
+		return c.escribir(pos, datos)
+	}
+	_, err := file.WriteAt(datos, pos)
+	return err
+}
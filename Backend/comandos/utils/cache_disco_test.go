@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"Proyecto/comandos/almacenamiento"
+	"bytes"
+	"testing"
+)
+
+func TestCachesSobreElMismoDisco(t *testing.T) {
+	disco, err := almacenamiento.NuevaMemoria().Crear("d.mia", 2*tamPaginaCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cada caché es independiente: lo que escribe una no lo ve el disco ni la otra hasta Flush
+	primera, segunda := NuevaCache(disco), NuevaCache(disco)
+	if err := EscribirBytes(primera, tamPaginaCache-2, []byte("hola")); err != nil {
+		t.Fatal(err)
+	}
+
+	leido := make([]byte, 4)
+	if err := LeerBytes(primera, tamPaginaCache-2, leido); err != nil || string(leido) != "hola" {
+		t.Fatalf("la caché leyó %q (%v)", leido, err)
+	}
+	for nombre, file := range map[string]almacenamiento.Disco{"disco": disco, "otra caché": NuevaCache(disco)} {
+		if err := LeerBytes(file, tamPaginaCache-2, leido); err != nil || !bytes.Equal(leido, make([]byte, 4)) {
+			t.Errorf("%s: leyó %q (%v) antes del Flush", nombre, leido, err)
+		}
+	}
+
+	if err := primera.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := LeerBytes(disco, tamPaginaCache-2, leido); err != nil || string(leido) != "hola" {
+		t.Errorf("el disco leyó %q (%v) después del Flush", leido, err)
+	}
+	// La segunda caché no había cargado esas páginas, así que ya ve lo escrito
+	if err := LeerBytes(segunda, tamPaginaCache-2, leido); err != nil || string(leido) != "hola" {
+		t.Errorf("la segunda caché leyó %q (%v)", leido, err)
+	}
+}
+
+func TestDescartarDejaElDiscoIntacto(t *testing.T) {
+	a, memoria := asignadorPrueba(t, 'F')
+	cache := a.file.(*CacheDisco)
+	MarcarInodoUsado(cache, a.sb, 700)
+	if err := EscribirBytes(cache, 100, []byte("a medias")); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Descartar()
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	disco, _ := memoria.Abrir("d.mia", false)
+	contenido := make([]byte, 108)
+	disco.ReadAt(contenido, 0)
+	if esperado := "1100" + bitmapPrueba; string(contenido[:len(esperado)]) != esperado {
+		t.Errorf("bitmaps en disco %s, se esperaba %s", contenido[:len(esperado)], esperado)
+	}
+	if !bytes.Equal(contenido[100:], make([]byte, 8)) {
+		t.Errorf("el disco tiene %q en 100 después de Descartar", contenido[100:])
+	}
+}
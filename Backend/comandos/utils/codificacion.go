@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Codificadores escritos a mano para las estructuras del sistema de archivos.
+// Producen exactamente los mismos bytes que binary.Write en little endian (sin relleno),
+// pero sin pasar por reflexión en cada lectura.
+
+const (
+	tamSuperBloque     = 17 * 4
+	tamInodo           = 21*4 + 1 + 3
+	tamContent         = 12 + 4
+	tamBloqueCarpeta   = 4 * tamContent
+	tamBloqueArchivo   = 64
+	tamBloqueApuntador = 16 * 4
+)
+
+func leerInt32(b []byte) int32 {
+	return int32(binary.LittleEndian.Uint32(b))
+}
+
+func ponerInt32(b []byte, v int32) {
+	binary.LittleEndian.PutUint32(b, uint32(v))
+}
+
+func decodificarSuperBloque(b []byte, sb *structures.SuperBloque) {
+	campos := []*int32{
+		&sb.S_filesistem_type, &sb.S_inodes_count, &sb.S_blocks_count, &sb.S_free_blocks_count,
+		&sb.S_free_inodes_count, &sb.S_mtime, &sb.S_umtime, &sb.S_mnt_count, &sb.S_magic,
+		&sb.S_inode_s, &sb.S_block_s, &sb.S_first_ino, &sb.S_first_blo, &sb.S_bm_inode_start,
+		&sb.S_bm_block_start, &sb.S_inode_start, &sb.S_block_start,
+	}
+	for i, c := range campos {
+		*c = leerInt32(b[i*4:])
+	}
+}
+
+func codificarSuperBloque(sb *structures.SuperBloque, b []byte) {
+	campos := []int32{
+		sb.S_filesistem_type, sb.S_inodes_count, sb.S_blocks_count, sb.S_free_blocks_count,
+		sb.S_free_inodes_count, sb.S_mtime, sb.S_umtime, sb.S_mnt_count, sb.S_magic,
+		sb.S_inode_s, sb.S_block_s, sb.S_first_ino, sb.S_first_blo, sb.S_bm_inode_start,
+		sb.S_bm_block_start, sb.S_inode_start, sb.S_block_start,
+	}
+	for i, c := range campos {
+		ponerInt32(b[i*4:], c)
+	}
+}
+
+func decodificarInodo(b []byte, inodo *structures.TablaInodo) {
+	inodo.I_uid = leerInt32(b[0:])
+	inodo.I_gid = leerInt32(b[4:])
+	inodo.I_s = leerInt32(b[8:])
+	inodo.I_atime = leerInt32(b[12:])
+	inodo.I_ctime = leerInt32(b[16:])
+	inodo.I_mtime = leerInt32(b[20:])
+	for i := range inodo.I_block {
+		inodo.I_block[i] = leerInt32(b[24+i*4:])
+	}
+	copy(inodo.I_type[:], b[84:85])
+	copy(inodo.I_perm[:], b[85:88])
+}
+
+func codificarInodo(inodo *structures.TablaInodo, b []byte) {
+	ponerInt32(b[0:], inodo.I_uid)
+	ponerInt32(b[4:], inodo.I_gid)
+	ponerInt32(b[8:], inodo.I_s)
+	ponerInt32(b[12:], inodo.I_atime)
+	ponerInt32(b[16:], inodo.I_ctime)
+	ponerInt32(b[20:], inodo.I_mtime)
+	for i, p := range inodo.I_block {
+		ponerInt32(b[24+i*4:], p)
+	}
+	copy(b[84:85], inodo.I_type[:])
+	copy(b[85:88], inodo.I_perm[:])
+}
+
+func decodificarBloqueCarpeta(b []byte, bloque *structures.BloqueCarpeta) {
+	for i := range bloque.B_content {
+		base := i * tamContent
+		copy(bloque.B_content[i].B_name[:], b[base:base+12])
+		bloque.B_content[i].B_inodo = leerInt32(b[base+12:])
+	}
+}
+
+func codificarBloqueCarpeta(bloque *structures.BloqueCarpeta, b []byte) {
+	for i, entrada := range bloque.B_content {
+		base := i * tamContent
+		copy(b[base:base+12], entrada.B_name[:])
+		ponerInt32(b[base+12:], entrada.B_inodo)
+	}
+}
+
+func decodificarBloqueApuntador(b []byte, bloque *structures.BloqueApuntador) {
+	for i := range bloque.B_pointers {
+		bloque.B_pointers[i] = leerInt32(b[i*4:])
+	}
+}
+
+func codificarBloqueApuntador(bloque *structures.BloqueApuntador, b []byte) {
+	for i, p := range bloque.B_pointers {
+		ponerInt32(b[i*4:], p)
+	}
+}
+
+// tamanioEstructura devuelve cuántos bytes ocupa v en el disco
+func tamanioEstructura(v interface{}) int {
+	switch v.(type) {
+	case *structures.SuperBloque:
+		return tamSuperBloque
+	case *structures.TablaInodo:
+		return tamInodo
+	case *structures.BloqueCarpeta:
+		return tamBloqueCarpeta
+	case *structures.BloqueArchivo:
+		return tamBloqueArchivo
+	case *structures.BloqueApuntador:
+		return tamBloqueApuntador
+	default:
+		return binary.Size(v)
+	}
+}
+
+// LeerEstructura lee y decodifica en v la estructura que está en pos.
+// v debe ser un puntero (*structures.TablaInodo, *structures.BloqueCarpeta, ...).
+func LeerEstructura(file almacenamiento.Disco, pos int32, v interface{}) error {
+	tam := tamanioEstructura(v)
+	if tam <= 0 {
+		return fmt.Errorf("tipo no soportado: %T", v)
+	}
+	buf := make([]byte, tam)
+	if err := LeerBytes(file, int64(pos), buf); err != nil {
+		return err
+	}
+
+	switch e := v.(type) {
+	case *structures.SuperBloque:
+		decodificarSuperBloque(buf, e)
+	case *structures.TablaInodo:
+		decodificarInodo(buf, e)
+	case *structures.BloqueCarpeta:
+		decodificarBloqueCarpeta(buf, e)
+	case *structures.BloqueArchivo:
+		copy(e.B_content[:], buf)
+	case *structures.BloqueApuntador:
+		decodificarBloqueApuntador(buf, e)
+	default:
+		return binary.Read(bytes.NewReader(buf), binary.LittleEndian, v)
+	}
+	return nil
+}
+
+// EscribirEstructura codifica v y la escribe en pos
+func EscribirEstructura(file almacenamiento.Disco, pos int32, v interface{}) error {
+	tam := tamanioEstructura(v)
+	if tam <= 0 {
+		return fmt.Errorf("tipo no soportado: %T", v)
+	}
+	buf := make([]byte, tam)
+
+	switch e := v.(type) {
+	case *structures.SuperBloque:
+		codificarSuperBloque(e, buf)
+	case *structures.TablaInodo:
+		codificarInodo(e, buf)
+	case *structures.BloqueCarpeta:
+		codificarBloqueCarpeta(e, buf)
+	case *structures.BloqueArchivo:
+		copy(buf, e.B_content[:])
+	case *structures.BloqueApuntador:
+		codificarBloqueApuntador(e, buf)
+	default:
+		var salida bytes.Buffer
+		if err := binary.Write(&salida, binary.LittleEndian, v); err != nil {
+			return err
+		}
+		buf = salida.Bytes()
+	}
+	return EscribirBytes(file, int64(pos), buf)
+}
+
+// LeerBloqueCarpeta lee el bloque de carpeta que está en pos
+func LeerBloqueCarpeta(file almacenamiento.Disco, pos int32) (structures.BloqueCarpeta, error) {
+	var bloque structures.BloqueCarpeta
+	err := LeerEstructura(file, pos, &bloque)
+	return bloque, err
+}
+
+// LeerBloqueArchivo lee el bloque de archivo que está en pos
+func LeerBloqueArchivo(file almacenamiento.Disco, pos int32) (structures.BloqueArchivo, error) {
+	var bloque structures.BloqueArchivo
+	err := LeerEstructura(file, pos, &bloque)
+	return bloque, err
+}
+
+// LeerBloqueApuntador lee el bloque de apuntadores que está en pos
+func LeerBloqueApuntador(file almacenamiento.Disco, pos int32) (structures.BloqueApuntador, error) {
+	var bloque structures.BloqueApuntador
+	err := LeerEstructura(file, pos, &bloque)
+	return bloque, err
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// La codificación manual debe escribir exactamente lo mismo que binary.Write y leer de
+// vuelta la misma estructura
+func TestCodificacionIgualABinaryWrite(t *testing.T) {
+	casos := []struct {
+		nombre string
+		nuevo  func() interface{}
+	}{
+		{"SuperBloque", func() interface{} { return &structures.SuperBloque{} }},
+		{"TablaInodo", func() interface{} { return &structures.TablaInodo{} }},
+		{"BloqueCarpeta", func() interface{} { return &structures.BloqueCarpeta{} }},
+		{"BloqueArchivo", func() interface{} { return &structures.BloqueArchivo{} }},
+		{"BloqueApuntador", func() interface{} { return &structures.BloqueApuntador{} }},
+	}
+	azar := rand.New(rand.NewSource(1))
+
+	for _, c := range casos {
+		original := c.nuevo()
+		tam := binary.Size(original)
+		if tam != tamanioEstructura(original) {
+			t.Errorf("%s: binary.Size %d, tamanioEstructura %d", c.nombre, tam, tamanioEstructura(original))
+			continue
+		}
+
+		// Valores arbitrarios en todos los campos, incluidos negativos
+		crudo := make([]byte, tam)
+		azar.Read(crudo)
+		if err := binary.Read(bytes.NewReader(crudo), binary.LittleEndian, original); err != nil {
+			t.Fatal(err)
+		}
+		var esperado bytes.Buffer
+		binary.Write(&esperado, binary.LittleEndian, original)
+
+		disco, _ := almacenamiento.NuevaMemoria().Crear("d.mia", int64(tam)+16)
+		if err := EscribirEstructura(disco, 16, original); err != nil {
+			t.Fatalf("%s: %v", c.nombre, err)
+		}
+		escrito := make([]byte, tam)
+		disco.ReadAt(escrito, 16)
+		if !bytes.Equal(escrito, esperado.Bytes()) {
+			t.Errorf("%s: los bytes escritos no coinciden con binary.Write", c.nombre)
+		}
+
+		leido := c.nuevo()
+		if err := LeerEstructura(disco, 16, leido); err != nil {
+			t.Fatalf("%s: %v", c.nombre, err)
+		}
+		if !reflect.DeepEqual(leido, original) {
+			t.Errorf("%s: la estructura leída no es la escrita", c.nombre)
+		}
+	}
+}
@@ -3,11 +3,10 @@ package utils
 import (
 	"Proyecto/Estructuras/size"
 	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
 	"bytes"
-	"encoding/binary"
 	"fmt"
 	"math/rand"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +15,15 @@ import (
 )
 
 var DirectorioDisco = "VDIC-MIA/Disks/"
+var DirectorioSnapshots = "VDIC-MIA/Snapshots/"
+
+// DirectorioReportes es la raíz donde se guardan los reportes generados.
+// Se puede cambiar con la variable de entorno MIA_REPORTES_DIR al iniciar el servidor.
+var DirectorioReportes = "VDIC-MIA/Rep"
+
+// DirectorioScripts es la carpeta contra la que exec resuelve un -path relativo escrito
+// en la consola o en la petición. Se puede cambiar con MIA_SCRIPTS_DIR.
+var DirectorioScripts = "VDIC-MIA/Scripts"
 
 func esEntero(valor string) (int32, bool, string) {
 	i, err := strconv.Atoi(valor)
@@ -192,7 +200,7 @@ func TieneName(name string) (string, bool, string) {
 }
 
 func ExisteArchivo(comando string, pathArchivo string) bool {
-	if _, err := os.Stat(pathArchivo); os.IsNotExist(err) {
+	if !almacenamiento.Existe(pathArchivo) {
 		color.Red("[" + comando + "]: Archivo no encontrado")
 		return false
 	}
@@ -203,19 +211,14 @@ func ExisteArchivo(comando string, pathArchivo string) bool {
 func ObtenerEstructuraMBR(pathDisco string) (structures.MBR, bool, string) {
 	mbr := structures.MBR{}
 
-	file, err := os.OpenFile(pathDisco, os.O_RDWR, 0666)
+	file, err := almacenamiento.Abrir(pathDisco, true)
 	if err != nil {
 		color.Red("[utils.ln:205] Error en la lectura del disco")
 		return structures.MBR{}, true, "[utils.ln:205] Error en la lectura del disco"
 	}
 	defer file.Close()
 
-	if _, err := file.Seek(0, 0); err != nil {
-		color.Red("[utils.ln:210]: Error al mover el puntero")
-		return structures.MBR{}, true, "[utils.ln:210]: Error al mover el puntero"
-	}
-
-	if err := binary.Read(file, binary.LittleEndian, &mbr); err != nil {
+	if err := LeerEstructura(file, 0, &mbr); err != nil {
 		color.Red("[utils.ln:217]: Error en la lectura del MBR")
 		return structures.MBR{}, true, "[utils.ln:217]: Error en la lectura del MBR"
 	}
@@ -238,6 +241,18 @@ func ExisteNombreParticion(pathDisco string, nombreParticion string) (bool, stri
 		return true, strError
 	}
 
+	//vamos a leer el archivo y si hay error se retornara ello
+	file, err := almacenamiento.Abrir(pathDisco, false)
+	if err != nil {
+		return true, "[utils.line:257]: Error en abrir el archivo"
+	}
+	defer file.Close()
+
+	return ExisteNombreParticionEnDisco(file, &mbr, nombreParticion)
+}
+
+// ExisteNombreParticionEnDisco es ExisteNombreParticion sobre un disco ya abierto
+func ExisteNombreParticionEnDisco(file almacenamiento.Disco, mbr *structures.MBR, nombreParticion string) (bool, string) {
 	for i := range mbr.Mbr_partitions {
 		if ConvertirByteAString(mbr.Mbr_partitions[i].Part_name[:]) == nombreParticion {
 			// Aquí continuamos posterior a lo de la clase
@@ -252,21 +267,8 @@ func ExisteNombreParticion(pathDisco string, nombreParticion string) (bool, stri
 			// declaramoss una variable que tenga la estructura de EBR
 			ebr := structures.EBR{}
 
-			//vamos a leer el archivo y si hay error se retornara ello
-			file, err := os.OpenFile(pathDisco, os.O_RDWR, 0666)
-			if err != nil {
-				return true, "[utils.line:257]: Error en abrir el archivo"
-			}
-			defer file.Close()
-
-			// vamos a mover el puntero en la posición donde se encuentre
-			// la partición extendida
-			if _, err := file.Seek(int64(mbr.Mbr_partitions[i].Part_start), 0); err != nil {
-				return true, "[utils.line:264]: Error al mover el puntero"
-			}
-
-			// se va a leer el archivo y asignar al ebr su valor
-			if err := binary.Read(file, binary.LittleEndian, &ebr); err != nil {
+			// se va a leer el ebr que está al inicio de la partición extendida
+			if err := LeerEstructura(file, mbr.Mbr_partitions[i].Part_start, &ebr); err != nil {
 				return true, "[utils.line:269]: Error en la lectura del EBR"
 			}
 
@@ -283,13 +285,8 @@ func ExisteNombreParticion(pathDisco string, nombreParticion string) (bool, stri
 						return true, "[utils.line:282]: Nombre de la partición existente"
 					}
 
-					// movemos puntero para el siguiente ebr
-					if _, err := file.Seek(int64(ebr.Part_next), 0); err != nil {
-						return true, "[utils.line:287]: Error al mover el puntero"
-					}
-
 					// leemos y asignamos al ebr el nuevo valor del siguiente
-					if err := binary.Read(file, binary.LittleEndian, &ebr); err != nil {
+					if err := LeerEstructura(file, ebr.Part_next, &ebr); err != nil {
 						return true, "[utils.292]: Error en la lectura del EBR"
 					}
 
@@ -360,16 +357,8 @@ func ConvertirStringAByte(contenido string, cantidadBytes int) []byte {
 	return textoDevolver
 }
 
-func EscribirMBR(file *os.File, mbr *structures.MBR) error {
-	if _, err := file.Seek(0, 0); err != nil {
-		return err
-	}
-
-	if err := binary.Write(file, binary.LittleEndian, mbr); err != nil {
-		return err
-	}
-
-	return nil
+func EscribirMBR(file almacenamiento.Disco, mbr *structures.MBR) error {
+	return EscribirEstructura(file, 0, mbr)
 }
 
 func TieneID(comando string, valor string) string {
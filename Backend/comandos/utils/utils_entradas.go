@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
+	"fmt"
+	"strings"
+)
+
+// MaxContenidoArchivo es lo que cabe en un inodo: 12 bloques directos más los que
+// alcanzan los apuntadores simple, doble y triple con el tamaño de bloque de la partición
+func MaxContenidoArchivo(sb *structures.SuperBloque) int64 {
+	porBloque := int64(apuntadoresPorBloque(sb))
+	bloques := 12 + porBloque + porBloque*porBloque + porBloque*porBloque*porBloque
+	return bloques * int64(sb.S_block_s)
+}
+
+// apuntadoresPorBloque es cuántos apuntadores de 4 bytes caben en un bloque
+func apuntadoresPorBloque(sb *structures.SuperBloque) int32 {
+	return sb.S_block_s / 4
+}
+
+// EscribirSuperBloque guarda el SuperBloque al inicio de la partición
+func EscribirSuperBloque(file almacenamiento.Disco, inicioParticion int32, sb *structures.SuperBloque) error {
+	if err := EscribirEstructura(file, inicioParticion, sb); err != nil {
+		return fmt.Errorf("error al escribir SuperBloque: %v", err)
+	}
+	return nil
+}
+
+// EscribirInodo guarda un inodo en su posición en bytes
+func EscribirInodo(file almacenamiento.Disco, posicion int32, inodo *structures.TablaInodo) error {
+	if err := EscribirEstructura(file, posicion, inodo); err != nil {
+		return fmt.Errorf("error al escribir inodo: %v", err)
+	}
+	return nil
+}
+
+// SobrescribirArchivo reemplaza el contenido de un archivo existente,
+// reutilizando sus bloques y liberando los que sobren
+func SobrescribirArchivo(file almacenamiento.Disco, sb *structures.SuperBloque, inodo *structures.TablaInodo, posInodo int32, contenido string) error {
+	if err := escribirContenidoArchivo(file, sb, inodo, contenido); err != nil {
+		return err
+	}
+
+	inodo.I_s = int32(len(contenido))
+	inodo.I_mtime = ObFechaInt()
+	return EscribirInodo(file, posInodo, inodo)
+}
+
+// QuitarEntradaCarpeta desenlaza un nombre de la carpeta padre sin liberar su inodo
+func QuitarEntradaCarpeta(file almacenamiento.Disco, inodoPadre *structures.TablaInodo, posInodoPadre int32, nombre string) (int32, error) {
+	entradas, err := ListarCarpeta(file, inodoPadre)
+	if err != nil {
+		return -1, err
+	}
+
+	for _, entrada := range entradas {
+		if entrada.Nombre == nombre {
+			return entrada.PosInodo, borrarEntrada(file, inodoPadre, posInodoPadre, entrada)
+		}
+	}
+
+	return -1, errores.Nuevof(errores.NoEncontrado, "'%s' no existe", nombre)
+}
+
+// LiberarInodo libera un inodo, sus bloques y, si es carpeta, todo su contenido
+func LiberarInodo(file almacenamiento.Disco, sb *structures.SuperBloque, posInodo int32) error {
+	inodo, err := LeerInodoPorPosicion(file, posInodo)
+	if err != nil {
+		return err
+	}
+
+	if inodo.I_type[0] == '0' {
+		entradas, err := ListarCarpeta(file, &inodo)
+		if err != nil {
+			return err
+		}
+		for _, entrada := range entradas {
+			if entrada.PosInodo == posInodo {
+				continue
+			}
+			if err := LiberarInodo(file, sb, entrada.PosInodo); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Incluye los bloques de apuntadores de los niveles indirectos
+	bloques, err := BloquesDeInodo(file, &inodo)
+	if err != nil {
+		return err
+	}
+	for _, bloque := range bloques {
+		marcarBloqueLibre(file, sb, bloque.Pos)
+		sb.S_free_blocks_count++
+	}
+
+	marcarInodoLibre(file, sb, posInodo)
+	sb.S_free_inodes_count++
+	return nil
+}
+
+// EliminarEntrada quita un archivo o carpeta (recursivamente) de la carpeta padre
+func EliminarEntrada(file almacenamiento.Disco, sb *structures.SuperBloque, inodoPadre *structures.TablaInodo, posInodoPadre int32, nombre string) error {
+	posInodo, err := QuitarEntradaCarpeta(file, inodoPadre, posInodoPadre, nombre)
+	if err != nil {
+		return err
+	}
+	return LiberarInodo(file, sb, posInodo)
+}
+
+// MoverEntrada cambia de carpeta y/o de nombre una entrada sin copiar sus datos
+func MoverEntrada(file almacenamiento.Disco, sb *structures.SuperBloque, posPadreOrigen int32, nombreOrigen string, posPadreDestino int32, nombreDestino string) error {
+	if len(nombreDestino) > len(structures.Content{}.B_name) {
+		return errores.Nuevof(errores.ParametroInvalido, "el nombre '%s' supera los %d caracteres", nombreDestino, len(structures.Content{}.B_name))
+	}
+
+	padreOrigen, err := LeerInodoPorPosicion(file, posPadreOrigen)
+	if err != nil {
+		return err
+	}
+	posInodo, existe, err := BuscarEnCarpeta(file, sb, &padreOrigen, nombreOrigen)
+	if err != nil {
+		return err
+	}
+	if !existe {
+		return errores.Nuevof(errores.NoEncontrado, "'%s' no existe", nombreOrigen)
+	}
+
+	// Primero se enlaza en el destino: si no hay espacio el origen queda intacto
+	padreDestino, err := LeerInodoPorPosicion(file, posPadreDestino)
+	if err != nil {
+		return err
+	}
+	if err := AgregarEntradaCarpeta(file, sb, &padreDestino, posPadreDestino, nombreDestino, posInodo); err != nil {
+		return err
+	}
+
+	// Releer el origen por si es la misma carpeta que el destino
+	padreOrigen, err = LeerInodoPorPosicion(file, posPadreOrigen)
+	if err != nil {
+		return err
+	}
+	if err := quitarEntradaPorInodo(file, &padreOrigen, posPadreOrigen, nombreOrigen, posInodo); err != nil {
+		return err
+	}
+
+	// Una carpeta movida debe apuntar con ".." a su nuevo padre
+	inodo, err := LeerInodoPorPosicion(file, posInodo)
+	if err != nil {
+		return err
+	}
+	if inodo.I_type[0] == '0' && posPadreOrigen != posPadreDestino && inodo.I_block[0] != -1 {
+		var bloqueCarpeta structures.BloqueCarpeta
+		if err := LeerEstructura(file, inodo.I_block[0], &bloqueCarpeta); err != nil {
+			return err
+		}
+		for j := range bloqueCarpeta.B_content {
+			if strings.TrimRight(string(bloqueCarpeta.B_content[j].B_name[:]), "\x00") == ".." {
+				bloqueCarpeta.B_content[j].B_inodo = posPadreDestino
+			}
+		}
+		if err := EscribirEstructura(file, inodo.I_block[0], &bloqueCarpeta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// quitarEntradaPorInodo es QuitarEntradaCarpeta pero exige que el nombre apunte a posInodo;
+// evita borrar el enlace recién creado al renombrar dentro de la misma carpeta
+func quitarEntradaPorInodo(file almacenamiento.Disco, inodoPadre *structures.TablaInodo, posInodoPadre int32, nombre string, posInodo int32) error {
+	entradas, err := ListarCarpeta(file, inodoPadre)
+	if err != nil {
+		return err
+	}
+
+	for _, entrada := range entradas {
+		if entrada.Nombre == nombre && entrada.PosInodo == posInodo {
+			return borrarEntrada(file, inodoPadre, posInodoPadre, entrada)
+		}
+	}
+
+	return errores.Nuevof(errores.NoEncontrado, "'%s' no existe", nombre)
+}
+
+// borrarEntrada vacía la entrada dentro de su bloque y actualiza el mtime del padre
+func borrarEntrada(file almacenamiento.Disco, inodoPadre *structures.TablaInodo, posInodoPadre int32, entrada EntradaCarpeta) error {
+	var bloqueCarpeta structures.BloqueCarpeta
+	if err := LeerEstructura(file, entrada.PosBloque, &bloqueCarpeta); err != nil {
+		return err
+	}
+
+	bloqueCarpeta.B_content[entrada.IndiceEntry].B_name = [12]byte{}
+	bloqueCarpeta.B_content[entrada.IndiceEntry].B_inodo = -1
+
+	if err := EscribirEstructura(file, entrada.PosBloque, &bloqueCarpeta); err != nil {
+		return err
+	}
+
+	inodoPadre.I_mtime = ObFechaInt()
+	return EscribirInodo(file, posInodoPadre, inodoPadre)
+}
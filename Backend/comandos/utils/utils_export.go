@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"strconv"
+	"strings"
+)
+
+// EntradaCarpeta es una entrada de un BloqueCarpeta (sin "." ni "..")
+type EntradaCarpeta struct {
+	Nombre      string
+	PosInodo    int32
+	PosBloque   int32
+	IndiceEntry int
+}
+
+// ListarCarpeta devuelve las entradas de los bloques directos de una carpeta
+func ListarCarpeta(file almacenamiento.Disco, inodoCarpeta *structures.TablaInodo) ([]EntradaCarpeta, error) {
+	var entradas []EntradaCarpeta
+
+	for i := 0; i < 12; i++ {
+		if inodoCarpeta.I_block[i] == -1 {
+			continue
+		}
+
+		var bloqueCarpeta structures.BloqueCarpeta
+		if err := LeerEstructura(file, inodoCarpeta.I_block[i], &bloqueCarpeta); err != nil {
+			return entradas, err
+		}
+
+		for j, entrada := range bloqueCarpeta.B_content {
+			if entrada.B_inodo == -1 {
+				continue
+			}
+			nombre := ConvertirByteAString(entrada.B_name[:])
+			if nombre == "" || nombre == "." || nombre == ".." {
+				continue
+			}
+			entradas = append(entradas, EntradaCarpeta{
+				Nombre:      nombre,
+				PosInodo:    entrada.B_inodo,
+				PosBloque:   inodoCarpeta.I_block[i],
+				IndiceEntry: j,
+			})
+		}
+	}
+
+	return entradas, nil
+}
+
+// LeerUsuariosGrupos lee users.txt (inodo 1) y devuelve los nombres por UID y por GID
+func LeerUsuariosGrupos(file almacenamiento.Disco, sb *structures.SuperBloque) (map[int32]string, map[int32]string) {
+	usuarios := make(map[int32]string)
+	grupos := make(map[int32]string)
+
+	inodoUsers, err := LeerInodo(file, sb, 1)
+	if err != nil {
+		return usuarios, grupos
+	}
+	contenido, err := LeerContenidoArchivo(file, sb, &inodoUsers)
+	if err != nil {
+		return usuarios, grupos
+	}
+
+	for _, linea := range strings.Split(contenido, "\n") {
+		partes := strings.Split(strings.TrimSpace(linea), ",")
+		if len(partes) < 3 {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(partes[0]))
+		if err != nil || id == 0 {
+			continue // id 0 = registro eliminado
+		}
+
+		switch strings.TrimSpace(partes[1]) {
+		case "G":
+			grupos[int32(id)] = strings.TrimSpace(partes[2])
+		case "U":
+			if len(partes) >= 4 {
+				usuarios[int32(id)] = strings.TrimSpace(partes[3])
+			}
+		}
+	}
+
+	return usuarios, grupos
+}
+
+// PermisoOctal convierte I_perm ('6','6','4') al modo numérico 0664
+func PermisoOctal(inodo *structures.TablaInodo) int64 {
+	var modo int64
+	for _, p := range inodo.I_perm {
+		digito := int64(0)
+		if p >= '0' && p <= '7' {
+			digito = int64(p - '0')
+		}
+		modo = modo*8 + digito
+	}
+	return modo
+}
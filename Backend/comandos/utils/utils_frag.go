@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"fmt"
+	"path"
+)
+
+// BloqueInodo es un bloque usado por un inodo; Apuntador indica si guarda apuntadores
+type BloqueInodo struct {
+	Pos       int32
+	Apuntador bool
+}
+
+// InodoEnUso es un inodo marcado en el bitmap junto con su ruta y sus bloques
+type InodoEnUso struct {
+	Numero  int32
+	Pos     int32
+	Ruta    string // vacía si ninguna carpeta lo enlaza
+	Inodo   structures.TablaInodo
+	Bloques []BloqueInodo
+}
+
+// ExtensionBloques es una corrida de bloques contiguos, por índice de bloque
+type ExtensionBloques struct {
+	Inicio   int32 `json:"inicio"`
+	Cantidad int32 `json:"cantidad"`
+}
+
+// BloquesDeInodo devuelve los bloques del inodo en orden lógico: primero los directos y
+// luego, por cada nivel indirecto (I_block[12..14]), el bloque de apuntadores seguido de
+// los bloques que referencia
+func BloquesDeInodo(file almacenamiento.Disco, inodo *structures.TablaInodo) ([]BloqueInodo, error) {
+	var bloques []BloqueInodo
+
+	var recorrer func(pos int32, nivel int) error
+	recorrer = func(pos int32, nivel int) error {
+		if pos == -1 {
+			return nil
+		}
+		if nivel == 0 {
+			bloques = append(bloques, BloqueInodo{Pos: pos})
+			return nil
+		}
+		bloques = append(bloques, BloqueInodo{Pos: pos, Apuntador: true})
+
+		var apuntador structures.BloqueApuntador
+		if err := LeerEstructura(file, pos, &apuntador); err != nil {
+			return fmt.Errorf("error al leer bloque de apuntadores en %d: %v", pos, err)
+		}
+		for _, p := range apuntador.B_pointers {
+			if err := recorrer(p, nivel-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, pos := range inodo.I_block {
+		nivel := 0
+		if i >= 12 {
+			nivel = i - 11
+		}
+		if err := recorrer(pos, nivel); err != nil {
+			return nil, err
+		}
+	}
+	return bloques, nil
+}
+
+// RutasInodos recorre el árbol desde la raíz y devuelve la ruta de cada inodo por posición
+func RutasInodos(file almacenamiento.Disco, sb *structures.SuperBloque) map[int32]string {
+	rutas := make(map[int32]string)
+
+	var recorrer func(pos int32, ruta string)
+	recorrer = func(pos int32, ruta string) {
+		if _, ok := rutas[pos]; ok {
+			return
+		}
+		rutas[pos] = ruta
+
+		inodo, err := LeerInodoPorPosicion(file, pos)
+		if err != nil || inodo.I_type[0] != '0' {
+			return
+		}
+		entradas, err := ListarCarpeta(file, &inodo)
+		if err != nil {
+			return
+		}
+		for _, e := range entradas {
+			recorrer(e.PosInodo, path.Join(ruta, e.Nombre))
+		}
+	}
+
+	recorrer(sb.S_inode_start, "/")
+	return rutas
+}
+
+// InodosEnUso devuelve, en orden de índice, los inodos marcados en el bitmap con sus bloques
+func InodosEnUso(file almacenamiento.Disco, sb *structures.SuperBloque) ([]InodoEnUso, error) {
+	bitmap, err := LeerBitmapInodos(file, sb)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer bitmap de inodos: %v", err)
+	}
+
+	rutas := RutasInodos(file, sb)
+	var inodos []InodoEnUso
+	for i := int32(0); i < sb.S_inodes_count; i++ {
+		if bitmap[i] != '1' {
+			continue
+		}
+		pos := sb.S_inode_start + i*sb.S_inode_s
+		inodo, err := LeerInodoPorPosicion(file, pos)
+		if err != nil {
+			return nil, fmt.Errorf("error al leer inodo %d: %v", i, err)
+		}
+		bloques, err := BloquesDeInodo(file, &inodo)
+		if err != nil {
+			return nil, fmt.Errorf("inodo %d: %v", i, err)
+		}
+		inodos = append(inodos, InodoEnUso{
+			Numero:  i,
+			Pos:     pos,
+			Ruta:    rutas[pos],
+			Inodo:   inodo,
+			Bloques: bloques,
+		})
+	}
+	return inodos, nil
+}
+
+// ExtensionesDeBloques agrupa los índices (en orden lógico) en corridas contiguas
+func ExtensionesDeBloques(indices []int32) []ExtensionBloques {
+	var extensiones []ExtensionBloques
+	for _, indice := range indices {
+		n := len(extensiones)
+		if n > 0 && extensiones[n-1].Inicio+extensiones[n-1].Cantidad == indice {
+			extensiones[n-1].Cantidad++
+			continue
+		}
+		extensiones = append(extensiones, ExtensionBloques{Inicio: indice, Cantidad: 1})
+	}
+	return extensiones
+}
+
+// IndicesBloques convierte las posiciones de los bloques en índices de la tabla de bloques
+func IndicesBloques(sb *structures.SuperBloque, bloques []BloqueInodo) []int32 {
+	indices := make([]int32, len(bloques))
+	for i, b := range bloques {
+		indices[i] = (b.Pos - sb.S_block_start) / sb.S_block_s
+	}
+	return indices
+}
+
+// PuntajeFragmentacion es el porcentaje de saltos entre bloques consecutivos de un mismo
+// inodo que no caen en el bloque siguiente: 0 si todo es contiguo, 100 si nada lo es
+func PuntajeFragmentacion(sb *structures.SuperBloque, inodos []InodoEnUso) float64 {
+	saltos, transiciones := 0, 0
+	for _, in := range inodos {
+		if len(in.Bloques) < 2 {
+			continue
+		}
+		saltos += len(ExtensionesDeBloques(IndicesBloques(sb, in.Bloques))) - 1
+		transiciones += len(in.Bloques) - 1
+	}
+	if transiciones == 0 {
+		return 0
+	}
+	return float64(saltos) * 100 / float64(transiciones)
+}
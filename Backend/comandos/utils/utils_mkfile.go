@@ -1,12 +1,11 @@
 package utils
 
 import (
-	"Proyecto/Estructuras/size"
 	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
 	"Proyecto/comandos/global"
-	"encoding/binary"
 	"fmt"
-	"os"
 	"strings"
 )
 
@@ -26,28 +25,25 @@ func TienePermisoEscritura(inodo *structures.TablaInodo, sesion *global.SesionUs
 
 	// Verificar según la categoría del usuario
 	if inodo.I_uid == sesion.UID {
-		// Es el propietario - verificar permiso de escritura (w = 2 o mayor)
-		return permisoUser >= '2'
+		// Es el propietario - verificar bit de escritura (w = 2)
+		return tieneBitPermiso(permisoUser, 2)
 	} else if inodo.I_gid == sesion.GID {
 		// Es del mismo grupo
-		return permisoGroup >= '2'
+		return tieneBitPermiso(permisoGroup, 2)
 	} else {
 		// Es otro usuario
-		return permisoOther >= '2'
+		return tieneBitPermiso(permisoOther, 2)
 	}
 }
 
-func LeerInodoDesdeRuta(file *os.File, sb *structures.SuperBloque, ruta string) (structures.TablaInodo, int32, error) {
+func LeerInodoDesdeRuta(file almacenamiento.Disco, sb *structures.SuperBloque, ruta string) (structures.TablaInodo, int32, error) {
 	// Asumiendo que la ruta es absoluta (empieza con /)
 	partesRuta := strings.Split(strings.Trim(ruta, "/"), "/")
 	if len(partesRuta) == 0 || (len(partesRuta) == 1 && partesRuta[0] == "") {
 		// Caso especial: ruta es "/"
 		// Devolver el inodo raíz, que generalmente está en S_inode_start
 		var inodoRaiz structures.TablaInodo
-		if _, err := file.Seek(int64(sb.S_inode_start), 0); err != nil {
-			return inodoRaiz, -1, fmt.Errorf("error al posicionar puntero para leer inodo raíz: %v", err)
-		}
-		if err := binary.Read(file, binary.LittleEndian, &inodoRaiz); err != nil {
+		if err := LeerEstructura(file, sb.S_inode_start, &inodoRaiz); err != nil {
 			return inodoRaiz, -1, fmt.Errorf("error al leer inodo raíz: %v", err)
 		}
 		return inodoRaiz, sb.S_inode_start, nil // <-- Devuelve inodo, posicion, error
@@ -59,16 +55,13 @@ func LeerInodoDesdeRuta(file *os.File, sb *structures.SuperBloque, ruta string)
 
 	for i, nombreParte := range partesRuta {
 		// Leer el inodo actual
-		if _, err := file.Seek(int64(posInodoActual), 0); err != nil {
-			return inodoActual, -1, fmt.Errorf("error al posicionar puntero para leer inodo en nivel %d: %v", i, err)
-		}
-		if err := binary.Read(file, binary.LittleEndian, &inodoActual); err != nil {
+		if err := LeerEstructura(file, posInodoActual, &inodoActual); err != nil {
 			return inodoActual, -1, fmt.Errorf("error al leer inodo en nivel %d: %v", i, err)
 		}
 
 		// Verificar que sea un directorio
 		if inodoActual.I_type[0] != '0' { // '0' = Carpeta
-			return inodoActual, posInodoActual, fmt.Errorf("la ruta '%s' no es un directorio en '%s'", strings.Join(partesRuta[:i+1], "/"), nombreParte)
+			return inodoActual, posInodoActual, errores.Nuevof(errores.ParametroInvalido, "la ruta '%s' no es un directorio en '%s'", strings.Join(partesRuta[:i+1], "/"), nombreParte)
 		}
 
 		// Buscar la parte de la ruta en este inodo (directorio)
@@ -77,66 +70,39 @@ func LeerInodoDesdeRuta(file *os.File, sb *structures.SuperBloque, ruta string)
 			return inodoActual, posInodoActual, fmt.Errorf("error buscando '%s' en directorio '%s': %v", nombreParte, strings.Join(partesRuta[:i], "/"), err)
 		}
 		if !encontrado {
-			return inodoActual, posInodoActual, fmt.Errorf("ruta '%s' no encontrada, '%s' no existe", strings.Join(partesRuta, "/"), nombreParte)
+			return inodoActual, posInodoActual, errores.Nuevof(errores.NoEncontrado, "ruta '%s' no encontrada, '%s' no existe", strings.Join(partesRuta, "/"), nombreParte)
 		}
 
 		// Actualizar para la siguiente iteración
 		posInodoActual = posSiguienteInodo
 	}
 
-	if _, err := file.Seek(int64(posInodoActual), 0); err != nil {
-		return inodoActual, -1, fmt.Errorf("error al posicionar puntero para leer inodo final: %v", err)
-	}
-	if err := binary.Read(file, binary.LittleEndian, &inodoActual); err != nil {
+	if err := LeerEstructura(file, posInodoActual, &inodoActual); err != nil {
 		return inodoActual, -1, fmt.Errorf("error al leer inodo final: %v", err)
 	}
 
 	return inodoActual, posInodoActual, nil
 }
 
-// BuscarInodoLIbre busca un inodo libre en el bitmap de inodos
-func BuscarInodoLIbre(file *os.File, sb *structures.SuperBloque) int32 {
-	for i := int32(0); i < sb.S_inodes_count; i++ {
-		var bit byte
-		if _, err := file.Seek(int64(sb.S_bm_inode_start+i), 0); err != nil {
-			continue
-		}
-		if err := binary.Read(file, binary.LittleEndian, &bit); err != nil {
-			continue
-		}
-		if bit == '0' {
-			return sb.S_inode_start + (i * size.SizeTablaInodo())
-		}
-	}
-	return -1
-}
-
-// MarcarInodoUsado marca un inodo como usado en el bitmap
-func MarcarInodoUsado(file *os.File, sb *structures.SuperBloque, posicionInodo int32) {
-	indice := (posicionInodo - sb.S_inode_start) / size.SizeTablaInodo()
-	var bit byte = '1'
-	if _, err := file.Seek(int64(sb.S_bm_inode_start+indice), 0); err != nil {
-		return
-	}
-	binary.Write(file, binary.LittleEndian, &bit)
+// CrearArchivo crea un archivo en el directorio padre con el contenido especificado, a
+// nombre del usuario de la sesión. posInodoPadre es la posición en bytes del inodo padre,
+// donde se reescribe al agregar la entrada.
+func CrearArchivo(file almacenamiento.Disco, sb *structures.SuperBloque, inodoPadre *structures.TablaInodo, posInodoPadre int32, nombreArchivo string, contenido string, sesion *global.SesionUsuario) error {
+	return CrearArchivoComo(file, sb, inodoPadre, posInodoPadre, nombreArchivo, contenido, sesion.UID, sesion.GID)
 }
 
-// CrearArchivo crea un archivo en el directorio padre con el contenido especificado.
-func CrearArchivo(file *os.File, sb *structures.SuperBloque, inodoPadre *structures.TablaInodo, nombreArchivo string, contenido string) error {
+// CrearArchivoComo crea el archivo con el dueño indicado en lugar del de una sesión
+func CrearArchivoComo(file almacenamiento.Disco, sb *structures.SuperBloque, inodoPadre *structures.TablaInodo, posInodoPadre int32, nombreArchivo string, contenido string, uid int32, gid int32) error {
 	// 1. Buscar un inodo libre para el nuevo archivo
 	nuevaPosicionInodo := BuscarInodoLIbre(file, sb)
 	if nuevaPosicionInodo == -1 {
-		return fmt.Errorf("no hay inodos libres")
+		return errores.Nuevo(errores.SinEspacio, "no hay inodos libres")
 	}
 
-	// 2. Marcar el inodo como usado
-	MarcarInodoUsado(file, sb, nuevaPosicionInodo)
-	sb.S_free_inodes_count-- // Actualizar contador en SuperBloque
-
-	// 3. Crear la estructura del nuevo inodo
+	// 2. Crear la estructura del nuevo inodo
 	var nuevoInodo structures.TablaInodo
-	nuevoInodo.I_uid = global.SesionActiva.UID
-	nuevoInodo.I_gid = global.SesionActiva.GID
+	nuevoInodo.I_uid = uid
+	nuevoInodo.I_gid = gid
 	nuevoInodo.I_s = int32(len(contenido))
 	nuevoInodo.I_atime = ObFechaInt()
 	nuevoInodo.I_ctime = ObFechaInt()
@@ -149,162 +115,158 @@ func CrearArchivo(file *os.File, sb *structures.SuperBloque, inodoPadre *structu
 	nuevoInodo.I_perm[1] = '4'
 	nuevoInodo.I_perm[2] = '4'
 
-	// 4. Asignar bloques de datos y escribir contenido
-	bloquesNecesarios := (len(contenido) + 63) / 64 // Tamaño de bloque es 64
-	if len(contenido) > 0 && bloquesNecesarios == 0 {
-		bloquesNecesarios = 1 // Si hay contenido pero < 64 bytes, se necesita al menos 1 bloque
+	// 3. Asignar bloques de datos y escribir contenido; si no caben no se marcó nada
+	if err := escribirContenidoArchivo(file, sb, &nuevoInodo, contenido); err != nil {
+		return err
 	}
 
-	if bloquesNecesarios > 12 {
-		return fmt.Errorf("contenido demasiado grande (más de 12 bloques directos)")
+	// 4. Marcar el inodo como usado y escribirlo en disco
+	MarcarInodoUsado(file, sb, nuevaPosicionInodo)
+	sb.S_free_inodes_count-- // Actualizar contador en SuperBloque
+	if err := EscribirEstructura(file, nuevaPosicionInodo, &nuevoInodo); err != nil {
+		return fmt.Errorf("error al escribir inodo: %v", err)
 	}
 
-	offset := 0
-	for i := 0; i < bloquesNecesarios; i++ {
-		var bloqueArchivo structures.BloqueArchivo
-		fin := offset + 64
-		if fin > len(contenido) {
-			fin = len(contenido)
+	// 5. Agregar entrada del archivo al directorio padre; si no hay lugar se devuelven el
+	// inodo y sus bloques
+	if err := AgregarEntradaCarpeta(file, sb, inodoPadre, posInodoPadre, nombreArchivo, nuevaPosicionInodo); err != nil {
+		if errLiberar := LiberarInodo(file, sb, nuevaPosicionInodo); errLiberar != nil {
+			return fmt.Errorf("%w (y no se pudo liberar el inodo: %v)", err, errLiberar)
 		}
-		copy(bloqueArchivo.B_content[:], contenido[offset:fin])
+		return err
+	}
+	return nil
+}
 
-		// Buscar un bloque libre
-		nuevoBloquePos := BuscarBloqueLIbre(file, sb)
-		if nuevoBloquePos == -1 {
-			// Si no hay bloques suficientes, liberar los ya asignados y el inodo
-			for j := 0; j < i; j++ {
-				marcarBloqueLibre(file, sb, nuevoInodo.I_block[j])
-				sb.S_free_blocks_count++
-			}
-			var bit byte = '0'
-			indiceInodo := (nuevaPosicionInodo - sb.S_inode_start) / size.SizeTablaInodo()
-			if _, err := file.Seek(int64(sb.S_bm_inode_start+indiceInodo), 0); err == nil {
-				binary.Write(file, binary.LittleEndian, &bit)
-			}
-			sb.S_free_inodes_count++ // Restaurar contador
-			return fmt.Errorf("no hay bloques libres suficientes")
-		}
+// escribirContenidoArchivo reparte el contenido en bloques de archivo: primero los 12
+// directos y después los apuntadores simple, doble y triple. Reutiliza los bloques que
+// el inodo ya tenía, pide de una vez los que falten (contiguos si hay espacio) y libera
+// los que sobren. El espacio se verifica antes de escribir: si no alcanza, el inodo y
+// los bitmaps quedan como estaban. No escribe el inodo; I_s lo actualiza quien llama.
+func escribirContenidoArchivo(file almacenamiento.Disco, sb *structures.SuperBloque, inodo *structures.TablaInodo, contenido string) error {
+	if maximo := MaxContenidoArchivo(sb); int64(len(contenido)) > maximo {
+		return errores.Nuevof(errores.SinEspacio, "contenido demasiado grande (un archivo admite %d bytes)", maximo)
+	}
 
-		// Marcar bloque como usado
-		MarcarBloqueUsado(file, sb, nuevoBloquePos)
-		sb.S_free_blocks_count-- // Actualizar contador en SuperBloque
+	tamBloque := int(sb.S_block_s)
+	datos, apuntadores := bloquesParaContenido(sb, len(contenido))
 
-		// Asignar bloque al inodo
-		nuevoInodo.I_block[i] = nuevoBloquePos
+	anteriores, err := BloquesDeInodo(file, inodo)
+	if err != nil {
+		return err
+	}
+	disponibles := make([]int32, 0, len(anteriores))
+	for _, b := range anteriores {
+		disponibles = append(disponibles, b.Pos)
+	}
 
-		// Escribir bloque en disco
-		if _, err := file.Seek(int64(nuevoBloquePos), 0); err != nil {
-			return fmt.Errorf("error al posicionar puntero para escribir bloque %d: %v", i, err)
+	// Los bloques que faltan se piden juntos para que queden contiguos si hay espacio
+	if faltan := datos + apuntadores - len(disponibles); faltan > 0 {
+		nuevos := BuscarBloquesLibres(file, sb, faltan)
+		if len(nuevos) < faltan {
+			return errores.Nuevo(errores.SinEspacio, "no hay bloques libres suficientes")
 		}
-		if err := binary.Write(file, binary.LittleEndian, &bloqueArchivo); err != nil {
-			return fmt.Errorf("error al escribir bloque %d: %v", i, err)
+		for _, pos := range nuevos {
+			MarcarBloqueUsado(file, sb, pos)
+			sb.S_free_blocks_count--
 		}
-		offset += 64
+		disponibles = append(disponibles, nuevos...)
 	}
 
-	// 5. Escribir el nuevo inodo en disco
-	if _, err := file.Seek(int64(nuevaPosicionInodo), 0); err != nil {
-		return fmt.Errorf("error al posicionar puntero para escribir inodo: %v", err)
+	siguiente := func() int32 {
+		pos := disponibles[0]
+		disponibles = disponibles[1:]
+		return pos
 	}
-	if err := binary.Write(file, binary.LittleEndian, &nuevoInodo); err != nil {
-		return fmt.Errorf("error al escribir inodo: %v", err)
+	offset := 0
+	escribirDatos := func() (int32, error) {
+		var bloqueArchivo structures.BloqueArchivo
+		copy(bloqueArchivo.B_content[:], contenido[offset:min(offset+tamBloque, len(contenido))])
+		offset += tamBloque
+		pos := siguiente()
+		return pos, EscribirEstructura(file, pos, &bloqueArchivo)
 	}
 
-	// 6. Agregar entrada del archivo al directorio padre
-	// Buscar un bloque de carpeta disponible en el inodo padre
-
-	var bloqueCarpeta structures.BloqueCarpeta
-	for i := 0; i < 12; i++ {
-		if inodoPadre.I_block[i] != -1 {
-			// Leer bloque existente
-			if _, err := file.Seek(int64(inodoPadre.I_block[i]), 0); err != nil {
-				continue
-			}
-			if err := binary.Read(file, binary.LittleEndian, &bloqueCarpeta); err != nil {
-				continue
-			}
-			// Buscar entrada vacía
-			for j := 0; j < 4; j++ {
-				if bloqueCarpeta.B_content[j].B_inodo == -1 {
-					// Encontramos una entrada vacía
-					copy(bloqueCarpeta.B_content[j].B_name[:], nombreArchivo)
-					bloqueCarpeta.B_content[j].B_inodo = nuevaPosicionInodo
-					// Escribir bloque actualizado
-					if _, err := file.Seek(int64(inodoPadre.I_block[i]), 0); err != nil {
-						return fmt.Errorf("error al posicionar puntero para escribir bloque carpeta: %v", err)
-					}
-					if err := binary.Write(file, binary.LittleEndian, &bloqueCarpeta); err != nil {
-						return fmt.Errorf("error al escribir bloque carpeta: %v", err)
-					}
-					// Actualizar mtime del directorio padre
-					inodoPadre.I_mtime = ObFechaInt()
-					// Escribir inodo padre actualizado
-					if _, err := file.Seek(int64(sb.S_inode_start), 0); err != nil { // Asumiendo que inodoPadre es el raíz, si no, necesitas la posición real
-					}
-					// Supongamos que inodoPadre es el raíz (índice 0)
-					posInodoPadre := sb.S_inode_start // Esto es para raíz
-					if _, err := file.Seek(int64(posInodoPadre), 0); err != nil {
-						return fmt.Errorf("error al posicionar puntero para escribir inodo padre: %v", err)
-					}
-					if err := binary.Write(file, binary.LittleEndian, inodoPadre); err != nil {
-						return fmt.Errorf("error al escribir inodo padre: %v", err)
-					}
-					return nil // Archivo creado exitosamente
-				}
-			}
-		} else if inodoPadre.I_block[i] == -1 {
-			// Bloque vacío, crear uno nuevo
-			nuevoBloqueCarpetaPos := BuscarBloqueLIbre(file, sb)
-			if nuevoBloqueCarpetaPos == -1 {
-				// Si no hay bloques, liberar inodo y bloques ya asignados
-				for j := 0; j < bloquesNecesarios; j++ {
-					marcarBloqueLibre(file, sb, nuevoInodo.I_block[j])
-					sb.S_free_blocks_count++
-				}
-				var bit byte = '0'
-				indiceInodo := (nuevaPosicionInodo - sb.S_inode_start) / size.SizeTablaInodo()
-				if _, err := file.Seek(int64(sb.S_bm_inode_start+indiceInodo), 0); err == nil {
-					binary.Write(file, binary.LittleEndian, &bit)
-				}
-				sb.S_free_inodes_count++ // Restaurar contador
-				return fmt.Errorf("no hay bloques libres para crear bloque de carpeta en el directorio padre")
-			}
-			MarcarBloqueUsado(file, sb, nuevoBloqueCarpetaPos)
-			sb.S_free_blocks_count-- // Actualizar contador en SuperBloque
-
-			// Crear bloque de carpeta con la nueva entrada
-			var nuevoBloqueC structures.BloqueCarpeta
-			copy(nuevoBloqueC.B_content[0].B_name[:], nombreArchivo)
-			nuevoBloqueC.B_content[0].B_inodo = nuevaPosicionInodo
-			for k := 1; k < 4; k++ {
-				nuevoBloqueC.B_content[k].B_inodo = -1 // Resto vacío
+	// El bloque de apuntadores se toma antes que sus hijos para que quede delante de ellos
+	var escribirNivel func(nivel int) (int32, error)
+	escribirNivel = func(nivel int) (int32, error) {
+		pos := siguiente()
+		var apuntador structures.BloqueApuntador
+		for k := range apuntador.B_pointers {
+			apuntador.B_pointers[k] = -1
+		}
+		for k := range apuntador.B_pointers {
+			if offset >= len(contenido) {
+				break
 			}
-
-			// Escribir bloque de carpeta nuevo
-			if _, err := file.Seek(int64(nuevoBloqueCarpetaPos), 0); err != nil {
-				return fmt.Errorf("error al posicionar puntero para escribir nuevo bloque carpeta: %v", err)
+			var err error
+			if nivel == 1 {
+				apuntador.B_pointers[k], err = escribirDatos()
+			} else {
+				apuntador.B_pointers[k], err = escribirNivel(nivel - 1)
 			}
-			if err := binary.Write(file, binary.LittleEndian, &nuevoBloqueC); err != nil {
-				return fmt.Errorf("error al escribir nuevo bloque carpeta: %v", err)
+			if err != nil {
+				return -1, err
 			}
+		}
+		return pos, EscribirEstructura(file, pos, &apuntador)
+	}
 
-			// Asignar bloque al inodo padre
-			inodoPadre.I_block[i] = nuevoBloqueCarpetaPos
+	for i := range inodo.I_block {
+		inodo.I_block[i] = -1
+	}
+	for i := 0; i < len(inodo.I_block) && offset < len(contenido); i++ {
+		var err error
+		if i < 12 {
+			inodo.I_block[i], err = escribirDatos()
+		} else {
+			inodo.I_block[i], err = escribirNivel(i - 11)
+		}
+		if err != nil {
+			return fmt.Errorf("error al escribir bloque %d: %v", i, err)
+		}
+	}
 
-			// Actualizar mtime del directorio padre
-			inodoPadre.I_mtime = ObFechaInt()
+	// Los bloques que el archivo ya no usa vuelven al bitmap
+	for _, pos := range disponibles {
+		marcarBloqueLibre(file, sb, pos)
+		sb.S_free_blocks_count++
+	}
+	return nil
+}
 
-			// Escribir inodo padre actualizado
-			posInodoPadre := sb.S_inode_start // Suponiendo raíz
-			if _, err := file.Seek(int64(posInodoPadre), 0); err != nil {
-				return fmt.Errorf("error al posicionar puntero para escribir inodo padre: %v", err)
-			}
-			if err := binary.Write(file, binary.LittleEndian, inodoPadre); err != nil {
-				return fmt.Errorf("error al escribir inodo padre: %v", err)
-			}
-			return nil // Archivo creado exitosamente
+// bloquesParaContenido devuelve cuántos bloques de datos y de apuntadores ocupa un
+// contenido de largo bytes
+func bloquesParaContenido(sb *structures.SuperBloque, largo int) (int, int) {
+	tamBloque := int(sb.S_block_s)
+	datos := (largo + tamBloque - 1) / tamBloque
+
+	// apuntadoresNivel cuenta los bloques de apuntadores de un nivel que referencia n bloques de datos
+	porBloque := int(apuntadoresPorBloque(sb))
+	var apuntadoresNivel func(n int, nivel int) int
+	apuntadoresNivel = func(n int, nivel int) int {
+		if nivel == 1 {
+			return 1
 		}
+		capacidadHijo := 1
+		for i := 1; i < nivel; i++ {
+			capacidadHijo *= porBloque
+		}
+		total := 1
+		for ; n > 0; n -= capacidadHijo {
+			total += apuntadoresNivel(min(n, capacidadHijo), nivel-1)
+		}
+		return total
 	}
 
-	return fmt.Errorf("el directorio padre está lleno (solo se manejan bloques directos)")
+	apuntadores := 0
+	resto := datos - 12
+	capacidad := 1
+	for nivel := 1; nivel <= 3 && resto > 0; nivel++ {
+		capacidad *= porBloque
+		usados := min(resto, capacidad)
+		apuntadores += apuntadoresNivel(usados, nivel)
+		resto -= usados
+	}
+	return datos, apuntadores
 }
@@ -4,21 +4,17 @@ package utils
 import (
 	"Proyecto/Estructuras/size"
 	"Proyecto/Estructuras/structures"
+	"Proyecto/comandos/almacenamiento"
+	"Proyecto/comandos/errores"
 	"Proyecto/comandos/global"
-	"encoding/binary"
 	"fmt"
-	"os"
 	"strings"
 )
 
-func LeerSuperBloque(file *os.File, inicioParticion int32) (structures.SuperBloque, error) {
+func LeerSuperBloque(file almacenamiento.Disco, inicioParticion int32) (structures.SuperBloque, error) {
 	var sb structures.SuperBloque
 
-	if _, err := file.Seek(int64(inicioParticion), 0); err != nil {
-		return sb, err
-	}
-
-	if err := binary.Read(file, binary.LittleEndian, &sb); err != nil {
+	if err := LeerEstructura(file, inicioParticion, &sb); err != nil {
 		return sb, err
 	}
 
@@ -30,12 +26,27 @@ func LeerSuperBloque(file *os.File, inicioParticion int32) (structures.SuperBloq
 	return sb, nil
 }
 
-// LeerArchivoDesdeRuta navega por la estructura de directorios y lee el archivo.
-func LeerArchivoDesdeRuta(file *os.File, sb *structures.SuperBloque, rutaCompleta string) (string, error) {
+// LeerArchivoDesdeRuta navega por la estructura de directorios y lee el archivo con los
+// permisos de la sesión indicada.
+func LeerArchivoDesdeRuta(file almacenamiento.Disco, sb *structures.SuperBloque, rutaCompleta string, sesion *global.SesionUsuario) (string, error) {
+	inodo, _, err := LocalizarArchivo(file, sb, rutaCompleta, sesion)
+	if err != nil {
+		return "", err
+	}
+
+	// Leer el contenido del archivo
+	return LeerContenidoArchivo(file, sb, &inodo)
+}
+
+// LocalizarArchivo recorre la ruta hasta el inodo del archivo y verifica el permiso de lectura
+// de la sesión. Devuelve el inodo y su posición en bytes.
+func LocalizarArchivo(file almacenamiento.Disco, sb *structures.SuperBloque, rutaCompleta string, sesion *global.SesionUsuario) (structures.TablaInodo, int32, error) {
+	var vacio structures.TablaInodo
+
 	// Limpiar la ruta
 	ruta := strings.TrimSpace(rutaCompleta)
 	if ruta == "" {
-		return "", fmt.Errorf("ruta vacía")
+		return vacio, 0, errores.Nuevo(errores.ParametroInvalido, "ruta vacía")
 	}
 
 	// Asegurar que empiece con /
@@ -46,13 +57,13 @@ func LeerArchivoDesdeRuta(file *os.File, sb *structures.SuperBloque, rutaComplet
 	// Dividir la ruta en partes
 	partes := strings.Split(strings.Trim(ruta, "/"), "/")
 	if len(partes) == 0 || (len(partes) == 1 && partes[0] == "") {
-		return "", fmt.Errorf("ruta inválida")
+		return vacio, 0, errores.Nuevo(errores.ParametroInvalido, "ruta inválida")
 	}
 
 	// Empezar desde el inodo raíz (inodo 0)
 	inodoActual, err := LeerInodo(file, sb, 0)
 	if err != nil {
-		return "", fmt.Errorf("error al leer inodo raíz: %v", err)
+		return vacio, 0, fmt.Errorf("error al leer inodo raíz: %v", err)
 	}
 
 	// Navegar por cada parte de la ruta
@@ -65,47 +76,46 @@ func LeerArchivoDesdeRuta(file *os.File, sb *structures.SuperBloque, rutaComplet
 
 		// Verificar que el inodo actual sea una carpeta
 		if inodoActual.I_type[0] != '0' {
-			return "", fmt.Errorf("'%s' no es una carpeta", strings.Join(partes[:i], "/"))
+			return vacio, 0, errores.Nuevof(errores.ParametroInvalido, "'%s' no es una carpeta", strings.Join(partes[:i], "/"))
 		}
 
 		// Buscar la parte en la carpeta actual
 		siguienteInodo, encontrado, err := BuscarEnCarpeta(file, sb, &inodoActual, parte)
 		if err != nil {
-			return "", err
+			return vacio, 0, err
 		}
 
 		if !encontrado {
-			return "", fmt.Errorf("'%s' no encontrado", parte)
+			return vacio, 0, errores.Nuevof(errores.NoEncontrado, "'%s' no encontrado", parte)
 		}
 
 		// Leer el siguiente inodo
 		inodoActual, err = LeerInodoPorPosicion(file, siguienteInodo)
 		if err != nil {
-			return "", fmt.Errorf("error al leer inodo: %v", err)
+			return vacio, 0, fmt.Errorf("error al leer inodo: %v", err)
 		}
 
-		// Si es el último elemento, verificar permisos y leer contenido
+		// Si es el último elemento, verificar que sea archivo y los permisos
 		if esUltimo {
 			// Verificar que sea un archivo
 			if inodoActual.I_type[0] != '1' {
-				return "", fmt.Errorf("'%s' es una carpeta, no un archivo", parte)
+				return vacio, 0, errores.Nuevof(errores.ParametroInvalido, "'%s' es una carpeta, no un archivo", parte)
 			}
 
 			// Verificar permisos de lectura - Pasa el nombre del archivo
-			if !TienePermisoLectura(&inodoActual, global.SesionActiva, parte) { // Añade 'parte' como nombre del archivo
-				return "", fmt.Errorf("sin permisos de lectura para '%s'", parte)
+			if !TienePermisoLectura(&inodoActual, sesion, parte) { // Añade 'parte' como nombre del archivo
+				return vacio, 0, errores.Nuevof(errores.SinPermiso, "sin permisos de lectura para '%s'", parte)
 			}
 
-			// Leer el contenido del archivo
-			return LeerContenidoArchivo(file, sb, &inodoActual)
+			return inodoActual, siguienteInodo, nil
 		}
 	}
 
-	return "", fmt.Errorf("ruta inválida")
+	return vacio, 0, errores.Nuevo(errores.ParametroInvalido, "ruta inválida")
 }
 
 // BuscarEnCarpeta busca un nombre en una carpeta y retorna el inodo
-func BuscarEnCarpeta(file *os.File, sb *structures.SuperBloque, inodoCarpeta *structures.TablaInodo, nombre string) (int32, bool, error) {
+func BuscarEnCarpeta(file almacenamiento.Disco, sb *structures.SuperBloque, inodoCarpeta *structures.TablaInodo, nombre string) (int32, bool, error) {
 	for i := 0; i < 12; i++ { // Bloques directos
 		if inodoCarpeta.I_block[i] == -1 {
 			break
@@ -114,11 +124,7 @@ func BuscarEnCarpeta(file *os.File, sb *structures.SuperBloque, inodoCarpeta *st
 		var bloqueCarpeta structures.BloqueCarpeta
 		posicionBloque := inodoCarpeta.I_block[i]
 
-		if _, err := file.Seek(int64(posicionBloque), 0); err != nil {
-			return -1, false, err
-		}
-
-		if err := binary.Read(file, binary.LittleEndian, &bloqueCarpeta); err != nil {
+		if err := LeerEstructura(file, posicionBloque, &bloqueCarpeta); err != nil {
 			return -1, false, err
 		}
 
@@ -138,27 +144,20 @@ func BuscarEnCarpeta(file *os.File, sb *structures.SuperBloque, inodoCarpeta *st
 }
 
 // LeerInodo lee un inodo por su índice
-func LeerInodo(file *os.File, sb *structures.SuperBloque, indice int32) (structures.TablaInodo, error) {
+func LeerInodo(file almacenamiento.Disco, sb *structures.SuperBloque, indice int32) (structures.TablaInodo, error) {
 	var inodo structures.TablaInodo
 	posicion := sb.S_inode_start + (indice * sb.S_inode_s) // ¡Usar S_inode_s!
-	if _, err := file.Seek(int64(posicion), 0); err != nil {
-		return inodo, err
-	}
-	if err := binary.Read(file, binary.LittleEndian, &inodo); err != nil {
+	if err := LeerEstructura(file, posicion, &inodo); err != nil {
 		return inodo, err
 	}
 	return inodo, nil
 }
 
 // LeerInodoPorPosicion lee un inodo directamente por su posición en bytes
-func LeerInodoPorPosicion(file *os.File, posicion int32) (structures.TablaInodo, error) {
+func LeerInodoPorPosicion(file almacenamiento.Disco, posicion int32) (structures.TablaInodo, error) {
 	var inodo structures.TablaInodo
 
-	if _, err := file.Seek(int64(posicion), 0); err != nil {
-		return inodo, err
-	}
-
-	if err := binary.Read(file, binary.LittleEndian, &inodo); err != nil {
+	if err := LeerEstructura(file, posicion, &inodo); err != nil {
 		return inodo, err
 	}
 
@@ -190,33 +189,43 @@ func TienePermisoLectura(inodo *structures.TablaInodo, sesion *global.SesionUsua
 
 	// Verificar según la categoría del usuario
 	if inodo.I_uid == sesion.UID {
-		// Es el propietario - verificar permiso de lectura (r = 4 o mayor)
-		return permisoUser >= '4'
+		// Es el propietario - verificar bit de lectura (r = 4)
+		return tieneBitPermiso(permisoUser, 4)
 	} else if inodo.I_gid == sesion.GID {
 		// Es del mismo grupo
-		return permisoGroup >= '4'
+		return tieneBitPermiso(permisoGroup, 4)
 	} else {
 		// Es otro usuario
-		return permisoOther >= '4'
+		return tieneBitPermiso(permisoOther, 4)
+	}
+}
+
+// tieneBitPermiso revisa un bit (4=r, 2=w, 1=x) del dígito octal de I_perm.
+// Comparar dígitos con >= daba escritura a permisos como '4' o '5'.
+func tieneBitPermiso(digito byte, bit byte) bool {
+	if digito < '0' || digito > '7' {
+		return false
 	}
+	return (digito-'0')&bit != 0
 }
 
 // LeerContenidoArchivo lee el contenido completo de un archivo
-func LeerContenidoArchivo(file *os.File, sb *structures.SuperBloque, inodo *structures.TablaInodo) (string, error) {
+func LeerContenidoArchivo(file almacenamiento.Disco, sb *structures.SuperBloque, inodo *structures.TablaInodo) (string, error) {
 	var contenidoTotal strings.Builder
 
-	// Leer bloques directos
-	for i := 0; i < 12; i++ {
-		if inodo.I_block[i] == -1 {
-			break
-		}
+	bloques, err := BloquesDeInodo(file, inodo)
+	if err != nil {
+		return "", err
+	}
 
-		var bloqueArchivo structures.BloqueArchivo
-		if _, err := file.Seek(int64(inodo.I_block[i]), 0); err != nil {
-			return "", fmt.Errorf("error seek bloque %d: %v", i, err)
+	// Leer los bloques de datos en orden, saltando los de apuntadores
+	for i, bloque := range bloques {
+		if bloque.Apuntador {
+			continue
 		}
 
-		if err := binary.Read(file, binary.LittleEndian, &bloqueArchivo); err != nil {
+		var bloqueArchivo structures.BloqueArchivo
+		if err := LeerEstructura(file, bloque.Pos, &bloqueArchivo); err != nil {
 			return "", fmt.Errorf("error lectura bloque %d: %v", i, err)
 		}
 
@@ -230,142 +239,112 @@ func LeerContenidoArchivo(file *os.File, sb *structures.SuperBloque, inodo *stru
 	return resultado, nil
 }
 
-// BuscarBloqueLIbre busca un bloque libre en el bitmap de bloques
-func BuscarBloqueLIbre(file *os.File, sb *structures.SuperBloque) int32 {
-	for i := int32(0); i < sb.S_blocks_count; i++ {
-		var bit byte
-		if _, err := file.Seek(int64(sb.S_bm_block_start+i), 0); err != nil {
-			continue
-		}
-		if err := binary.Read(file, binary.LittleEndian, &bit); err != nil {
-			continue
-		}
-		if bit == '0' {
-			return sb.S_block_start + (i * size.SizeBloqueArchivo())
-		}
-	}
-	return -1
-}
-
-// MarcarBloqueUsado marca un bloque como usado en el bitmap
-func MarcarBloqueUsado(file *os.File, sb *structures.SuperBloque, posicionBloque int32) {
-	indice := (posicionBloque - sb.S_block_start) / size.SizeBloqueArchivo()
-	var bit byte = '1'
-	if _, err := file.Seek(int64(sb.S_bm_block_start+indice), 0); err != nil {
-		return
-	}
-	binary.Write(file, binary.LittleEndian, &bit)
-}
-
-// marcarBloqueLibre marca un bloque como libre en el bitmap
-func marcarBloqueLibre(file *os.File, sb *structures.SuperBloque, posicionBloque int32) {
-	indice := (posicionBloque - sb.S_block_start) / size.SizeBloqueArchivo()
-	var bit byte = '0'
-	if _, err := file.Seek(int64(sb.S_bm_block_start+indice), 0); err != nil {
-		return
-	}
-	binary.Write(file, binary.LittleEndian, &bit)
-}
-
 // EscribirArchivoUsersText actualiza el contenido de users.txt
-func EscribirArchivoUsersText(file *os.File, sb *structures.SuperBloque, nuevoContenido string) error {
+func EscribirArchivoUsersText(file almacenamiento.Disco, sb *structures.SuperBloque, nuevoContenido string) error {
 	inodoUsers, err := LeerInodo(file, sb, 1) // Inodo de users.txt es el 1
 	if err != nil {
 		return err
 	}
 
-	bloquesAntiguos := (inodoUsers.I_s + 63) / 64
-	if inodoUsers.I_s == 0 {
-		bloquesAntiguos = 0
+	posInodo := sb.S_inode_start + size.SizeTablaInodo()
+	if err := SobrescribirArchivo(file, sb, &inodoUsers, posInodo, nuevoContenido); err != nil {
+		return err
 	}
 
-	inodoUsers.I_s = int32(len(nuevoContenido))
-	inodoUsers.I_mtime = ObFechaInt() // Asegúrate de que ObFechaInt esté definida en este paquete o importada
+	// Escribir el SuperBloque actualizado
+	return EscribirEstructura(file, sb.S_bm_inode_start-size.SizeSuperBloque(), sb)
+}
 
-	bloquesNecesarios := (len(nuevoContenido) + 63) / 64
+// LimpiarParticion limpia una partición escribiendo ceros
+func LimpiarParticion(file almacenamiento.Disco, inicio int32, tamanio int32) error {
+	buffer := make([]byte, 1024)
+	restante := tamanio
+	pos := int64(inicio)
 
-	if bloquesNecesarios > 12 {
-		return fmt.Errorf("contenido demasiado grande")
-	}
+	for restante > 0 {
+		escribir := int32(1024)
+		if restante < escribir {
+			escribir = restante
+		}
 
-	offset := 0
-	for i := 0; i < bloquesNecesarios; i++ {
-		var bloqueArchivo structures.BloqueArchivo
-		fin := offset + 64
-		if fin > len(nuevoContenido) {
-			fin = len(nuevoContenido)
+		if _, err := file.WriteAt(buffer[:escribir], pos); err != nil {
+			return err
 		}
-		copy(bloqueArchivo.B_content[:], nuevoContenido[offset:fin])
 
-		if inodoUsers.I_block[i] == -1 {
-			nuevoBloque := BuscarBloqueLIbre(file, sb)
-			if nuevoBloque == -1 {
-				return fmt.Errorf("no hay bloques libres")
-			}
-			inodoUsers.I_block[i] = nuevoBloque
-			MarcarBloqueUsado(file, sb, nuevoBloque)
+		pos += int64(escribir)
+		restante -= escribir
+	}
+
+	return nil
+}
+
+// ValidarCredenciales busca el usuario en el contenido de users.txt y devuelve su UID y GID
+func ValidarCredenciales(contenido string, usuario string, password string) (int32, int32, bool) {
+	lineas := strings.Split(contenido, "\n")
+
+	for _, linea := range lineas {
+		linea = strings.TrimSpace(linea)
+		if linea == "" {
+			continue
 		}
 
-		if _, err := file.Seek(int64(inodoUsers.I_block[i]), 0); err != nil {
-			return err
+		// Formato: UID,U,grupo,usuario,password
+		// Formato: GID,G,nombre_grupo
+		partes := strings.Split(linea, ",")
+		if len(partes) < 3 {
+			continue
 		}
-		if err := binary.Write(file, binary.LittleEndian, &bloqueArchivo); err != nil {
-			return err
+
+		tipo := strings.TrimSpace(partes[1])
+		if tipo != "U" {
+			continue
 		}
-		offset += 64
-	}
 
-	for i := bloquesNecesarios; i < 12; i++ {
-		if inodoUsers.I_block[i] != -1 {
-			marcarBloqueLibre(file, sb, inodoUsers.I_block[i])
-			inodoUsers.I_block[i] = -1
+		if len(partes) < 5 {
+			continue
 		}
-	}
 
-	diferenciaBloques := int32(bloquesNecesarios) - bloquesAntiguos
-	sb.S_free_blocks_count -= diferenciaBloques
+		nombreUsuario := strings.TrimSpace(partes[3])
+		passUsuario := strings.TrimSpace(partes[4])
 
-	// Escribir el SuperBloque actualizado
-	if _, err := file.Seek(int64(sb.S_bm_inode_start-size.SizeSuperBloque()), 0); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, sb); err != nil {
-		return err
-	}
+		if nombreUsuario == usuario && passUsuario == password {
+			var uid int32
+			fmt.Sscanf(partes[0], "%d", &uid)
 
-	// Escribir el inodo de users.txt actualizado
-	posInodo := sb.S_inode_start + size.SizeTablaInodo()
-	if _, err := file.Seek(int64(posInodo), 0); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, &inodoUsers); err != nil {
-		return err
+			grupo := strings.TrimSpace(partes[2])
+			gid := BuscarGIDEnContenido(contenido, grupo)
+
+			return uid, gid, true
+		}
 	}
 
-	return nil
+	return 0, 0, false
 }
 
-// LimpiarParticion limpia una partición escribiendo ceros
-func LimpiarParticion(file *os.File, inicio int32, tamanio int32) error {
-	buffer := make([]byte, 1024)
-	restante := tamanio
+// BuscarGIDEnContenido busca el GID de un grupo
+func BuscarGIDEnContenido(contenido string, nombreGrupo string) int32 {
+	lineas := strings.Split(contenido, "\n")
 
-	if _, err := file.Seek(int64(inicio), 0); err != nil {
-		return err
-	}
-
-	for restante > 0 {
-		escribir := int32(1024)
-		if restante < escribir {
-			escribir = restante
+	for _, linea := range lineas {
+		linea = strings.TrimSpace(linea)
+		if linea == "" {
+			continue
 		}
 
-		if _, err := file.Write(buffer[:escribir]); err != nil {
-			return err
+		partes := strings.Split(linea, ",")
+		if len(partes) < 3 {
+			continue
 		}
 
-		restante -= escribir
+		tipo := strings.TrimSpace(partes[1])
+		grupo := strings.TrimSpace(partes[2])
+
+		if tipo == "G" && grupo == nombreGrupo {
+			var gid int32
+			fmt.Sscanf(partes[0], "%d", &gid)
+			return gid
+		}
 	}
 
-	return nil
+	return 0
 }
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"Proyecto/comandos/almacenamiento"
 	"Proyecto/comandos/controllers"
 	"Proyecto/comandos/general"
+	"Proyecto/comandos/utils"
 	"Proyecto/middlewares"
 	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/rs/cors"
 )
@@ -20,6 +23,37 @@ func main() {
 	mux.HandleFunc("/commands", controllers.HandleCommand)
 	mux.HandleFunc("/reportes", controllers.HandleReportsObtener)
 	mux.HandleFunc("/reportes/list", controllers.HandleListReports)
+	mux.HandleFunc("GET /reportes/file/{name...}", controllers.HandleReportFile)
+	mux.HandleFunc("GET /inspect/{id}", controllers.HandleInspect)
+
+	// API JSON de archivos sobre la partición de la sesión activa
+	mux.HandleFunc("GET /fs/{id}/tree", controllers.HandleFSArbol)
+	mux.HandleFunc("GET /fs/{id}/file", controllers.HandleFSLeerArchivo)
+	mux.HandleFunc("PUT /fs/{id}/file", controllers.HandleFSEscribirArchivo)
+	mux.HandleFunc("POST /fs/{id}/dir", controllers.HandleFSCrearCarpeta)
+	mux.HandleFunc("DELETE /fs/{id}/entry", controllers.HandleFSEliminar)
+
+	// Raíz de reportes configurable; rep escribe solo dentro de ella
+	if dir := os.Getenv("MIA_REPORTES_DIR"); dir != "" {
+		utils.DirectorioReportes = dir
+	}
+
+	// Carpeta base de los exec con ruta relativa
+	if dir := os.Getenv("MIA_SCRIPTS_DIR"); dir != "" {
+		utils.DirectorioScripts = dir
+	}
+
+	// Con MIA_ALMACENAMIENTO=memoria los discos viven solo en memoria y se pierden al salir
+	if os.Getenv("MIA_ALMACENAMIENTO") == "memoria" {
+		almacenamiento.Usar(almacenamiento.NuevaMemoria())
+		fmt.Println("Discos en memoria: no se escribirán archivos .mia")
+	}
+
+	// WebDAV opcional: con MIA_WEBDAV=1 se exponen las particiones montadas en /dav/<id>/
+	if os.Getenv("MIA_WEBDAV") == "1" {
+		mux.HandleFunc(controllers.PrefijoWebDAV, controllers.HandleWebDAV)
+		fmt.Println("WebDAV habilitado en " + controllers.PrefijoWebDAV + "<id>/")
+	}
 
 	// handler := c.Handler(mux)
 	handler := middlewares.RecoverMiddleware(c.Handler(mux))
@@ -160,7 +160,7 @@ function App() {
                       <span className="text-cyan-200 font-mono">{file}</span>
                       <div className="flex gap-2">
                         <button
-                          onClick={() => window.open(`http://localhost:9600/rep/${file}`, '_blank')}
+                          onClick={() => window.open(`http://localhost:9600/reportes/file/${encodeURIComponent(file)}`, '_blank')}
                           className="px-3 py-1 bg-cyan-700 hover:bg-cyan-600 text-xs rounded text-white"
                         >
                           Ver
@@ -168,7 +168,7 @@ function App() {
                         <button
                           onClick={() => {
                             const link = document.createElement('a');
-                            link.href = `http://localhost:9600/rep/${file}`;
+                            link.href = `http://localhost:9600/reportes/file/${encodeURIComponent(file)}`;
                             link.download = file;
                             document.body.appendChild(link);
                             link.click();